@@ -0,0 +1,242 @@
+// Author: Paulina Kimak
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+)
+
+// ImageStats holds the statistics of a single grayscale image.
+type ImageStats struct {
+	Name                  string  `json:"name"`
+	Entropy               float64 `json:"entropy"`
+	CorrelationHorizontal float64 `json:"correlation_horizontal"`
+	CorrelationVertical   float64 `json:"correlation_vertical"`
+	CorrelationDiagonal   float64 `json:"correlation_diagonal"`
+	Blocks                int     `json:"blocks"`
+	RepeatedBlocks        int     `json:"repeated_blocks"`
+	HistogramFile         string  `json:"histogram_file"`
+}
+
+// Comparison holds the differential measures between the plain image and one encrypted image.
+type Comparison struct {
+	Mode string  `json:"mode"`
+	NPCR float64 `json:"npcr_percent"`
+	UACI float64 `json:"uaci_percent"`
+}
+
+// Report is the full result of the analysis, written as text and JSON.
+type Report struct {
+	BlockSize   int          `json:"block_size"`
+	Images      []ImageStats `json:"images"`
+	Comparisons []Comparison `json:"comparisons"`
+}
+
+// Histogram counts how many pixels have each of the 256 gray levels.
+func Histogram(img *image.Gray) [256]int {
+	var hist [256]int
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			hist[img.GrayAt(x, y).Y]++
+		}
+	}
+	return hist
+}
+
+// Entropy returns the Shannon entropy of the gray levels in bits per pixel (max 8).
+func Entropy(img *image.Gray) float64 {
+	hist := Histogram(img)
+	total := float64(img.Bounds().Dx() * img.Bounds().Dy())
+	if total == 0 {
+		return 0
+	}
+
+	h := 0.0
+	for _, count := range hist {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / total
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// Correlation returns the Pearson correlation coefficient between every pixel
+// and its neighbour shifted by (dx, dy). Plain images are close to 1, good ciphertexts close to 0.
+func Correlation(img *image.Gray, dx, dy int) float64 {
+	b := img.Bounds()
+	var n, sumX, sumY, sumXX, sumYY, sumXY float64
+
+	for y := b.Min.Y; y+dy < b.Max.Y; y++ {
+		for x := b.Min.X; x+dx < b.Max.X; x++ {
+			a := float64(img.GrayAt(x, y).Y)
+			c := float64(img.GrayAt(x+dx, y+dy).Y)
+			n++
+			sumX += a
+			sumY += c
+			sumXX += a * a
+			sumYY += c * c
+			sumXY += a * c
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+
+	cov := sumXY/n - (sumX/n)*(sumY/n)
+	varX := sumXX/n - (sumX/n)*(sumX/n)
+	varY := sumYY/n - (sumY/n)*(sumY/n)
+	if varX == 0 || varY == 0 {
+		// A constant image has no defined correlation
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
+
+// NPCRUACI compares two images of the same size pixel by pixel.
+// NPCR is the percentage of changed pixels, UACI the mean absolute change relative to 255.
+func NPCRUACI(a, b *image.Gray) (float64, float64, error) {
+	if a.Bounds().Dx() != b.Bounds().Dx() || a.Bounds().Dy() != b.Bounds().Dy() {
+		return 0, 0, fmt.Errorf("images have different sizes")
+	}
+
+	ab, bb := a.Bounds(), b.Bounds()
+	changed, diffSum := 0, 0.0
+	for y := 0; y < ab.Dy(); y++ {
+		for x := 0; x < ab.Dx(); x++ {
+			p1 := int(a.GrayAt(ab.Min.X+x, ab.Min.Y+y).Y)
+			p2 := int(b.GrayAt(bb.Min.X+x, bb.Min.Y+y).Y)
+			if p1 != p2 {
+				changed++
+			}
+			diffSum += math.Abs(float64(p1-p2)) / 255
+		}
+	}
+
+	total := float64(ab.Dx() * ab.Dy())
+	if total == 0 {
+		return 0, 0, nil
+	}
+	return 100 * float64(changed) / total, 100 * diffSum / total, nil
+}
+
+// RepeatedBlocks splits the image into size×size blocks and counts the blocks
+// whose content already appeared earlier. Many repeats mean the mode leaks structure (ECB).
+func RepeatedBlocks(img *image.Gray, size int) (int, int) {
+	b := img.Bounds()
+	seen := make(map[string]bool)
+	total, repeated := 0, 0
+
+	for y := b.Min.Y; y < b.Max.Y; y += size {
+		for x := b.Min.X; x < b.Max.X; x += size {
+			var sb strings.Builder
+			for dy := 0; dy < size; dy++ {
+				for dx := 0; dx < size; dx++ {
+					sb.WriteByte(img.GrayAt(x+dx, y+dy).Y)
+				}
+			}
+			key := sb.String()
+			if seen[key] {
+				repeated++
+			}
+			seen[key] = true
+			total++
+		}
+	}
+	return total, repeated
+}
+
+// SaveHistogramPNG renders the gray level histogram as a bar chart (one column per level).
+func SaveHistogramPNG(path string, hist [256]int) error {
+	const height = 200
+
+	maxCount := 0
+	for _, c := range hist {
+		if c > maxCount {
+			maxCount = c
+		}
+	}
+
+	img := image.NewGray(image.Rect(0, 0, len(hist), height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < len(hist); x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	if maxCount > 0 {
+		for x, c := range hist {
+			barHeight := c * height / maxCount
+			for y := height - barHeight; y < height; y++ {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			}
+		}
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return png.Encode(out, img)
+}
+
+// Analyze computes the statistics of one image and renders its histogram to histPath.
+func Analyze(name string, img *image.Gray, blockSize int, histPath string) (ImageStats, error) {
+	total, repeated := RepeatedBlocks(img, blockSize)
+	stats := ImageStats{
+		Name:                  name,
+		Entropy:               Entropy(img),
+		CorrelationHorizontal: Correlation(img, 1, 0),
+		CorrelationVertical:   Correlation(img, 0, 1),
+		CorrelationDiagonal:   Correlation(img, 1, 1),
+		Blocks:                total,
+		RepeatedBlocks:        repeated,
+		HistogramFile:         histPath,
+	}
+	if err := SaveHistogramPNG(histPath, Histogram(img)); err != nil {
+		return stats, fmt.Errorf("failed to save histogram: %v", err)
+	}
+	return stats, nil
+}
+
+// Text formats the report as a human readable table.
+func (r Report) Text() string {
+	var sb strings.Builder
+	sb.WriteString("Block mode leakage analysis\n")
+	sb.WriteString(fmt.Sprintf("Block size: %dx%d pixels\n\n", r.BlockSize, r.BlockSize))
+
+	sb.WriteString(fmt.Sprintf("%-8s %8s %8s %8s %8s %16s  %s\n",
+		"image", "entropy", "corr-h", "corr-v", "corr-d", "repeated blocks", "histogram"))
+	for _, s := range r.Images {
+		sb.WriteString(fmt.Sprintf("%-8s %8.4f %8.4f %8.4f %8.4f %7d / %-7d  %s\n",
+			s.Name, s.Entropy, s.CorrelationHorizontal, s.CorrelationVertical, s.CorrelationDiagonal,
+			s.RepeatedBlocks, s.Blocks, s.HistogramFile))
+	}
+
+	sb.WriteString("\nplain vs encrypted\n")
+	sb.WriteString(fmt.Sprintf("%-8s %10s %10s\n", "mode", "NPCR %", "UACI %"))
+	for _, c := range r.Comparisons {
+		sb.WriteString(fmt.Sprintf("%-8s %10.4f %10.4f\n", c.Mode, c.NPCR, c.UACI))
+	}
+	return sb.String()
+}
+
+// WriteReport saves the report as text to textPath and as JSON to jsonPath.
+func (r Report) WriteReport(textPath, jsonPath string) error {
+	if err := os.WriteFile(textPath, []byte(r.Text()), 0644); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(jsonPath, append(data, '\n'), 0644)
+}
@@ -2,12 +2,53 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"image"
+	"block/analysis"
 	"block/helpers"
 	"block/funcblock"
+	"block/spn"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"strings"
 )
 
 func main() {
+	analyzeFlag := flag.Bool("a", false, "analyze plain.bmp against ecb_crypto.bmp and cbc_crypto.bmp")
+	cipherName := flag.String("cipher", "sha", "block cipher: sha, sdes or feistel")
+	rounds := flag.Int("rounds", 16, "number of Feistel rounds")
+	feistelSize := flag.Int("bsize", 8, "Feistel block size in bytes (even, divides 64)")
+	roundName := flag.String("round", "sha256", "Feistel round function: "+strings.Join(funcblock.RoundFuncNames(), ", "))
+	traceFlag := flag.Bool("trace", false, "encrypt a single block and print every round")
+	blockHex := flag.String("block", "", "block to trace as hex (default: first block of plain.bmp)")
+	spnFlag := flag.Bool("spn", false, "linear and differential cryptanalysis of the toy SPN")
+	linearPairs := flag.Int("lpairs", 30000, "known plaintexts for the linear SPN attack")
+	diffPairs := flag.Int("dpairs", 5000, "chosen plaintext pairs for the differential SPN attack")
+	seed := flag.Uint64("seed", 0, "seed for the SPN keys and plaintexts (0 = random)")
+	flag.Parse()
+
+	if *linearPairs < 1 || *diffPairs < 1 {
+		fmt.Println("Error: -lpairs and -dpairs must be at least 1")
+		return
+	}
+
+	if *analyzeFlag {
+		if err := analyzeImages(); err != nil {
+			fmt.Println("Error during analysis:", err)
+		}
+		return
+	}
+
+	if *spnFlag {
+		if err := attackSPN(*linearPairs, *diffPairs, *seed); err != nil {
+			fmt.Println("Error during SPN cryptanalysis:", err)
+		}
+		return
+	}
+
 	// Load the image
 	img, err := helpers.LoadImage("files/plain.bmp")
 	if err != nil {
@@ -21,9 +62,23 @@ func main() {
 	// Read the key from the file
 	key := helpers.ReadKey("files/key.txt") 
 
+	// Select the block cipher
+	cipher, err := newCipher(*cipherName, key, *rounds, *feistelSize, *roundName)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	if *traceFlag {
+		if err := traceBlock(cipher, grayImg, *blockHex); err != nil {
+			fmt.Println("Error:", err)
+		}
+		return
+	}
+
 	// Process the image using ECB and CBC modes
-	ecb := funcblock.ProcessECB(grayImg, key)
-	cbc := funcblock.ProcessCBC(grayImg, key)
+	ecb := funcblock.ProcessECB(grayImg, cipher)
+	cbc := funcblock.ProcessCBC(grayImg, cipher)
 
 	// Save the processed images
 	helpers.SaveImage("files/ecb_crypto.bmp", ecb)
@@ -32,3 +87,135 @@ func main() {
 	fmt.Println("ECB and CBC images saved in files directory.")
 
 }
+
+// newCipher builds the block cipher chosen with -cipher.
+func newCipher(name string, key []byte, rounds, size int, roundName string) (funcblock.BlockCipher, error) {
+	var cipher funcblock.BlockCipher
+	switch name {
+	case "sha":
+		cipher = funcblock.NewSHACipher(key)
+	case "sdes":
+		cipher = funcblock.NewSDES(funcblock.SDESKeyFromBytes(key))
+	case "feistel":
+		round, ok := funcblock.RoundFuncs[roundName]
+		if !ok {
+			return nil, fmt.Errorf("unknown round function %q", roundName)
+		}
+		f, err := funcblock.NewFeistel(key, rounds, size, round)
+		if err != nil {
+			return nil, err
+		}
+		cipher = f
+	default:
+		return nil, fmt.Errorf("unknown cipher %q", name)
+	}
+	return cipher, funcblock.CheckCipher(cipher)
+}
+
+// traceBlock encrypts one block with tracing enabled and decrypts it back when the cipher allows it.
+func traceBlock(cipher funcblock.BlockCipher, img *image.Gray, blockHex string) error {
+	bs := cipher.BlockSize()
+
+	var block []byte
+	if blockHex != "" {
+		var err error
+		block, err = hex.DecodeString(blockHex)
+		if err != nil {
+			return fmt.Errorf("invalid -block: %v", err)
+		}
+		if len(block) != bs {
+			return fmt.Errorf("-block must have %d bytes, got %d", bs, len(block))
+		}
+	} else {
+		// First cipher block of the top-left 8x8 image block
+		for i := 0; i < bs; i++ {
+			block = append(block, img.GrayAt(i%funcblock.BlockSize, i/funcblock.BlockSize).Y)
+		}
+	}
+
+	switch c := cipher.(type) {
+	case *funcblock.SDES:
+		c.Trace = os.Stdout
+	case *funcblock.Feistel:
+		c.Trace = os.Stdout
+	default:
+		return fmt.Errorf("trace mode is available for -cipher sdes and feistel")
+	}
+
+	out := make([]byte, bs)
+	fmt.Println("=== Encryption ===")
+	cipher.Encrypt(out, block)
+	fmt.Printf("plaintext  %x\nciphertext %x\n", block, out)
+
+	back := make([]byte, bs)
+	fmt.Println("\n=== Decryption ===")
+	cipher.(funcblock.Decrypter).Decrypt(back, out)
+	fmt.Printf("decrypted  %x\n", back)
+	return nil
+}
+
+// analyzeImages compares plain.bmp with both encrypted images and writes
+// the report (text + JSON) and PNG histograms into the files directory.
+func analyzeImages() error {
+	images := []struct{ name, path string }{
+		{"plain", "files/plain.bmp"},
+		{"ecb", "files/ecb_crypto.bmp"},
+		{"cbc", "files/cbc_crypto.bmp"},
+	}
+
+	report := analysis.Report{BlockSize: funcblock.BlockSize}
+	var plain *image.Gray
+
+	for _, entry := range images {
+		img, err := helpers.LoadImage(entry.path)
+		if err != nil {
+			return fmt.Errorf("loading %s: %v (run without -a first to create the encrypted images)", entry.path, err)
+		}
+		gray := helpers.ConvertToGrayscale(img)
+
+		stats, err := analysis.Analyze(entry.name, gray, funcblock.BlockSize, "files/hist_"+entry.name+".png")
+		if err != nil {
+			return err
+		}
+		report.Images = append(report.Images, stats)
+
+		if plain == nil {
+			plain = gray
+			continue
+		}
+		npcr, uaci, err := analysis.NPCRUACI(plain, gray)
+		if err != nil {
+			return fmt.Errorf("comparing plain and %s: %v", entry.name, err)
+		}
+		report.Comparisons = append(report.Comparisons, analysis.Comparison{Mode: entry.name, NPCR: npcr, UACI: uaci})
+	}
+
+	if err := report.WriteReport("files/analysis.txt", "files/analysis.json"); err != nil {
+		return fmt.Errorf("saving report: %v", err)
+	}
+	fmt.Print(report.Text())
+	fmt.Println("\nReport saved to files/analysis.txt and files/analysis.json.")
+	return nil
+}
+
+// attackSPN runs both SPN attacks and saves the report to files/spn_report.txt.
+func attackSPN(linearPairs, diffPairs int, seed uint64) error {
+	if seed == 0 {
+		var buf [8]byte
+		if _, err := rand.Read(buf[:]); err != nil {
+			return err
+		}
+		seed = binary.BigEndian.Uint64(buf[:])
+	}
+
+	report, err := spn.RunAttacks(linearPairs, diffPairs, seed)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile("files/spn_report.txt", []byte(report), 0644); err != nil {
+		return err
+	}
+	fmt.Print(report)
+	fmt.Println("\nReport saved to files/spn_report.txt.")
+	return nil
+}
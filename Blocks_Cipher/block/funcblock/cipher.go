@@ -0,0 +1,45 @@
+// Author: Paulina Kimak
+package funcblock
+
+import "fmt"
+
+// BlockCipher is the interface ProcessECB and ProcessCBC use to encrypt image data.
+// Every 8x8 pixel block (64 bytes) is cut into chunks of BlockSize() bytes,
+// so BlockSize() must divide 64.
+type BlockCipher interface {
+	// BlockSize returns the cipher block size in bytes
+	BlockSize() int
+	// Encrypt encrypts one block from src into dst
+	Encrypt(dst, src []byte)
+}
+
+// Decrypter is implemented by the ciphers that can be inverted (S-DES, Feistel).
+// The SHA-256 pseudo cipher is one-way and does not implement it.
+type Decrypter interface {
+	Decrypt(dst, src []byte)
+}
+
+// CheckCipher verifies that the cipher block size fits into an image block.
+func CheckCipher(c BlockCipher) error {
+	bs := c.BlockSize()
+	if bs <= 0 || (blockSize*blockSize)%bs != 0 {
+		return fmt.Errorf("cipher block size %d bytes does not divide the %d-byte image block", bs, blockSize*blockSize)
+	}
+	return nil
+}
+
+// SHACipher is the original pseudo cipher: the whole 64-byte image block is hashed together with the key.
+type SHACipher struct {
+	key []byte
+}
+
+// NewSHACipher returns the SHA-256 based pseudo cipher for the given key.
+func NewSHACipher(key []byte) *SHACipher {
+	return &SHACipher{key: key}
+}
+
+func (c *SHACipher) BlockSize() int { return blockSize * blockSize }
+
+func (c *SHACipher) Encrypt(dst, src []byte) {
+	copy(dst, shaEncrypt(src, c.key))
+}
@@ -0,0 +1,146 @@
+// Author: Paulina Kimak
+package funcblock
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// RoundFunc is the pluggable Feistel round function F(half, subkey).
+// It must return exactly len(half) bytes; it does not need to be invertible.
+type RoundFunc func(half, subkey []byte) []byte
+
+// RoundFuncs lists the round functions available from the command line.
+var RoundFuncs = map[string]RoundFunc{
+	"sha256": SHARound,
+	"toy":    ToyRound,
+}
+
+// RoundFuncNames returns the registered round function names in sorted order.
+func RoundFuncNames() []string {
+	names := make([]string, 0, len(RoundFuncs))
+	for name := range RoundFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SHARound hashes subkey||half with SHA-256 and truncates it to the half size.
+func SHARound(half, subkey []byte) []byte {
+	return repeatHash(append(append([]byte{}, subkey...), half...), len(half))
+}
+
+// toySBox is the 4-bit S-box used by ToyRound (first row of DES S1).
+var toySBox = [16]byte{14, 4, 13, 1, 2, 15, 11, 8, 3, 10, 6, 12, 5, 9, 0, 7}
+
+// ToyRound is a hand-traceable round function: XOR with the subkey,
+// substitute every nibble through a 4-bit S-box, then rotate the half left by 3 bits.
+func ToyRound(half, subkey []byte) []byte {
+	out := make([]byte, len(half))
+	for i := range half {
+		v := half[i] ^ subkey[i]
+		out[i] = toySBox[v>>4]<<4 | toySBox[v&0x0F]
+	}
+	return rotateLeft(out, 3)
+}
+
+// rotateLeft rotates a byte slice (as one big-endian bit string) left by k bits.
+func rotateLeft(in []byte, k int) []byte {
+	n := len(in) * 8
+	out := make([]byte, len(in))
+	for i := 0; i < n; i++ {
+		src := (i + k) % n
+		bit := in[src/8] >> (7 - src%8) & 1
+		out[i/8] |= bit << (7 - i%8)
+	}
+	return out
+}
+
+// Feistel is a configurable balanced Feistel network. Set Trace to print every round.
+type Feistel struct {
+	blockSize int
+	subkeys   [][]byte
+	round     RoundFunc
+	Trace     io.Writer
+}
+
+// NewFeistel builds a Feistel network with the given number of rounds and block size (bytes, even).
+// Round keys are derived from the key as SHA-256(key || round number) truncated to the half size.
+func NewFeistel(key []byte, rounds, blockSize int, round RoundFunc) (*Feistel, error) {
+	if rounds < 1 {
+		return nil, fmt.Errorf("feistel needs at least one round, got %d", rounds)
+	}
+	if blockSize < 2 || blockSize%2 != 0 {
+		return nil, fmt.Errorf("feistel block size must be a positive even number of bytes, got %d", blockSize)
+	}
+	if round == nil {
+		return nil, fmt.Errorf("feistel round function is missing")
+	}
+
+	half := blockSize / 2
+	subkeys := make([][]byte, rounds)
+	for i := range subkeys {
+		var idx [4]byte
+		binary.BigEndian.PutUint32(idx[:], uint32(i+1))
+		subkeys[i] = repeatHash(append(append([]byte{}, key...), idx[:]...), half)
+	}
+	return &Feistel{blockSize: blockSize, subkeys: subkeys, round: round}, nil
+}
+
+func (f *Feistel) BlockSize() int { return f.blockSize }
+
+// Rounds returns the number of rounds.
+func (f *Feistel) Rounds() int { return len(f.subkeys) }
+
+// Encrypt: L(i+1) = R(i), R(i+1) = L(i) XOR F(R(i), K(i)); the halves are swapped at the end.
+func (f *Feistel) Encrypt(dst, src []byte) {
+	f.crypt(dst, src, false)
+}
+
+// Decrypt runs the same network with the round keys in reverse order.
+func (f *Feistel) Decrypt(dst, src []byte) {
+	f.crypt(dst, src, true)
+}
+
+func (f *Feistel) crypt(dst, src []byte, decrypt bool) {
+	half := f.blockSize / 2
+	left := append([]byte{}, src[:half]...)
+	right := append([]byte{}, src[half:f.blockSize]...)
+	f.tracef("Feistel input      L=%x R=%x\n", left, right)
+
+	for i := range f.subkeys {
+		k := i
+		if decrypt {
+			k = len(f.subkeys) - 1 - i
+		}
+		out := f.round(right, f.subkeys[k])
+		newRight := xorBlocks(left, out)
+		left, right = right, newRight
+		f.tracef("  round %2d  K=%x  F=%x  L=%x R=%x\n", i+1, f.subkeys[k], out, left, right)
+	}
+
+	// Undo the last swap so that decryption is the same network
+	copy(dst[:half], right)
+	copy(dst[half:f.blockSize], left)
+	f.tracef("  output           %x\n", dst[:f.blockSize])
+}
+
+func (f *Feistel) tracef(format string, args ...interface{}) {
+	if f.Trace != nil {
+		fmt.Fprintf(f.Trace, format, args...)
+	}
+}
+
+// repeatHash stretches a SHA-256 digest to n bytes.
+func repeatHash(data []byte, n int) []byte {
+	hash := sha256.Sum256(data)
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = hash[i%len(hash)]
+	}
+	return out
+}
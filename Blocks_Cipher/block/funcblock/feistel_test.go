@@ -0,0 +1,53 @@
+// Author: Paulina Kimak
+package funcblock
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestFeistelVectors pins the output of the network (key "secret", 16 rounds, 8-byte block) so
+// that changes in the key schedule or round functions are noticed.
+func TestFeistelVectors(t *testing.T) {
+	vectors := []struct {
+		round, plain, cipher string
+	}{
+		{"sha256", "ABCDEFGH", "4d5024143c782a68"},
+		{"toy", "ABCDEFGH", "d05aae40d285c02a"},
+	}
+	for _, v := range vectors {
+		f, err := NewFeistel([]byte("secret"), 16, 8, RoundFuncs[v.round])
+		if err != nil {
+			t.Fatalf("%s: %v", v.round, err)
+		}
+		out, back := make([]byte, 8), make([]byte, 8)
+		f.Encrypt(out, []byte(v.plain))
+		f.Decrypt(back, out)
+		if got := hex.EncodeToString(out); got != v.cipher {
+			t.Errorf("%s P=%q: C=%s, expected %s", v.round, v.plain, got, v.cipher)
+		}
+		if !bytes.Equal(back, []byte(v.plain)) {
+			t.Errorf("%s: decrypted %q, expected %q", v.round, back, v.plain)
+		}
+	}
+}
+
+// TestFeistelRoundTrip decrypts blocks for other shapes of the network and every round function.
+func TestFeistelRoundTrip(t *testing.T) {
+	for _, shape := range []struct{ rounds, size int }{{1, 2}, {3, 4}, {8, 16}, {32, 64}} {
+		for _, name := range RoundFuncNames() {
+			f, err := NewFeistel([]byte("key"), shape.rounds, shape.size, RoundFuncs[name])
+			if err != nil {
+				t.Fatalf("%s, %d rounds, %d-byte block: %v", name, shape.rounds, shape.size, err)
+			}
+			plain := bytes.Repeat([]byte{0x5A, 0xC3}, shape.size/2)
+			out, back := make([]byte, shape.size), make([]byte, shape.size)
+			f.Encrypt(out, plain)
+			f.Decrypt(back, out)
+			if !bytes.Equal(back, plain) {
+				t.Errorf("%s, %d rounds, %d-byte block: decrypted %x, expected %x", name, shape.rounds, shape.size, back, plain)
+			}
+		}
+	}
+}
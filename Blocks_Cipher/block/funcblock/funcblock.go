@@ -10,6 +10,9 @@ import (
 
 const blockSize = 8 // 8x8 pixel blocks
 
+// BlockSize is the side of the square pixel block used by ProcessECB and ProcessCBC.
+const BlockSize = blockSize
+
 // getBlock extracts an 8x8 block of pixels from the grayscale image
 func getBlock(img *image.Gray, x, y int) []byte {
 	// Preallocate 1D byte slice to hold 64 grayscale values (for 8x8 block)
@@ -60,15 +63,21 @@ func shaEncrypt(block, key []byte) []byte {
 	return out
 }
 
-func ProcessECB(img *image.Gray, key []byte) *image.Gray {
+// ProcessECB encrypts every 8x8 block independently. The block is cut into
+// cipher-sized chunks and each chunk is encrypted on its own.
+func ProcessECB(img *image.Gray, c BlockCipher) *image.Gray {
 	out := image.NewGray(img.Bounds())
+	bs := c.BlockSize()
 
 	for y := 0; y < img.Bounds().Dy(); y += blockSize {
 		for x := 0; x < img.Bounds().Dx(); x += blockSize {
 			// Extract an 8x8 block of pixels from the image
 			block := getBlock(img, x, y)
-			// Encrypt the block using the shaEncrypt function
-			encrypted := shaEncrypt(block, key)
+			encrypted := make([]byte, len(block))
+			// Encrypt the block chunk by chunk with the selected cipher
+			for i := 0; i < len(block); i += bs {
+				c.Encrypt(encrypted[i:i+bs], block[i:i+bs])
+			}
 			// Write the encrypted block back to the output image
 			writeBlock(out, x, y, encrypted)
 		}
@@ -89,18 +98,24 @@ func xorBlocks(a, b []byte) []byte {
 }
 
 
-func ProcessCBC(img *image.Gray, key []byte) *image.Gray {
+// ProcessCBC chains the cipher-sized chunks of all blocks: every chunk is
+// XOR'ed with the previous ciphertext chunk before encryption.
+func ProcessCBC(img *image.Gray, c BlockCipher) *image.Gray {
 	out := image.NewGray(img.Bounds())
-	iv := make([]byte, blockSize*blockSize) // init vector = 0s
+	bs := c.BlockSize()
+	iv := make([]byte, bs) // init vector = 0s
 	prev := iv
 
 	for y := 0; y < img.Bounds().Dy(); y += blockSize {
 		for x := 0; x < img.Bounds().Dx(); x += blockSize {
 			block := getBlock(img, x, y)
-			xored := xorBlocks(block, prev)
-			encrypted := shaEncrypt(xored, key)
+			encrypted := make([]byte, len(block))
+			for i := 0; i < len(block); i += bs {
+				xored := xorBlocks(block[i:i+bs], prev)
+				c.Encrypt(encrypted[i:i+bs], xored)
+				prev = encrypted[i : i+bs]
+			}
 			writeBlock(out, x, y, encrypted)
-			prev = encrypted
 		}
 	}
 	return out
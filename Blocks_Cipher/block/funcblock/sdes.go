@@ -0,0 +1,144 @@
+// Author: Paulina Kimak
+package funcblock
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Simplified DES (Stallings): 8-bit block, 10-bit key, two Feistel rounds.
+// Bit positions in the tables count from 1 = most significant bit.
+var (
+	sdesP10   = []int{3, 5, 2, 7, 4, 10, 1, 9, 8, 6}
+	sdesP8    = []int{6, 3, 7, 4, 8, 5, 10, 9}
+	sdesIP    = []int{2, 6, 3, 1, 4, 8, 5, 7}
+	sdesIPInv = []int{4, 1, 3, 5, 7, 2, 8, 6}
+	sdesEP    = []int{4, 1, 2, 3, 2, 3, 4, 1}
+	sdesP4    = []int{2, 4, 3, 1}
+
+	sdesS0 = [4][4]uint{
+		{1, 0, 3, 2},
+		{3, 2, 1, 0},
+		{0, 2, 1, 3},
+		{3, 1, 3, 2},
+	}
+	sdesS1 = [4][4]uint{
+		{0, 1, 2, 3},
+		{2, 0, 1, 3},
+		{3, 0, 1, 0},
+		{2, 1, 0, 3},
+	}
+)
+
+// SDES is the simplified DES teaching cipher. Set Trace to print every round.
+type SDES struct {
+	K1, K2 uint
+	Trace  io.Writer
+}
+
+// NewSDES derives the two 8-bit round keys from a 10-bit key.
+func NewSDES(key uint) *SDES {
+	key &= 0x3FF
+	p10 := permute(key, 10, sdesP10)
+
+	// Split into two 5-bit halves, rotate left by 1 → K1, by 2 more → K2
+	left, right := p10>>5, p10&0x1F
+	left, right = rotl5(left, 1), rotl5(right, 1)
+	k1 := permute(left<<5|right, 10, sdesP8)
+	left, right = rotl5(left, 2), rotl5(right, 2)
+	k2 := permute(left<<5|right, 10, sdesP8)
+
+	return &SDES{K1: k1, K2: k2}
+}
+
+// SDESKeyFromBytes reads a 10-bit S-DES key. A key file with ten '0'/'1'
+// characters is taken literally, otherwise the low 10 bits of the first two bytes are used.
+func SDESKeyFromBytes(key []byte) uint {
+	text := strings.TrimSpace(string(key))
+	if len(text) == 10 {
+		if v, err := strconv.ParseUint(text, 2, 16); err == nil {
+			return uint(v)
+		}
+	}
+	var v uint
+	for i := 0; i < 2 && i < len(key); i++ {
+		v = v<<8 | uint(key[i])
+	}
+	return v & 0x3FF
+}
+
+func (s *SDES) BlockSize() int { return 1 }
+
+func (s *SDES) Encrypt(dst, src []byte) {
+	for i := range src {
+		dst[i] = byte(s.crypt(uint(src[i]), s.K1, s.K2))
+	}
+}
+
+func (s *SDES) Decrypt(dst, src []byte) {
+	for i := range src {
+		dst[i] = byte(s.crypt(uint(src[i]), s.K2, s.K1))
+	}
+}
+
+// crypt runs IP, fK(first), SW, fK(second), IP⁻¹. Decryption swaps the key order.
+func (s *SDES) crypt(block, first, second uint) uint {
+	s.tracef("S-DES input        %s   K1=%s K2=%s\n", bits(block, 8), bits(s.K1, 8), bits(s.K2, 8))
+
+	state := permute(block, 8, sdesIP)
+	s.tracef("  after IP         %s\n", bits(state, 8))
+
+	state = s.fk(state, first)
+	s.tracef("  round 1 (fK)     %s   key=%s\n", bits(state, 8), bits(first, 8))
+
+	state = (state&0x0F)<<4 | state>>4
+	s.tracef("  after SW         %s\n", bits(state, 8))
+
+	state = s.fk(state, second)
+	s.tracef("  round 2 (fK)     %s   key=%s\n", bits(state, 8), bits(second, 8))
+
+	out := permute(state, 8, sdesIPInv)
+	s.tracef("  after IP^-1      %s\n", bits(out, 8))
+	return out
+}
+
+// fk XORs the left nibble with F(right, subkey) and keeps the right nibble.
+func (s *SDES) fk(state, subkey uint) uint {
+	left, right := state>>4, state&0x0F
+
+	ep := permute(right, 4, sdesEP) ^ subkey
+	row0, col0 := (ep>>6&2)|(ep>>4&1), ep>>5&3
+	row1, col1 := (ep>>2&2)|(ep&1), ep>>1&3
+	sbox := sdesS0[row0][col0]<<2 | sdesS1[row1][col1]
+	f := permute(sbox, 4, sdesP4)
+
+	s.tracef("    E/P^K=%s  S-box=%s  P4=%s\n", bits(ep, 8), bits(sbox, 4), bits(f, 4))
+	return (left^f)<<4 | right
+}
+
+func (s *SDES) tracef(format string, args ...interface{}) {
+	if s.Trace != nil {
+		fmt.Fprintf(s.Trace, format, args...)
+	}
+}
+
+// permute builds a new value from the bits of in (width n bits) selected by table.
+func permute(in uint, n int, table []int) uint {
+	var out uint
+	for _, pos := range table {
+		out = out<<1 | (in>>(n-pos))&1
+	}
+	return out
+}
+
+// rotl5 rotates a 5-bit value left by k positions.
+func rotl5(v uint, k int) uint {
+	return (v<<k | v>>(5-k)) & 0x1F
+}
+
+// bits formats v as a binary string of width n.
+func bits(v uint, n int) string {
+	return fmt.Sprintf("%0*b", n, v)
+}
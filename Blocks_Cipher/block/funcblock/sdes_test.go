@@ -0,0 +1,55 @@
+// Author: Paulina Kimak
+package funcblock
+
+import "testing"
+
+// TestSDESKeySchedule checks the round keys of Stallings' example: K = 1010000010 → K1 = 10100100,
+// K2 = 01000011.
+func TestSDESKeySchedule(t *testing.T) {
+	s := NewSDES(0b1010000010)
+	if s.K1 != 0b10100100 || s.K2 != 0b01000011 {
+		t.Errorf("K1=%s K2=%s, expected K1=10100100 K2=01000011", bits(s.K1, 8), bits(s.K2, 8))
+	}
+}
+
+// TestSDESVectors checks published S-DES test vectors (Stallings' example and the usual S-DES table).
+func TestSDESVectors(t *testing.T) {
+	vectors := []struct {
+		key, plain, cipher uint
+	}{
+		{0b1010000010, 0b10010111, 0b00111000},
+		{0b0000000000, 0b00000000, 0b11110000},
+		{0b1111111111, 0b11111111, 0b00001111},
+		{0b0000000000, 0b10101010, 0b00010001},
+		{0b1110001110, 0b10101010, 0b11001010},
+		{0b1110001110, 0b01010101, 0b01110000},
+		{0b1111111111, 0b10101010, 0b00000100},
+	}
+	for _, v := range vectors {
+		c := NewSDES(v.key)
+		out, back := make([]byte, 1), make([]byte, 1)
+		c.Encrypt(out, []byte{byte(v.plain)})
+		c.Decrypt(back, out)
+		if uint(out[0]) != v.cipher {
+			t.Errorf("key=%s P=%s: C=%s, expected %s", bits(v.key, 10), bits(v.plain, 8), bits(uint(out[0]), 8), bits(v.cipher, 8))
+		}
+		if uint(back[0]) != v.plain {
+			t.Errorf("key=%s C=%s: decrypted %s, expected %s", bits(v.key, 10), bits(v.cipher, 8), bits(uint(back[0]), 8), bits(v.plain, 8))
+		}
+	}
+}
+
+// TestSDESRoundTrip decrypts every block under every key.
+func TestSDESRoundTrip(t *testing.T) {
+	for key := uint(0); key < 1024; key++ {
+		c := NewSDES(key)
+		for p := 0; p < 256; p++ {
+			out, back := make([]byte, 1), make([]byte, 1)
+			c.Encrypt(out, []byte{byte(p)})
+			c.Decrypt(back, out)
+			if back[0] != byte(p) {
+				t.Fatalf("key=%s P=%s: decrypt(encrypt(P)) = %s", bits(key, 10), bits(uint(p), 8), bits(uint(back[0]), 8))
+			}
+		}
+	}
+}
@@ -2,4 +2,4 @@ module block
 
 go 1.23.5
 
-require golang.org/x/image v0.26.0 // indirect
+require golang.org/x/image v0.26.0
@@ -0,0 +1,310 @@
+// Author: Paulina Kimak
+package spn
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+// TrailStep is one active S-box of a characteristic: S-box number 1..4 in the given round
+// with its input and output mask (linear) or difference (differential).
+type TrailStep struct {
+	Round, SBox int
+	In, Out     uint16
+}
+
+// Trail is a linear approximation or differential characteristic through rounds 1..3.
+// Input is the plaintext mask/difference, Output the mask/difference at the input of round 4 (U4).
+type Trail struct {
+	Name   string
+	Input  uint16
+	Output uint16
+	Steps  []TrailStep
+}
+
+// LinearTrail is Heys' approximation P5⊕P7⊕P8⊕U4,6⊕U4,8⊕U4,14⊕U4,16 = 0.
+var LinearTrail = Trail{
+	Name:   "linear",
+	Input:  0x0B00,
+	Output: 0x0505,
+	Steps: []TrailStep{
+		{Round: 1, SBox: 2, In: 0xB, Out: 0x4},
+		{Round: 2, SBox: 2, In: 0x4, Out: 0x5},
+		{Round: 3, SBox: 2, In: 0x4, Out: 0x5},
+		{Round: 3, SBox: 4, In: 0x4, Out: 0x5},
+	},
+}
+
+// DifferentialTrail is Heys' characteristic ΔP = 0B00 → ΔU4 = 0606.
+var DifferentialTrail = Trail{
+	Name:   "differential",
+	Input:  0x0B00,
+	Output: 0x0606,
+	Steps: []TrailStep{
+		{Round: 1, SBox: 2, In: 0xB, Out: 0x2},
+		{Round: 2, SBox: 3, In: 0x4, Out: 0x6},
+		{Round: 3, SBox: 2, In: 0x2, Out: 0x5},
+		{Round: 3, SBox: 3, In: 0x2, Out: 0x5},
+	},
+}
+
+// Check follows the trail through the permutation and verifies that every round
+// starts with the mask/difference produced by the previous one.
+func (t Trail) Check() error {
+	state := t.Input
+	for r := 1; r < Rounds; r++ {
+		var in, out uint16
+		for _, s := range t.Steps {
+			if s.Round != r {
+				continue
+			}
+			shift := uint(16 - 4*s.SBox)
+			in |= s.In << shift
+			out |= s.Out << shift
+		}
+		if in != state {
+			return fmt.Errorf("%s trail: round %d expects %04X but receives %04X", t.Name, r, in, state)
+		}
+		state = Permute(out)
+	}
+	if state != t.Output {
+		return fmt.Errorf("%s trail: ends in %04X, declared %04X", t.Name, state, t.Output)
+	}
+	return nil
+}
+
+// LinearBias combines the S-box biases with the piling-up lemma: 2^(n−1)·∏ LAT/16.
+func (t Trail) LinearBias(lat [16][16]int) float64 {
+	bias := math.Pow(2, float64(len(t.Steps)-1))
+	for _, s := range t.Steps {
+		bias *= float64(lat[s.In][s.Out]) / 16
+	}
+	return bias
+}
+
+// DifferentialProbability multiplies the S-box probabilities DDT/16 of all steps.
+func (t Trail) DifferentialProbability(ddt [16][16]int) float64 {
+	p := 1.0
+	for _, s := range t.Steps {
+		p *= float64(ddt[s.In][s.Out]) / 16
+	}
+	return p
+}
+
+// TargetNibbles returns the last-round S-boxes (1..4) touched by the trail output;
+// the attack recovers the K5 nibbles in front of them.
+func (t Trail) TargetNibbles() []int {
+	var nibbles []int
+	for i := 1; i <= 4; i++ {
+		if Nibble(t.Output, i) != 0 {
+			nibbles = append(nibbles, i)
+		}
+	}
+	return nibbles
+}
+
+// Candidate is one guess of the target subkey bits together with its score.
+type Candidate struct {
+	Key   uint16  // guessed K5 bits, placed in their nibbles
+	Count int     // linear: pairs satisfying the approximation, differential: right pairs
+	Score float64 // linear: |bias|, differential: probability
+}
+
+// AttackResult summarises one attack.
+type AttackResult struct {
+	Trail      Trail
+	Pairs      int
+	Used       int // differential: pairs that survived the ciphertext filter
+	Expected   float64
+	Candidates []Candidate // sorted best first
+	Correct    uint16      // the real target bits of K5
+}
+
+// Recovered returns the best scoring guess.
+func (r AttackResult) Recovered() uint16 {
+	return r.Candidates[0].Key
+}
+
+// Success reports whether the best guess equals the real subkey bits.
+func (r AttackResult) Success() bool {
+	return r.Recovered() == r.Correct
+}
+
+// candidates enumerates every value of the target nibbles.
+func candidates(nibbles []int) []uint16 {
+	keys := make([]uint16, 0, 1<<(4*len(nibbles)))
+	for v := 0; v < 1<<(4*len(nibbles)); v++ {
+		var key uint16
+		for i, n := range nibbles {
+			key |= uint16(v>>(4*(len(nibbles)-1-i))&0xF) << uint(16-4*n)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// partialDecrypt undoes K5 and the last S-box layer on the target nibbles only.
+func partialDecrypt(c, key uint16, nibbles []int) uint16 {
+	var u uint16
+	for _, n := range nibbles {
+		shift := uint(16 - 4*n)
+		u |= InvSBox[Nibble(c^key, n)] << shift
+	}
+	return u
+}
+
+func targetMask(nibbles []int) uint16 {
+	var mask uint16
+	for _, n := range nibbles {
+		mask |= 0xF << uint(16-4*n)
+	}
+	return mask
+}
+
+// LinearAttack encrypts `pairs` random known plaintexts and ranks every guess of the target
+// K5 bits by how far the approximation deviates from 1/2 after partial decryption.
+func LinearAttack(c *Cipher, trail Trail, pairs int, rng *rand.Rand) AttackResult {
+	nibbles := trail.TargetNibbles()
+	keys := candidates(nibbles)
+	counts := make([]int, len(keys))
+
+	for i := 0; i < pairs; i++ {
+		p := uint16(rng.UintN(1 << 16))
+		ct := c.Encrypt(p)
+		inBit := parity(p & trail.Input)
+		for k, key := range keys {
+			u := partialDecrypt(ct, key, nibbles)
+			if inBit^parity(u&trail.Output) == 0 {
+				counts[k]++
+			}
+		}
+	}
+
+	result := AttackResult{
+		Trail:    trail,
+		Pairs:    pairs,
+		Used:     pairs,
+		Expected: math.Abs(trail.LinearBias(LinearApproximationTable())),
+		Correct:  c.Keys[Rounds] & targetMask(nibbles),
+	}
+	for k, key := range keys {
+		bias := math.Abs(float64(counts[k])-float64(pairs)/2) / float64(pairs)
+		result.Candidates = append(result.Candidates, Candidate{Key: key, Count: counts[k], Score: bias})
+	}
+	sortCandidates(result.Candidates)
+	return result
+}
+
+// DifferentialAttack encrypts `pairs` chosen plaintext pairs with difference trail.Input,
+// drops pairs whose ciphertext difference touches non-target nibbles, and counts for every
+// guess of the target K5 bits how often the partial decryption shows difference trail.Output.
+func DifferentialAttack(c *Cipher, trail Trail, pairs int, rng *rand.Rand) AttackResult {
+	nibbles := trail.TargetNibbles()
+	mask := targetMask(nibbles)
+	keys := candidates(nibbles)
+	counts := make([]int, len(keys))
+	used := 0
+
+	for i := 0; i < pairs; i++ {
+		p1 := uint16(rng.UintN(1 << 16))
+		p2 := p1 ^ trail.Input
+		c1, c2 := c.Encrypt(p1), c.Encrypt(p2)
+		// A right pair has zero difference in the inactive last-round S-boxes
+		if (c1^c2)&^mask != 0 {
+			continue
+		}
+		used++
+		for k, key := range keys {
+			if partialDecrypt(c1, key, nibbles)^partialDecrypt(c2, key, nibbles) == trail.Output {
+				counts[k]++
+			}
+		}
+	}
+
+	result := AttackResult{
+		Trail:    trail,
+		Pairs:    pairs,
+		Used:     used,
+		Expected: trail.DifferentialProbability(DifferenceDistributionTable()),
+		Correct:  c.Keys[Rounds] & mask,
+	}
+	for k, key := range keys {
+		result.Candidates = append(result.Candidates, Candidate{Key: key, Count: counts[k], Score: float64(counts[k]) / float64(pairs)})
+	}
+	sortCandidates(result.Candidates)
+	return result
+}
+
+func sortCandidates(cands []Candidate) {
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].Score > cands[j].Score })
+}
+
+// Text formats the attack result with the trail, the expected value and the best candidates.
+func (r AttackResult) Text(top int) string {
+	var sb strings.Builder
+	lat, ddt := LinearApproximationTable(), DifferenceDistributionTable()
+
+	sb.WriteString(fmt.Sprintf("=== %s attack ===\n", strings.ToUpper(r.Trail.Name[:1])+r.Trail.Name[1:]))
+	sb.WriteString(fmt.Sprintf("Plaintext %s: %04X   U4 %s: %04X\n", r.kind(), r.Trail.Input, r.kind(), r.Trail.Output))
+	for _, s := range r.Trail.Steps {
+		if r.Trail.Name == "linear" {
+			sb.WriteString(fmt.Sprintf("  round %d S%d%d: %X → %X  LAT=%+d  bias=%+.4f\n",
+				s.Round, s.Round, s.SBox, s.In, s.Out, lat[s.In][s.Out], float64(lat[s.In][s.Out])/16))
+		} else {
+			sb.WriteString(fmt.Sprintf("  round %d S%d%d: %X → %X  DDT=%d  prob=%.4f\n",
+				s.Round, s.Round, s.SBox, s.In, s.Out, ddt[s.In][s.Out], float64(ddt[s.In][s.Out])/16))
+		}
+	}
+	if r.Trail.Name == "linear" {
+		sb.WriteString(fmt.Sprintf("Expected |bias| (piling-up lemma): %.5f (1/%.0f)\n", r.Expected, 1/r.Expected))
+		sb.WriteString(fmt.Sprintf("Known plaintexts: %d\n", r.Pairs))
+	} else {
+		sb.WriteString(fmt.Sprintf("Expected probability: %.5f (%.0f/1024)\n", r.Expected, r.Expected*1024))
+		sb.WriteString(fmt.Sprintf("Chosen plaintext pairs: %d, kept after ciphertext filter: %d\n", r.Pairs, r.Used))
+	}
+
+	sb.WriteString(fmt.Sprintf("Target K5 nibbles: %v\n", r.Trail.TargetNibbles()))
+	sb.WriteString(fmt.Sprintf("%-10s %8s %10s\n", "K5 guess", "count", r.scoreName()))
+	for i := 0; i < top && i < len(r.Candidates); i++ {
+		cand := r.Candidates[i]
+		mark := ""
+		if cand.Key == r.Correct {
+			mark = "  <- real key"
+		}
+		sb.WriteString(fmt.Sprintf("%-10s %8d %10.5f%s\n", formatPartial(cand.Key, r.Trail.TargetNibbles()), cand.Count, cand.Score, mark))
+	}
+
+	status := "FAILED"
+	if r.Success() {
+		status = "OK"
+	}
+	sb.WriteString(fmt.Sprintf("Recovered %s, real %s: %s\n",
+		formatPartial(r.Recovered(), r.Trail.TargetNibbles()), formatPartial(r.Correct, r.Trail.TargetNibbles()), status))
+	return sb.String()
+}
+
+func (r AttackResult) kind() string {
+	if r.Trail.Name == "linear" {
+		return "mask"
+	}
+	return "difference"
+}
+
+func (r AttackResult) scoreName() string {
+	if r.Trail.Name == "linear" {
+		return "|bias|"
+	}
+	return "prob"
+}
+
+// formatPartial prints the target nibbles in hex and the unknown ones as '_'.
+func formatPartial(key uint16, nibbles []int) string {
+	out := []byte("____")
+	for _, n := range nibbles {
+		out[n-1] = fmt.Sprintf("%X", Nibble(key, n))[0]
+	}
+	return string(out)
+}
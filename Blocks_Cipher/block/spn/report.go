@@ -0,0 +1,52 @@
+// Author: Paulina Kimak
+package spn
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// RunAttacks builds an SPN with random round keys (from seed), prints the S-box tables,
+// and runs the linear attack with linearPairs known plaintexts and the differential
+// attack with diffPairs chosen plaintext pairs. It returns the full text report.
+func RunAttacks(linearPairs, diffPairs int, seed uint64) (string, error) {
+	for _, t := range []Trail{LinearTrail, DifferentialTrail} {
+		if err := t.Check(); err != nil {
+			return "", err
+		}
+	}
+
+	rng := rand.New(rand.NewPCG(seed, seed^0x9E3779B97F4A7C15))
+	var c Cipher
+	for i := range c.Keys {
+		c.Keys[i] = uint16(rng.UintN(1 << 16))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Toy SPN cryptanalysis (16-bit block, 4 rounds, 4-bit S-boxes)\n")
+	sb.WriteString(fmt.Sprintf("Seed: %d\n", seed))
+	sb.WriteString("S-box: ")
+	for _, v := range SBox {
+		sb.WriteString(fmt.Sprintf("%X ", v))
+	}
+	sb.WriteString("\nRound keys:")
+	for i, k := range c.Keys {
+		sb.WriteString(fmt.Sprintf(" K%d=%04X", i+1, k))
+	}
+	sb.WriteString("\n\n")
+
+	sb.WriteString(FormatTable("Linear approximation table (count − 8, bias = entry/16)", LinearApproximationTable()))
+	sb.WriteString("\n")
+	sb.WriteString(FormatTable("Difference distribution table (probability = entry/16)", DifferenceDistributionTable()))
+	sb.WriteString("\n")
+
+	linear := LinearAttack(&c, LinearTrail, linearPairs, rng)
+	sb.WriteString(linear.Text(8))
+	sb.WriteString("\n")
+
+	diff := DifferentialAttack(&c, DifferentialTrail, diffPairs, rng)
+	sb.WriteString(diff.Text(8))
+
+	return sb.String(), nil
+}
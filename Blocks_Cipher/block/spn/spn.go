@@ -0,0 +1,75 @@
+// Author: Paulina Kimak
+package spn
+
+// Toy substitution-permutation network from Heys' "A Tutorial on Linear and
+// Differential Cryptanalysis": 16-bit block, four 4-bit S-boxes per round,
+// four rounds and five 16-bit round keys (the last one is XOR'ed after round 4).
+
+const (
+	BlockBits = 16
+	Rounds    = 4
+)
+
+// SBox is the 4-bit substitution used in every position of every round.
+var SBox = [16]uint16{0xE, 0x4, 0xD, 0x1, 0x2, 0xF, 0xB, 0x8, 0x3, 0xA, 0x6, 0xC, 0x5, 0x9, 0x0, 0x7}
+
+// InvSBox is the inverse of SBox, used for the partial decryption of the last round.
+var InvSBox = func() [16]uint16 {
+	var inv [16]uint16
+	for x, y := range SBox {
+		inv[y] = uint16(x)
+	}
+	return inv
+}()
+
+// Cipher holds the five round keys.
+type Cipher struct {
+	Keys [Rounds + 1]uint16
+}
+
+// substitute applies the S-box (or its inverse) to all four nibbles.
+func substitute(v uint16, box *[16]uint16) uint16 {
+	var out uint16
+	for i := 0; i < 4; i++ {
+		shift := uint(12 - 4*i)
+		out |= box[(v>>shift)&0xF] << shift
+	}
+	return out
+}
+
+// Permute moves bit i to bit j (bits numbered 1..16 from the left):
+// bit 4·a+b+1 goes to 4·b+a+1, i.e. the 4×4 bit matrix is transposed.
+func Permute(v uint16) uint16 {
+	var out uint16
+	for i := 0; i < BlockBits; i++ {
+		bit := (v >> (15 - i)) & 1
+		j := (i%4)*4 + i/4
+		out |= bit << (15 - j)
+	}
+	return out
+}
+
+// Encrypt runs rounds 1..3 (key mixing, substitution, permutation),
+// round 4 without the permutation, and the final key mixing with K5.
+func (c *Cipher) Encrypt(p uint16) uint16 {
+	v := p
+	for r := 0; r < Rounds-1; r++ {
+		v = Permute(substitute(v^c.Keys[r], &SBox))
+	}
+	v = substitute(v^c.Keys[Rounds-1], &SBox)
+	return v ^ c.Keys[Rounds]
+}
+
+// Nibble returns the i-th 4-bit group of v (1 = leftmost).
+func Nibble(v uint16, i int) uint16 {
+	return (v >> uint(16-4*i)) & 0xF
+}
+
+// parity returns 1 when v has an odd number of set bits.
+func parity(v uint16) uint16 {
+	v ^= v >> 8
+	v ^= v >> 4
+	v ^= v >> 2
+	v ^= v >> 1
+	return v & 1
+}
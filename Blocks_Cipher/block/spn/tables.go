@@ -0,0 +1,57 @@
+// Author: Paulina Kimak
+package spn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LinearApproximationTable returns LAT[a][b] = #{x : a·x = b·S(x)} − 8,
+// so the bias of the approximation (a, b) is LAT[a][b] / 16.
+func LinearApproximationTable() [16][16]int {
+	var lat [16][16]int
+	for a := uint16(0); a < 16; a++ {
+		for b := uint16(0); b < 16; b++ {
+			count := 0
+			for x := uint16(0); x < 16; x++ {
+				if parity(a&x) == parity(b&SBox[x]) {
+					count++
+				}
+			}
+			lat[a][b] = count - 8
+		}
+	}
+	return lat
+}
+
+// DifferenceDistributionTable returns DDT[dx][dy] = #{x : S(x) ⊕ S(x ⊕ dx) = dy},
+// so the probability of the differential (dx → dy) is DDT[dx][dy] / 16.
+func DifferenceDistributionTable() [16][16]int {
+	var ddt [16][16]int
+	for dx := uint16(0); dx < 16; dx++ {
+		for x := uint16(0); x < 16; x++ {
+			dy := SBox[x] ^ SBox[x^dx]
+			ddt[dx][dy]++
+		}
+	}
+	return ddt
+}
+
+// FormatTable prints a 16×16 table with hex row (input) and column (output) headers.
+func FormatTable(title string, table [16][16]int) string {
+	var sb strings.Builder
+	sb.WriteString(title + "\n")
+	sb.WriteString("in\\out")
+	for b := 0; b < 16; b++ {
+		sb.WriteString(fmt.Sprintf("%4X", b))
+	}
+	sb.WriteString("\n")
+	for a := 0; a < 16; a++ {
+		sb.WriteString(fmt.Sprintf("%6X", a))
+		for b := 0; b < 16; b++ {
+			sb.WriteString(fmt.Sprintf("%4d", table[a][b]))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -4,6 +4,8 @@ package main
 import (
 	"flag"
 	"log"
+	"math/big"
+	"os"
 
 	"elgamal/flagfunc"
 	"elgamal/helpers"
@@ -18,20 +20,169 @@ func main() {
 	decryptFlag := flag.Bool("d", false, "decrypt the ciphertext")
 	signatureFlag := flag.Bool("s", false, "sign the message")
 	verifyFlag := flag.Bool("v", false, "verification of signature")
+	genParamsFlag := flag.Bool("gen-params", false, "generate safe prime p and generator g into elgamal.txt")
+	hybridEncryptFlag := flag.Bool("he", false, "hybrid encryption (ElGamal + AES-GCM) of a file of any size")
+	hybridDecryptFlag := flag.Bool("hd", false, "hybrid decryption of crypto_hybrid.txt")
+	dsaKeysFlag := flag.Bool("dsa-k", false, "prepare DSA/Schnorr keys in the prime-order subgroup")
+	dsaSignFlag := flag.Bool("dsa-s", false, "sign the message with DSA")
+	dsaVerifyFlag := flag.Bool("dsa-v", false, "verify the DSA signature")
+	schnorrSignFlag := flag.Bool("schnorr-s", false, "sign the message with Schnorr")
+	schnorrVerifyFlag := flag.Bool("schnorr-v", false, "verify the Schnorr signature")
+	nonceAttackFlag := flag.Bool("nonce-attack", false, "recover the private key from two ElGamal signatures made with the same k")
+	ecKeysFlag := flag.Bool("ec-k", false, "prepare elliptic-curve keys (-curve P-256, X25519 or Ed25519)")
+	ecEncryptFlag := flag.Bool("ec-e", false, "EC-ElGamal (ECDH + AES-GCM) encryption of plain.txt")
+	ecDecryptFlag := flag.Bool("ec-d", false, "EC-ElGamal decryption of ec_crypto.txt")
+	ecSignFlag := flag.Bool("ec-s", false, "sign the message with ECDSA (P-256) or Ed25519")
+	ecVerifyFlag := flag.Bool("ec-v", false, "verify the ECDSA/Ed25519 signature")
+	ecToyFlag := flag.Bool("ec-toy", false, "teaching mode: point arithmetic and Koblitz EC-ElGamal on a textbook curve")
+	convertFlag := flag.Bool("convert", false, "import private.txt/publicKey.txt (also legacy numbers) and rewrite them in -keyfmt")
+	fingerprintFlag := flag.Bool("fingerprint", false, "show format and fingerprint of the key files")
+	mulFlag := flag.Bool("mul", false, "multiply two ciphertexts (-ct, -ct2) into an encryption of m1·m2")
+	rerandFlag := flag.Bool("rerand", false, "re-randomize the ciphertext -ct")
+	expEncryptFlag := flag.Bool("exp-e", false, "exponential ElGamal: encrypt g^m for a small number m")
+	expDecryptFlag := flag.Bool("exp-d", false, "exponential ElGamal: decrypt and find m <= -maxm")
+	expAddFlag := flag.Bool("exp-add", false, "exponential ElGamal: add two ciphertexts (-ct, -ct2)")
+	voteFlag := flag.Bool("vote", false, "demo: tally encrypted yes/no votes from votes.txt")
+	splitFlag := flag.Bool("split", false, "split the private key into -n Shamir shares with threshold -t")
+	verifySharesFlag := flag.Bool("verify-shares", false, "check the shares against the Feldman commitments")
+	combineFlag := flag.Bool("combine", false, "reconstruct the private key from t shares")
+	partialFlag := flag.Bool("partial", false, "partial decryption of -ct by shareholder -share")
+	tDecryptFlag := flag.Bool("tdecrypt", false, "threshold decryption of -ct from t partial decryptions")
+	dlogFlag := flag.Bool("dlog", false, "recover b from the public key by solving g^b = beta (weak parameters) and decrypt -ct")
+
+	// Options
+	bitsFlag := flag.Int("bits", 512, "size of p in bits for -gen-params")
+	qbitsFlag := flag.Int("qbits", 0, "size of q in bits for -gen-params (0 = safe prime p = 2q+1)")
+	allowWeakFlag := flag.Bool("allow-weak", false, "accept weak parameters in -k (attack demonstrations only)")
+	hashFlag := flag.String("hash", "sha256", "hash for -s: sha256, sha384 or sha512")
+	msgFlag := flag.String("msg", flagfunc.DocumentFile, "document to sign (-s) or verify (-v)")
+	sigFlag := flag.String("sig", flagfunc.SignaturePath, "signature written by -s and read by -v")
+	curveFlag := flag.String("curve", "", "curve for -ec-k (P-256, X25519, Ed25519) or -ec-toy (toy17, toy751, P-256)")
+	keyFmtFlag := flag.String("keyfmt", flagfunc.KeyFormat, "format of new key files: pem, json or legacy")
+	passFlag := flag.String("pass", "", "passphrase of the private key (default $"+flagfunc.PassphraseEnv+")")
+	plainFlag := flag.String("plain", flagfunc.PlainPath, "plaintext for -e and -exp-e")
+	ctFlag := flag.String("ct", flagfunc.CipherPath, "ciphertext written by -e/-exp-e, read by -d/-exp-d/-rerand/-mul/-exp-add")
+	ct2Flag := flag.String("ct2", flagfunc.Cipher2Path, "second ciphertext for -mul and -exp-add")
+	outFlag := flag.String("out", "", "result file of -mul, -exp-add and -rerand")
+	maxMFlag := flag.Int64("maxm", flagfunc.MaxExpMessage, "largest m searched by -exp-d")
+	tFlag := flag.Int("t", flagfunc.Threshold, "threshold t for -split")
+	nFlag := flag.Int("n", flagfunc.ShareCount, "number of shares n for -split")
+	shareFlag := flag.Int("share", flagfunc.ShareIndex, "shareholder index for -partial")
+	sharesFlag := flag.String("shares", "", "share indices for -combine, -verify-shares and -tdecrypt, e.g. 1,3,5 (default all)")
+	algoFlag := flag.String("algo", flagfunc.DlogAlgorithm, "solver for -dlog: auto, bsgs, rho, ph or all")
+	fixKFlag := flag.String("fixk", "", "fixed nonce k for -s (nonce-reuse demonstration only)")
+	msg2Flag := flag.String("msg2", flagfunc.Document2File, "second document for -nonce-attack")
+	sig2Flag := flag.String("sig2", flagfunc.Signature2Path, "second signature for -nonce-attack")
 
 	flag.Parse()
 
 	// Check flags
-	operationFlags := []*bool{keysFlag, encryptFlag, decryptFlag, signatureFlag, verifyFlag}
+	operationFlags := []*bool{keysFlag, encryptFlag, decryptFlag, signatureFlag, verifyFlag, genParamsFlag,
+		hybridEncryptFlag, hybridDecryptFlag, dsaKeysFlag, dsaSignFlag, dsaVerifyFlag,
+		schnorrSignFlag, schnorrVerifyFlag, nonceAttackFlag, ecKeysFlag, ecEncryptFlag, ecDecryptFlag,
+		ecSignFlag, ecVerifyFlag, ecToyFlag, convertFlag, fingerprintFlag, mulFlag, rerandFlag,
+		expEncryptFlag, expDecryptFlag, expAddFlag, voteFlag, splitFlag, verifySharesFlag, combineFlag, partialFlag, tDecryptFlag,
+		dlogFlag}
 	operationCount := helpers.CountSelectedFlags(operationFlags)
 
 	if operationCount != 1 {
-		log.Fatalf("Error: You must choose exactly one operation: -k, -e, -d, -s or v.")
+		log.Fatalf("Error: You must choose exactly one operation: -k, -e, -d, -s, -v, -gen-params, -he, -hd, -dsa-k, -dsa-s, -dsa-v, -schnorr-s, -schnorr-v, -nonce-attack, -ec-k, -ec-e, -ec-d, -ec-s, -ec-v, -ec-toy, -convert, -fingerprint, -mul, -rerand, -exp-e, -exp-d, -exp-add, -vote, -split, -verify-shares, -combine, -partial, -tdecrypt or -dlog.")
+	}
+
+	flagfunc.ParamBits = *bitsFlag
+	flagfunc.ParamQBits = *qbitsFlag
+	flagfunc.AllowWeak = *allowWeakFlag
+	flagfunc.SignHash = *hashFlag
+	flagfunc.DocumentFile = *msgFlag
+	flagfunc.SignaturePath = *sigFlag
+	flagfunc.Document2File = *msg2Flag
+	flagfunc.Signature2Path = *sig2Flag
+	flagfunc.Curve = *curveFlag
+	flagfunc.KeyFormat = *keyFmtFlag
+	flagfunc.PlainPath = *plainFlag
+	flagfunc.CipherPath = *ctFlag
+	flagfunc.Cipher2Path = *ct2Flag
+	flagfunc.OutPath = *outFlag
+	flagfunc.MaxExpMessage = *maxMFlag
+	flagfunc.Threshold = *tFlag
+	flagfunc.ShareCount = *nFlag
+	flagfunc.ShareIndex = *shareFlag
+	flagfunc.ShareList = *sharesFlag
+	flagfunc.DlogAlgorithm = *algoFlag
+	flagfunc.ComparePlain = *ctFlag == flagfunc.CryptoFile && *plainFlag == flagfunc.PlainFile
+	flagfunc.Passphrase = *passFlag
+	if flagfunc.Passphrase == "" {
+		flagfunc.Passphrase = os.Getenv(flagfunc.PassphraseEnv)
+	}
+	if *fixKFlag != "" {
+		k, ok := new(big.Int).SetString(*fixKFlag, 10)
+		if !ok {
+			log.Fatalf("Error: -fixk must be a decimal number, got %q", *fixKFlag)
+		}
+		flagfunc.FixedK = k
 	}
 
 	// Determine the operation
 	var operation string
 	switch {
+	case *genParamsFlag:
+		operation = "gp"
+	case *hybridEncryptFlag:
+		operation = "he"
+	case *hybridDecryptFlag:
+		operation = "hd"
+	case *dsaKeysFlag:
+		operation = "dk"
+	case *dsaSignFlag:
+		operation = "ds"
+	case *dsaVerifyFlag:
+		operation = "dv"
+	case *schnorrSignFlag:
+		operation = "ss"
+	case *schnorrVerifyFlag:
+		operation = "sv"
+	case *nonceAttackFlag:
+		operation = "na"
+	case *ecKeysFlag:
+		operation = "ek"
+	case *ecEncryptFlag:
+		operation = "ee"
+	case *ecDecryptFlag:
+		operation = "ed"
+	case *ecSignFlag:
+		operation = "es"
+	case *ecVerifyFlag:
+		operation = "ev"
+	case *ecToyFlag:
+		operation = "et"
+	case *convertFlag:
+		operation = "cv"
+	case *fingerprintFlag:
+		operation = "fp"
+	case *mulFlag:
+		operation = "mul"
+	case *rerandFlag:
+		operation = "rr"
+	case *expEncryptFlag:
+		operation = "xe"
+	case *expDecryptFlag:
+		operation = "xd"
+	case *expAddFlag:
+		operation = "xa"
+	case *voteFlag:
+		operation = "vote"
+	case *splitFlag:
+		operation = "split"
+	case *verifySharesFlag:
+		operation = "vs"
+	case *combineFlag:
+		operation = "combine"
+	case *partialFlag:
+		operation = "partial"
+	case *tDecryptFlag:
+		operation = "td"
+	case *dlogFlag:
+		operation = "dlog"
 	case *keysFlag:
 		operation = "k"
 	case *encryptFlag:
@@ -0,0 +1,185 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"elgamal/helpers"
+)
+
+const (
+	DlogReportFile   = "files/dlog_report.txt"
+	RecoveredKeyFile = "files/private_recovered.txt"
+)
+
+// DlogAlgorithm selects the solver of -dlog: auto, bsgs, rho, ph or all (set from the -algo flag)
+var DlogAlgorithm = "auto"
+
+// dlogSolver is one discrete-logarithm algorithm for g^x = h in the group of order n.
+type dlogSolver struct {
+	name  string
+	solve func(g, h, p, n *big.Int, factors []helpers.PrimePower) (*big.Int, error)
+}
+
+var dlogSolvers = map[string]dlogSolver{
+	"bsgs": {"baby-step giant-step", func(g, h, p, n *big.Int, _ []helpers.PrimePower) (*big.Int, error) {
+		return helpers.BabyStepGiantStep(g, h, p, n)
+	}},
+	"rho": {"Pollard rho", func(g, h, p, n *big.Int, _ []helpers.PrimePower) (*big.Int, error) {
+		return helpers.PollardRhoLog(g, h, p, n)
+	}},
+	"ph": {"Pohlig-Hellman", helpers.PohligHellman},
+}
+
+// chooseDlogAlgorithms returns the solvers to run. auto picks Pohlig-Hellman when every prime
+// factor of the order is small enough, since it is never slower than BSGS or rho on the whole group.
+func chooseDlogAlgorithms(n *big.Int, factors []helpers.PrimePower) ([]string, error) {
+	switch DlogAlgorithm {
+	case "bsgs", "rho", "ph":
+		return []string{DlogAlgorithm}, nil
+	case "all":
+		return []string{"ph", "bsgs", "rho"}, nil
+	case "auto":
+		if len(factors) == 0 {
+			return nil, fmt.Errorf("the order of g has no prime factors: there is no discrete logarithm to solve")
+		}
+		largest := factors[len(factors)-1].Q
+		for i := len(factors) - 1; i >= 0; i-- {
+			if new(big.Int).Mod(n, factors[i].Q).Sign() == 0 {
+				largest = factors[i].Q
+				break
+			}
+		}
+		if largest.BitLen() > helpers.MaxRhoBits {
+			return nil, fmt.Errorf("the largest prime factor of the order of g has %d bits: the discrete logarithm is infeasible (the parameters are not weak)", largest.BitLen())
+		}
+		return []string{"ph"}, nil
+	default:
+		return nil, fmt.Errorf("unknown -algo %q (use auto, bsgs, rho, ph or all)", DlogAlgorithm)
+	}
+}
+
+// DiscreteLogAttack recovers b from the public key (p, g, β) by solving g^b = β,
+// saves the recovered private key and decrypts CipherPath with it.
+func DiscreteLogAttack(PublicKeyFile, CipherPath string) error {
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	report := func(format string, args ...interface{}) {
+		line := fmt.Sprintf(format, args...)
+		fmt.Println(line)
+		sb.WriteString(line + "\n")
+	}
+	report("Discrete logarithm attack on %s (%d-bit p)", PublicKeyFile, pub.P.BitLen())
+	used, err := recoverDlogKey(pub, report)
+	if err := saveDlogReport(sb.String(), err); err != nil {
+		return err
+	}
+	log.Printf("[WARN] Private key recovered from the public key with %s", used)
+
+	// c1 = g^k, so b modulo the order of g decrypts as well as the original b
+	if _, err := os.Stat(CipherPath); err != nil {
+		return nil
+	}
+	fmt.Printf("Decrypting %s with the recovered key:\n", CipherPath)
+	ComparePlain = false
+	return DecryptElgamal(CipherPath, RecoveredKeyFile)
+}
+
+// saveDlogReport writes the report to DlogReportFile on every path of the attack and returns
+// err joined with the write error, if any.
+func saveDlogReport(text string, err error) error {
+	if werr := os.WriteFile(DlogReportFile, []byte(text), 0644); werr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to save report: %v", werr))
+	}
+	return err
+}
+
+// checkDlogKey rejects the keys for which g^b = β has no meaningful solution: with g = 1 or
+// g = p-1 any b (even 0) would be reported as recovered.
+func checkDlogKey(p, g *big.Int) error {
+	if p.Cmp(big.NewInt(5)) < 0 {
+		return fmt.Errorf("p = %s is too small to be an ElGamal modulus", p)
+	}
+	if g.Cmp(big.NewInt(2)) < 0 || g.Cmp(new(big.Int).Sub(p, big.NewInt(2))) > 0 {
+		return fmt.Errorf("g = %s must satisfy 2 <= g <= p-2", g)
+	}
+	return nil
+}
+
+// recoverDlogKey solves g^b = β, saves the recovered key to RecoveredKeyFile and returns the
+// name of the algorithm that found b. Every step is written with report.
+func recoverDlogKey(pub *helpers.ElGamalKey, report func(format string, args ...interface{})) (string, error) {
+	p, g, beta := pub.P, pub.G, pub.Beta
+	if err := checkDlogKey(p, g); err != nil {
+		report("%v", err)
+		return "", err
+	}
+
+	// The order of g needs the factorization of p-1
+	start := time.Now()
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	factors, err := helpers.Factor(pm1)
+	if err != nil {
+		report("p-1 could not be factored: %v", err)
+		report("without the order of g the solvers cannot run; a large unfactored cofactor also means Pohlig-Hellman gains nothing")
+		return "", fmt.Errorf("factoring p-1: %v", err)
+	}
+	n := helpers.GroupOrder(g, p, factors)
+	parts := make([]string, len(factors))
+	for i, f := range factors {
+		parts[i] = f.Q.String()
+		if f.E > 1 {
+			parts[i] += fmt.Sprintf("^%d", f.E)
+		}
+	}
+	report("p-1 = %s  (factored in %v)", strings.Join(parts, " · "), time.Since(start).Round(time.Microsecond))
+	report("order of g: n = %s (%d bits)", n, n.BitLen())
+
+	algorithms, err := chooseDlogAlgorithms(n, factors)
+	if err != nil {
+		report("%v", err)
+		return "", err
+	}
+
+	var b *big.Int
+	used := ""
+	for _, name := range algorithms {
+		solver := dlogSolvers[name]
+		start := time.Now()
+		x, err := solver.solve(g, beta, p, n, factors)
+		elapsed := time.Since(start).Round(time.Microsecond)
+		if err != nil {
+			report("%-22s failed after %v: %v", solver.name, elapsed, err)
+			continue
+		}
+		report("%-22s b = %s  (%v)", solver.name, x, elapsed)
+		if b == nil {
+			b, used = x, solver.name
+		}
+	}
+	if b == nil {
+		return "", fmt.Errorf("no algorithm recovered b")
+	}
+	if new(big.Int).Exp(g, b, p).Cmp(beta) != 0 {
+		report("g^b = beta does not hold for b = %s", b)
+		return "", fmt.Errorf("recovered b does not satisfy g^b = beta")
+	}
+	report("g^b = beta confirmed, b recovered with %s", used)
+
+	key := &helpers.ElGamalKey{P: p, G: g, Beta: beta, B: b}
+	if err := helpers.WriteKeyFile(RecoveredKeyFile, key, KeyFormat, ""); err != nil {
+		return "", fmt.Errorf("failed to save recovered key: %v", err)
+	}
+	report("recovered private key saved to %s", RecoveredKeyFile)
+	return used, nil
+}
@@ -0,0 +1,56 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"math/big"
+	"testing"
+
+	"elgamal/helpers"
+)
+
+// TestDlogSolvers solves 2^b = beta modulo 467 with every algorithm of -dlog; b = 127.
+func TestDlogSolvers(t *testing.T) {
+	p, g, b := big.NewInt(467), big.NewInt(2), big.NewInt(127)
+	beta := new(big.Int).Exp(g, b, p)
+	factors, err := helpers.Factor(big.NewInt(466))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := helpers.GroupOrder(g, p, factors)
+	for _, name := range []string{"bsgs", "rho", "ph"} {
+		solver := dlogSolvers[name]
+		x, err := solver.solve(g, beta, p, n, factors)
+		if err != nil {
+			t.Errorf("%s: %v", solver.name, err)
+			continue
+		}
+		if x.Cmp(b) != 0 {
+			t.Errorf("%s: log_2(%s) mod 467 = %s, expected 127", solver.name, beta, x)
+		}
+	}
+}
+
+// TestDlogKeyChecks rejects keys that would crash the solvers or give a meaningless b.
+func TestDlogKeyChecks(t *testing.T) {
+	tests := []struct {
+		p, g int64
+		ok   bool
+	}{
+		{2, 1, false},
+		{3, 2, false},
+		{467, 1, false},
+		{467, 466, false},
+		{467, 0, false},
+		{467, 2, true},
+		{467, 465, true},
+	}
+	for _, tt := range tests {
+		err := checkDlogKey(big.NewInt(tt.p), big.NewInt(tt.g))
+		if (err == nil) != tt.ok {
+			t.Errorf("p = %d, g = %d: error %v, expected ok = %t", tt.p, tt.g, err, tt.ok)
+		}
+	}
+	if _, err := chooseDlogAlgorithms(big.NewInt(1), nil); err == nil {
+		t.Errorf("auto accepted an order without prime factors")
+	}
+}
@@ -0,0 +1,277 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"crypto"
+	"crypto/dsa" //nolint:staticcheck // used only to cross-check our DSA against the reference implementation
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+
+	"elgamal/helpers"
+)
+
+const (
+	DSAPrivateKeyFile    = "files/dsa_private.txt"
+	DSAPublicKeyFile     = "files/dsa_public.txt"
+	DSASignatureFile     = "files/dsa_signature.txt"
+	SchnorrSignatureFile = "files/schnorr_signature.txt"
+)
+
+// DSA and Schnorr part------------------------------------------------------------------------------------
+// Both schemes work in the subgroup of prime order q generated by g (from elgamal.txt) and share
+// the key pair: private x with 1 <= x < q, public y = gˣ mod p. Nonces come from RFC 6979.
+
+// GenerateDSAKeys creates x and y in the group from ElgamalFile.
+func GenerateDSAKeys(ElgamalFile string) error {
+	p, g, q, err := ReadGroup(ElgamalFile)
+	if err != nil {
+		return fmt.Errorf("failed to read parameters: %v", err)
+	}
+	q, err = SubgroupOrder(p, g, q)
+	if err != nil {
+		return err
+	}
+
+	// x random, 1 <= x < q
+	x, err := helpers.RandomBigInt(new(big.Int).Sub(q, big.NewInt(1)))
+	if err != nil {
+		return fmt.Errorf("error generating random x: %v", err)
+	}
+	x.Add(x, big.NewInt(1))
+	y := new(big.Int).Exp(g, x, p)
+
+	if err := helpers.WriteBigIntsToFile(DSAPrivateKeyFile, []*big.Int{p, q, g, x}); err != nil {
+		return err
+	}
+	if err := helpers.WriteBigIntsToFile(DSAPublicKeyFile, []*big.Int{p, q, g, y}); err != nil {
+		return err
+	}
+	log.Printf("[INFO] DSA/Schnorr keys saved (q has %d bits)", q.BitLen())
+	return nil
+}
+
+// dsaSign computes r = (gᵏ mod p) mod q and s = k⁻¹·(z + x·r) mod q,
+// asking nextK for a new nonce while r or s is zero.
+func dsaSign(p, q, g, x, z *big.Int, nextK func() *big.Int) (*big.Int, *big.Int) {
+	for {
+		k := nextK()
+		r := new(big.Int).Exp(g, k, p)
+		r.Mod(r, q)
+		if r.Sign() == 0 {
+			continue
+		}
+		kInv := new(big.Int).ModInverse(k, q)
+		s := new(big.Int).Mul(x, r)
+		s.Add(s, z)
+		s.Mul(s, kInv)
+		s.Mod(s, q)
+		if s.Sign() == 0 {
+			continue
+		}
+		return r, s
+	}
+}
+
+// dsaVerify checks 0 < r, s < q and (g^(z·w) · y^(r·w) mod p) mod q = r with w = s⁻¹ mod q.
+func dsaVerify(p, q, g, y, z, r, s *big.Int) bool {
+	if r.Sign() <= 0 || r.Cmp(q) >= 0 || s.Sign() <= 0 || s.Cmp(q) >= 0 {
+		return false
+	}
+	w := new(big.Int).ModInverse(s, q)
+	u1 := new(big.Int).Mul(z, w)
+	u1.Mod(u1, q)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, q)
+	v := new(big.Int).Exp(g, u1, p)
+	v.Mul(v, new(big.Int).Exp(y, u2, p))
+	v.Mod(v, p)
+	v.Mod(v, q)
+	return v.Cmp(r) == 0
+}
+
+// schnorrChallenge computes e = bits2int(H(R || m)) mod q, with R padded to the length of p.
+func schnorrChallenge(p, q, R *big.Int, msg []byte, h crypto.Hash) *big.Int {
+	hasher := h.New()
+	hasher.Write(helpers.FixedBytes(R.Bytes(), (p.BitLen()+7)/8))
+	hasher.Write(msg)
+	e := helpers.Bits2Int(hasher.Sum(nil), q)
+	return e.Mod(e, q)
+}
+
+// schnorrSign computes R = gᵏ mod p, e = H(R || m) and s = (k + x·e) mod q. The signature is (e, s).
+func schnorrSign(p, q, g, x *big.Int, msg []byte, h crypto.Hash, k *big.Int) (*big.Int, *big.Int) {
+	R := new(big.Int).Exp(g, k, p)
+	e := schnorrChallenge(p, q, R, msg, h)
+	s := new(big.Int).Mul(x, e)
+	s.Add(s, k)
+	s.Mod(s, q)
+	return e, s
+}
+
+// schnorrVerify recomputes R' = gˢ · y⁻ᵉ mod p and accepts when H(R' || m) = e.
+func schnorrVerify(p, q, g, y *big.Int, msg []byte, h crypto.Hash, e, s *big.Int) bool {
+	if e.Sign() < 0 || e.Cmp(q) >= 0 || s.Sign() < 0 || s.Cmp(q) >= 0 {
+		return false
+	}
+	negE := new(big.Int).Sub(q, e) // y^(-e) = y^(q-e) because y has order q
+	R := new(big.Int).Exp(g, s, p)
+	R.Mul(R, new(big.Int).Exp(y, negE, p))
+	R.Mod(R, p)
+	return schnorrChallenge(p, q, R, msg, h).Cmp(e) == 0
+}
+
+// readDSAKey reads p, q, g and x or y from a DSA key file.
+func readDSAKey(path string) (p, q, g, key *big.Int, err error) {
+	values, err := helpers.ReadBigIntsFromFile(path, 4)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to read key %s: %v (run -dsa-k first)", path, err)
+	}
+	return values[0], values[1], values[2], values[3], nil
+}
+
+// SignDSA signs the document with DSA and a deterministic RFC 6979 nonce.
+func SignDSA(DocumentFile, DSAPrivateKeyFile string) error {
+	p, q, g, x, err := readDSAKey(DSAPrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	h, err := helpers.ParseHash(SignHash)
+	if err != nil {
+		return err
+	}
+	digest, err := helpers.HashFile(DocumentFile, h)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %v", DocumentFile, err)
+	}
+
+	nonces := helpers.NewNonceGenerator(q, x, digest, h)
+	r, s := dsaSign(p, q, g, x, helpers.Bits2Int(digest, q), nonces.Next)
+
+	meta := map[string]string{"algorithm": "DSA", "hash": h.String(), "nonce": "RFC6979"}
+	if err := helpers.WriteRecord(DSASignatureFile, []string{"algorithm", "hash", "nonce"}, meta, []*big.Int{r, s}); err != nil {
+		return fmt.Errorf("failed to save signature: %v", err)
+	}
+	log.Printf("[INFO] DSA signature (r, s) of %s saved to %s", DocumentFile, DSASignatureFile)
+	return nil
+}
+
+// VerifyDSA verifies the DSA signature and, when q has a whole number of bytes,
+// cross-checks the result with crypto/dsa.
+func VerifyDSA(DocumentFile, DSAPublicKeyFile, DSASignatureFile string) error {
+	p, q, g, y, err := readDSAKey(DSAPublicKeyFile)
+	if err != nil {
+		return err
+	}
+	sig, err := readSignatureOf(DSASignatureFile, "DSA")
+	if err != nil {
+		return err
+	}
+	h, err := sig.Hash()
+	if err != nil {
+		return err
+	}
+	digest, err := helpers.HashFile(DocumentFile, h)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %v", DocumentFile, err)
+	}
+	r, s := sig.Values[0], sig.Values[1]
+
+	valid := dsaVerify(p, q, g, y, helpers.Bits2Int(digest, q), r, s)
+
+	if q.BitLen()%8 == 0 {
+		pub := dsa.PublicKey{Parameters: dsa.Parameters{P: p, Q: q, G: g}, Y: y}
+		ref := dsa.Verify(&pub, digest[:min(len(digest), q.BitLen()/8)], r, s)
+		log.Printf("[INFO] crypto/dsa verification result: %v", ref)
+		if ref != valid {
+			return fmt.Errorf("crypto/dsa disagrees with our verification (ours %v, crypto/dsa %v)", valid, ref)
+		}
+	} else {
+		log.Printf("[INFO] crypto/dsa cross-check skipped: q has %d bits, not a multiple of 8", q.BitLen())
+	}
+
+	return writeVerifyResult(valid, "DSA equation does not hold")
+}
+
+// SignSchnorr signs the document with a Schnorr signature and an RFC 6979 nonce.
+func SignSchnorr(DocumentFile, DSAPrivateKeyFile string) error {
+	p, q, g, x, err := readDSAKey(DSAPrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	h, err := helpers.ParseHash(SignHash)
+	if err != nil {
+		return err
+	}
+	msg, err := os.ReadFile(DocumentFile)
+	if err != nil {
+		return err
+	}
+	digest := h.New()
+	digest.Write(msg)
+
+	k := helpers.NewNonceGenerator(q, x, digest.Sum(nil), h).Next()
+	e, s := schnorrSign(p, q, g, x, msg, h, k)
+
+	meta := map[string]string{"algorithm": "Schnorr", "hash": h.String(), "nonce": "RFC6979"}
+	if err := helpers.WriteRecord(SchnorrSignatureFile, []string{"algorithm", "hash", "nonce"}, meta, []*big.Int{e, s}); err != nil {
+		return fmt.Errorf("failed to save signature: %v", err)
+	}
+	log.Printf("[INFO] Schnorr signature (e, s) of %s saved to %s", DocumentFile, SchnorrSignatureFile)
+	return nil
+}
+
+// VerifySchnorr verifies the Schnorr signature of the document.
+func VerifySchnorr(DocumentFile, DSAPublicKeyFile, SchnorrSignatureFile string) error {
+	p, q, g, y, err := readDSAKey(DSAPublicKeyFile)
+	if err != nil {
+		return err
+	}
+	sig, err := readSignatureOf(SchnorrSignatureFile, "Schnorr")
+	if err != nil {
+		return err
+	}
+	h, err := sig.Hash()
+	if err != nil {
+		return err
+	}
+	msg, err := os.ReadFile(DocumentFile)
+	if err != nil {
+		return err
+	}
+
+	valid := schnorrVerify(p, q, g, y, msg, h, sig.Values[0], sig.Values[1])
+	return writeVerifyResult(valid, "H(g^s · y^-e || m) != e")
+}
+
+// readSignatureOf reads a two-number signature file and checks its algorithm.
+func readSignatureOf(path, algorithm string) (*helpers.Record, error) {
+	sig, err := helpers.ReadRecord(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %v", err)
+	}
+	if sig.Meta["algorithm"] != algorithm {
+		return nil, fmt.Errorf("%s is not a %s signature (algorithm %q)", path, algorithm, sig.Meta["algorithm"])
+	}
+	if len(sig.Values) != 2 {
+		return nil, fmt.Errorf("expected 2 numbers in %s, got %d", path, len(sig.Values))
+	}
+	return sig, nil
+}
+
+// writeVerifyResult saves T or N to VerifyFile, prints it, and returns an error for an invalid signature.
+func writeVerifyResult(valid bool, reason string) error {
+	result := "T"
+	if !valid {
+		result = "N"
+	}
+	if err := os.WriteFile(VerifyFile, []byte(result), 0644); err != nil {
+		return fmt.Errorf("failed to write verification result: %v", err)
+	}
+	fmt.Println(result)
+	if !valid {
+		return fmt.Errorf("invalid signature: %s", reason)
+	}
+	return nil
+}
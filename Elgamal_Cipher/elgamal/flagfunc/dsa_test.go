@@ -0,0 +1,96 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"crypto"
+	"crypto/dsa" //nolint:staticcheck // reference implementation for the compatibility check
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"elgamal/helpers"
+)
+
+// Textbook group p = 23, q = 11, g = 4 with x = 3, y = g^x mod p = 18
+var (
+	toyP, toyQ, toyG = big.NewInt(23), big.NewInt(11), big.NewInt(4)
+	toyX, toyY       = big.NewInt(3), big.NewInt(18)
+)
+
+func TestToyGroup(t *testing.T) {
+	if y := new(big.Int).Exp(toyG, toyX, toyP); y.Cmp(toyY) != 0 {
+		t.Errorf("g^x mod p = %s, expected 18", y)
+	}
+}
+
+// TestDSAToy signs z = 5 with k = 7: r = (4^7 mod 23) mod 11 = 8, s = 7⁻¹·(5 + 3·8) mod 11 = 1.
+func TestDSAToy(t *testing.T) {
+	r, s := dsaSign(toyP, toyQ, toyG, toyX, big.NewInt(5), func() *big.Int { return big.NewInt(7) })
+	if r.Int64() != 8 || s.Int64() != 1 {
+		t.Errorf("(r, s) = (%d, %d), expected (8, 1)", r, s)
+	}
+	if !dsaVerify(toyP, toyQ, toyG, toyY, big.NewInt(5), r, s) {
+		t.Errorf("signature of z = 5 does not verify")
+	}
+	if dsaVerify(toyP, toyQ, toyG, toyY, big.NewInt(6), r, s) {
+		t.Errorf("signature of z = 5 verifies for z = 6")
+	}
+}
+
+// TestSchnorrToy signs "abc" with k = 9: R = 13, e = SHA-256(0x0d || "abc") → 9, s = 9 + 3·9 mod 11 = 3.
+func TestSchnorrToy(t *testing.T) {
+	e, s := schnorrSign(toyP, toyQ, toyG, toyX, []byte("abc"), crypto.SHA256, big.NewInt(9))
+	if e.Int64() != 9 || s.Int64() != 3 {
+		t.Errorf("(e, s) = (%d, %d), expected (9, 3)", e, s)
+	}
+	if !schnorrVerify(toyP, toyQ, toyG, toyY, []byte("abc"), crypto.SHA256, e, s) {
+		t.Errorf("signature of \"abc\" does not verify")
+	}
+	if schnorrVerify(toyP, toyQ, toyG, toyY, []byte("abd"), crypto.SHA256, e, s) {
+		t.Errorf("signature of \"abc\" verifies for \"abd\"")
+	}
+}
+
+// TestDSACompatibility signs and verifies in both directions with crypto/dsa on a 1024/160 group.
+func TestDSACompatibility(t *testing.T) {
+	var params dsa.Parameters
+	if err := dsa.GenerateParameters(&params, rand.Reader, dsa.L1024N160); err != nil {
+		t.Fatalf("failed to generate DSA parameters: %v", err)
+	}
+	priv := dsa.PrivateKey{PublicKey: dsa.PublicKey{Parameters: params}}
+	if err := dsa.GenerateKey(&priv, rand.Reader); err != nil {
+		t.Fatalf("failed to generate DSA key: %v", err)
+	}
+	P, Q, G, X, Y := params.P, params.Q, params.G, priv.X, priv.Y
+
+	msg := []byte("compatibility check")
+	digest := sha256.Sum256(msg)
+	hashed := digest[:Q.BitLen()/8] // leftmost N bits, as crypto/dsa expects
+	z := helpers.Bits2Int(digest[:], Q)
+
+	r, s := dsaSign(P, Q, G, X, z, helpers.NewNonceGenerator(Q, X, digest[:], crypto.SHA256).Next)
+	if !dsa.Verify(&priv.PublicKey, hashed, r, s) {
+		t.Errorf("crypto/dsa rejects our DSA signature")
+	}
+
+	r2, s2, err := dsa.Sign(rand.Reader, &priv, hashed)
+	if err != nil {
+		t.Fatalf("crypto/dsa failed to sign: %v", err)
+	}
+	if !dsaVerify(P, Q, G, Y, z, r2, s2) {
+		t.Errorf("our DSA rejects the crypto/dsa signature")
+	}
+
+	// RFC 6979 makes signatures deterministic
+	r3, s3 := dsaSign(P, Q, G, X, z, helpers.NewNonceGenerator(Q, X, digest[:], crypto.SHA256).Next)
+	if r3.Cmp(r) != 0 || s3.Cmp(s) != 0 {
+		t.Errorf("DSA with RFC 6979 gives two different signatures of the same message")
+	}
+
+	k := helpers.NewNonceGenerator(Q, X, digest[:], crypto.SHA256).Next()
+	e, s := schnorrSign(P, Q, G, X, msg, crypto.SHA256, k)
+	if !schnorrVerify(P, Q, G, Y, msg, crypto.SHA256, e, s) {
+		t.Errorf("Schnorr signature does not verify in the 1024/160 group")
+	}
+}
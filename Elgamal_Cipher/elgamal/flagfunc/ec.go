@@ -0,0 +1,402 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"elgamal/helpers"
+)
+
+const (
+	ECPrivateKeyFile = "files/ec_private.txt"
+	ECPublicKeyFile  = "files/ec_public.txt"
+	ECCryptoFile     = "files/ec_crypto.txt"
+	ECSignatureFile  = "files/ec_signature.txt"
+
+	CurveP256    = "P-256"
+	CurveX25519  = "X25519"
+	CurveEd25519 = "Ed25519"
+
+	ecHybridHeader = "EC-ELGAMAL-HYBRID-V1"
+	ecHybridInfo   = "ec-elgamal hybrid aes-256-gcm v1"
+)
+
+// Curve is the curve for -ec-k and the teaching mode (set from the -curve flag).
+// Empty means P-256 for keys and toy17 for -ec-toy.
+var Curve = ""
+
+// EC keys--------------------------------------------------------------------------------------------
+// Key files use the same "name: value" layout as signature files:
+//
+//	curve: P-256
+//	private: <hex>        (public: <hex> in the public key file)
+//
+// P-256 keys are used for EC-ElGamal (ECDH) and ECDSA, X25519 only for encryption,
+// Ed25519 only for signatures.
+
+// GenerateECKeys creates a key pair on the chosen curve.
+func GenerateECKeys(curve string) error {
+	var priv, pub []byte
+	switch curve {
+	case CurveP256, CurveX25519:
+		key, err := ecdhCurve(curve).GenerateKey(rand.Reader)
+		if err != nil {
+			return fmt.Errorf("failed to generate %s key: %v", curve, err)
+		}
+		priv, pub = key.Bytes(), key.PublicKey().Bytes()
+	case CurveEd25519:
+		pubKey, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return fmt.Errorf("failed to generate Ed25519 key: %v", err)
+		}
+		priv, pub = key.Seed(), pubKey
+	default:
+		return fmt.Errorf("unsupported curve %q for keys: use %s, %s or %s", curve, CurveP256, CurveX25519, CurveEd25519)
+	}
+
+	keys := []string{"curve", "private"}
+	if err := helpers.WriteRecord(ECPrivateKeyFile, keys, map[string]string{"curve": curve, "private": hex.EncodeToString(priv)}, nil); err != nil {
+		return fmt.Errorf("failed to save private key: %v", err)
+	}
+	keys = []string{"curve", "public"}
+	if err := helpers.WriteRecord(ECPublicKeyFile, keys, map[string]string{"curve": curve, "public": hex.EncodeToString(pub)}, nil); err != nil {
+		return fmt.Errorf("failed to save public key: %v", err)
+	}
+	log.Printf("[INFO] %s key pair saved to %s and %s", curve, ECPrivateKeyFile, ECPublicKeyFile)
+	return nil
+}
+
+// readECKey returns the curve identifier and the key bytes stored under field ("private" or "public").
+func readECKey(path, field string) (string, []byte, error) {
+	file, err := helpers.ReadRecord(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read key: %v", err)
+	}
+	curve, ok := file.Meta["curve"]
+	if !ok {
+		return "", nil, fmt.Errorf("%s has no curve identifier", path)
+	}
+	key, err := hex.DecodeString(file.Meta[field])
+	if err != nil || len(key) == 0 {
+		return "", nil, fmt.Errorf("%s has no valid %s key", path, field)
+	}
+	return curve, key, nil
+}
+
+func ecdhCurve(curve string) ecdh.Curve {
+	if curve == CurveX25519 {
+		return ecdh.X25519()
+	}
+	return ecdh.P256()
+}
+
+// EC-ElGamal (hybrid KEM)-----------------------------------------------------------------------------
+// The same idea as -he: an ephemeral key k gives C1 = k·G and the shared point k·Q = d·C1,
+// so C1 is the ElGamal "c1" and the shared secret only keys AES-256-GCM.
+
+// ecHybridKey derives the AES key bound to the ephemeral and the recipient public keys.
+func ecHybridKey(shared, ephemeral, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	return helpers.HKDF(shared, salt, []byte(ecHybridInfo), 32)
+}
+
+func ecHybridAAD(curve string, ephemeral []byte) []byte {
+	return []byte(ecHybridHeader + "\n" + curve + "\n" + hex.EncodeToString(ephemeral))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptEC encrypts PlainFile for the P-256 or X25519 public key into ECCryptoFile.
+func EncryptEC(PlainFile, ECPublicKeyFile string) error {
+	curve, pubBytes, err := readECKey(ECPublicKeyFile, "public")
+	if err != nil {
+		return err
+	}
+	if curve != CurveP256 && curve != CurveX25519 {
+		return fmt.Errorf("%s keys cannot encrypt, use %s or %s", curve, CurveP256, CurveX25519)
+	}
+	pub, err := ecdhCurve(curve).NewPublicKey(pubBytes)
+	if err != nil {
+		return fmt.Errorf("invalid %s public key: %v", curve, err)
+	}
+
+	plain, err := os.ReadFile(PlainFile)
+	if err != nil {
+		return err
+	}
+
+	// C1 = k·G, shared = k·Q
+	ephemeral, err := ecdhCurve(curve).GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate ephemeral key: %v", err)
+	}
+	shared, err := ephemeral.ECDH(pub)
+	if err != nil {
+		return fmt.Errorf("ECDH failed: %v", err)
+	}
+	c1 := ephemeral.PublicKey().Bytes()
+
+	key, err := ecHybridKey(shared, c1, pubBytes)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	sealed := gcm.Seal(nil, nonce, plain, ecHybridAAD(curve, c1))
+
+	container := strings.Join([]string{
+		ecHybridHeader,
+		curve,
+		hex.EncodeToString(c1),
+		base64.StdEncoding.EncodeToString(nonce),
+		base64.StdEncoding.EncodeToString(sealed),
+	}, "\n") + "\n"
+	if err := os.WriteFile(ECCryptoFile, []byte(container), 0644); err != nil {
+		return fmt.Errorf("failed to write container: %v", err)
+	}
+	log.Printf("[INFO] Encrypted %d bytes for %s, C1 = %x", len(plain), curve, c1)
+	return nil
+}
+
+// DecryptEC opens ECCryptoFile with the private key and writes DecryptedFile after the tag is verified.
+func DecryptEC(ECCryptoFile, ECPrivateKeyFile string) error {
+	curve, privBytes, err := readECKey(ECPrivateKeyFile, "private")
+	if err != nil {
+		return err
+	}
+	if curve != CurveP256 && curve != CurveX25519 {
+		return fmt.Errorf("%s keys cannot decrypt, use %s or %s", curve, CurveP256, CurveX25519)
+	}
+	priv, err := ecdhCurve(curve).NewPrivateKey(privBytes)
+	if err != nil {
+		return fmt.Errorf("invalid %s private key: %v", curve, err)
+	}
+
+	lines, err := readLines(ECCryptoFile)
+	if err != nil {
+		return err
+	}
+	if len(lines) != 5 || lines[0] != ecHybridHeader {
+		return fmt.Errorf("%s is not a %s container", ECCryptoFile, ecHybridHeader)
+	}
+	if lines[1] != curve {
+		return fmt.Errorf("container is for %s, the key is %s", lines[1], curve)
+	}
+	c1, err := hex.DecodeString(lines[2])
+	if err != nil {
+		return fmt.Errorf("invalid C1 in %s: %v", ECCryptoFile, err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return fmt.Errorf("invalid nonce in %s: %v", ECCryptoFile, err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(lines[4])
+	if err != nil {
+		return fmt.Errorf("invalid ciphertext in %s: %v", ECCryptoFile, err)
+	}
+
+	// NewPublicKey rejects points that are not on the curve
+	ephemeral, err := ecdhCurve(curve).NewPublicKey(c1)
+	if err != nil {
+		return fmt.Errorf("invalid C1: %v", err)
+	}
+	shared, err := priv.ECDH(ephemeral)
+	if err != nil {
+		return fmt.Errorf("ECDH failed: %v", err)
+	}
+	key, err := ecHybridKey(shared, c1, priv.PublicKey().Bytes())
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return fmt.Errorf("invalid container: nonce has %d bytes, expected %d", len(nonce), gcm.NonceSize())
+	}
+	plain, err := gcm.Open(nil, nonce, sealed, ecHybridAAD(curve, c1))
+	if err != nil {
+		fmt.Println("Authentication failed: wrong key or modified container")
+		return fmt.Errorf("authentication tag verification failed: %v", err)
+	}
+
+	if err := os.WriteFile(DecryptedFile, plain, 0644); err != nil {
+		return fmt.Errorf("failed to write decrypted message: %v", err)
+	}
+	log.Printf("[INFO] Decrypted %d bytes", len(plain))
+	return nil
+}
+
+// readLines returns the non-empty lines of a container file.
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<30)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// ECDSA / EdDSA--------------------------------------------------------------------------------------
+
+// p256ECDSAKey turns the raw P-256 scalar into an ecdsa key.
+func p256ECDSAKey(privBytes []byte) (*ecdsa.PrivateKey, error) {
+	priv, err := ecdh.P256().NewPrivateKey(privBytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := p256ECDSAPublicKey(priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(privBytes)}, nil
+}
+
+// p256ECDSAPublicKey parses the uncompressed point 04 || X || Y.
+func p256ECDSAPublicKey(pubBytes []byte) (*ecdsa.PublicKey, error) {
+	if _, err := ecdh.P256().NewPublicKey(pubBytes); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(pubBytes[1:33]),
+		Y:     new(big.Int).SetBytes(pubBytes[33:65]),
+	}, nil
+}
+
+// SignEC signs the document with ECDSA (P-256, hash from -hash) or Ed25519.
+func SignEC(DocumentFile, ECPrivateKeyFile string) error {
+	curve, privBytes, err := readECKey(ECPrivateKeyFile, "private")
+	if err != nil {
+		return err
+	}
+	doc, err := os.ReadFile(DocumentFile)
+	if err != nil {
+		return err
+	}
+
+	switch curve {
+	case CurveP256:
+		h, err := helpers.ParseHash(SignHash)
+		if err != nil {
+			return err
+		}
+		key, err := p256ECDSAKey(privBytes)
+		if err != nil {
+			return fmt.Errorf("invalid P-256 private key: %v", err)
+		}
+		hasher := h.New()
+		hasher.Write(doc)
+		r, s, err := ecdsa.Sign(rand.Reader, key, hasher.Sum(nil))
+		if err != nil {
+			return fmt.Errorf("ECDSA signing failed: %v", err)
+		}
+		meta := map[string]string{"algorithm": "ECDSA", "curve": curve, "hash": h.String()}
+		if err := helpers.WriteRecord(ECSignatureFile, []string{"algorithm", "curve", "hash"}, meta, []*big.Int{r, s}); err != nil {
+			return fmt.Errorf("failed to save signature: %v", err)
+		}
+	case CurveEd25519:
+		if len(privBytes) != ed25519.SeedSize {
+			return fmt.Errorf("invalid Ed25519 private key")
+		}
+		// Ed25519 hashes the message itself with SHA-512
+		sig := ed25519.Sign(ed25519.NewKeyFromSeed(privBytes), doc)
+		meta := map[string]string{"algorithm": "Ed25519", "curve": curve, "hash": "SHA-512", "signature": hex.EncodeToString(sig)}
+		if err := helpers.WriteRecord(ECSignatureFile, []string{"algorithm", "curve", "hash", "signature"}, meta, nil); err != nil {
+			return fmt.Errorf("failed to save signature: %v", err)
+		}
+	default:
+		return fmt.Errorf("%s keys cannot sign, use %s or %s", curve, CurveP256, CurveEd25519)
+	}
+	log.Printf("[INFO] Signed %s with %s, signature saved to %s", DocumentFile, curve, ECSignatureFile)
+	return nil
+}
+
+// VerifyEC checks an ECDSA or Ed25519 signature and writes T or N to VerifyFile.
+func VerifyEC(DocumentFile, ECPublicKeyFile, ECSignatureFile string) error {
+	curve, pubBytes, err := readECKey(ECPublicKeyFile, "public")
+	if err != nil {
+		return err
+	}
+	sig, err := helpers.ReadRecord(ECSignatureFile)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %v", err)
+	}
+	if sig.Meta["curve"] != curve {
+		return fmt.Errorf("signature is for curve %q, the key is %s", sig.Meta["curve"], curve)
+	}
+	doc, err := os.ReadFile(DocumentFile)
+	if err != nil {
+		return err
+	}
+
+	valid := false
+	reason := ""
+	switch curve {
+	case CurveP256:
+		if len(sig.Values) != 2 {
+			return fmt.Errorf("expected 2 numbers (r, s) in %s, got %d", ECSignatureFile, len(sig.Values))
+		}
+		h, err := sig.Hash()
+		if err != nil {
+			return err
+		}
+		pub, err := p256ECDSAPublicKey(pubBytes)
+		if err != nil {
+			return fmt.Errorf("invalid P-256 public key: %v", err)
+		}
+		hasher := h.New()
+		hasher.Write(doc)
+		valid = ecdsa.Verify(pub, hasher.Sum(nil), sig.Values[0], sig.Values[1])
+		reason = "ECDSA equation does not hold"
+	case CurveEd25519:
+		signature, err := hex.DecodeString(sig.Meta["signature"])
+		if err != nil {
+			return fmt.Errorf("invalid Ed25519 signature in %s", ECSignatureFile)
+		}
+		if len(pubBytes) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid Ed25519 public key")
+		}
+		valid = ed25519.Verify(ed25519.PublicKey(pubBytes), doc, signature)
+		reason = "Ed25519 signature does not match"
+	default:
+		return fmt.Errorf("%s keys cannot verify signatures, use %s or %s", curve, CurveP256, CurveEd25519)
+	}
+	return writeVerifyResult(valid, reason)
+}
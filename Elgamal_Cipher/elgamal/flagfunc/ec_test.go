@@ -0,0 +1,47 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"elgamal/helpers"
+)
+
+// TestP256Compatibility checks the big.Int arithmetic on P-256 against crypto/ecdh and crypto/ecdsa.
+func TestP256Compatibility(t *testing.T) {
+	p256 := helpers.P256Curve()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate P-256 key: %v", err)
+	}
+	Q := p256.ScalarMult(new(big.Int).SetBytes(key.Bytes()), p256.G)
+	pub := key.PublicKey().Bytes()
+	if Q.X.Cmp(new(big.Int).SetBytes(pub[1:33])) != 0 || Q.Y.Cmp(new(big.Int).SetBytes(pub[33:])) != 0 {
+		t.Errorf("d·G = %s differs from the crypto/ecdh public key %x", Q, pub)
+	}
+
+	ecdsaKey, err := p256ECDSAKey(key.Bytes())
+	if err != nil {
+		t.Fatalf("failed to convert P-256 key: %v", err)
+	}
+	digest := sha256.Sum256([]byte("compatibility check"))
+	r, s, err := ecdsa.Sign(rand.Reader, ecdsaKey, digest[:])
+	if err != nil {
+		t.Fatalf("crypto/ecdsa failed to sign: %v", err)
+	}
+	// ECDSA verification by hand: (u1·G + u2·Q).x mod n = r with u1 = z·s⁻¹, u2 = r·s⁻¹
+	sInv := new(big.Int).ModInverse(s, p256.N)
+	u1 := new(big.Int).Mul(new(big.Int).SetBytes(digest[:]), sInv)
+	u1.Mod(u1, p256.N)
+	u2 := new(big.Int).Mul(r, sInv)
+	u2.Mod(u2, p256.N)
+	R := p256.Add(p256.ScalarMult(u1, p256.G), p256.ScalarMult(u2, Q))
+	if new(big.Int).Mod(R.X, p256.N).Cmp(r) != 0 {
+		t.Errorf("crypto/ecdsa signature does not verify with the big.Int arithmetic")
+	}
+}
@@ -0,0 +1,155 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"elgamal/helpers"
+)
+
+const (
+	ECToyFile = "files/ec_toy.txt"
+
+	// maxListedMultiples limits the table of k·G and maxTracedBlocks the detailed EC-ElGamal steps
+	maxListedMultiples = 40
+	maxTracedBlocks    = 4
+)
+
+// Teaching mode--------------------------------------------------------------------------------------
+// Point addition and doubling step by step on a textbook curve, then EC-ElGamal with
+// Koblitz encoding of plain.txt using the same big.Int arithmetic.
+
+// ECTeaching runs the teaching mode on the named curve and saves the transcript to ECToyFile.
+func ECTeaching(curveName, PlainFile string) error {
+	c, err := helpers.LookupCurve(curveName)
+	if err != nil {
+		return err
+	}
+	m, err := readMessageNumber(PlainFile)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	if err := ecTeach(&sb, c, m); err != nil {
+		return err
+	}
+	fmt.Print(sb.String())
+	if err := os.WriteFile(ECToyFile, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to save transcript: %v", err)
+	}
+	log.Printf("[INFO] Teaching mode on %s saved to %s", c.Name, ECToyFile)
+	return nil
+}
+
+// readMessageNumber reads plain.txt as a number, or as text converted to a number (as -e does).
+func readMessageNumber(PlainFile string) (*big.Int, error) {
+	content, err := os.ReadFile(PlainFile)
+	if err != nil {
+		return nil, err
+	}
+	raw := strings.TrimSpace(string(content))
+	m, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		m = new(big.Int).SetBytes([]byte(raw))
+	}
+	return m, nil
+}
+
+func ecTeach(w io.Writer, c *helpers.Curve, m *big.Int) error {
+	fmt.Fprintf(w, "Curve %s: y² = x³ + (%s)·x + %s mod %s\n", c.Name, c.A, c.B, c.P)
+	fmt.Fprintf(w, "G = %s, on curve: %v, order n = %s\n", c.G, c.IsOnCurve(c.G), c.N)
+	if !c.IsOnCurve(c.G) {
+		return fmt.Errorf("G is not on the curve")
+	}
+
+	// Formulas on the first multiples of G
+	fmt.Fprintf(w, "\nPoint doubling, 2G:\n")
+	c.Trace = w
+	g2 := c.Double(c.G)
+	fmt.Fprintf(w, "Point addition, 3G = 2G + G:\n")
+	g3 := c.Add(g2, c.G)
+	fmt.Fprintf(w, "Negation: −G = %s, G + (−G):\n", c.Neg(c.G))
+	c.Add(c.G, c.Neg(c.G))
+	c.Trace = nil
+	fmt.Fprintf(w, "2G = %s, 3G = %s, both on curve: %v\n", g2, g3, c.IsOnCurve(g2) && c.IsOnCurve(g3))
+
+	if c.N.Cmp(big.NewInt(maxListedMultiples)) <= 0 {
+		fmt.Fprintf(w, "\nMultiples of G:\n")
+		pt := helpers.Infinity
+		for k := int64(1); k <= c.N.Int64(); k++ {
+			pt = c.Add(pt, c.G)
+			fmt.Fprintf(w, "  %2d·G = %s\n", k, pt)
+		}
+	}
+
+	// Key pair d, Q = d·G
+	d, err := helpers.RandomBigInt(new(big.Int).Sub(c.N, big.NewInt(1)))
+	if err != nil {
+		return err
+	}
+	d.Add(d, big.NewInt(1)) // 1 <= d < n
+	small := c.P.BitLen() <= 16
+	fmt.Fprintf(w, "\nKey pair: d = %s, Q = d·G (double-and-add over the bits of d = %b)\n", d, d)
+	if small {
+		c.Trace = w
+	}
+	Q := c.ScalarMult(d, c.G)
+	c.Trace = nil
+	fmt.Fprintf(w, "Q = %s\n", Q)
+
+	// EC-ElGamal with Koblitz encoding: m is written in base M, every digit becomes one point
+	K, M, err := helpers.KoblitzParams(c.P)
+	if err != nil {
+		return err
+	}
+	var digits []*big.Int
+	for rest := new(big.Int).Set(m); ; {
+		digit := new(big.Int)
+		rest.DivMod(rest, M, digit)
+		digits = append(digits, digit)
+		if rest.Sign() == 0 {
+			break
+		}
+	}
+	fmt.Fprintf(w, "\nEC-ElGamal, Koblitz encoding with K = %d: m = %s written in base M = ⌊p/K⌋ = %s gives %d block(s)\n", K, m, M, len(digits))
+	fmt.Fprintf(w, "  encrypt: M_i = (m_i·K + j, y), C1 = k·G, C2 = M_i + k·Q; decrypt: M_i = C2 − d·C1, m_i = ⌊x/K⌋\n")
+
+	recovered := new(big.Int)
+	for i := len(digits) - 1; i >= 0; i-- {
+		point, err := c.KoblitzEncode(digits[i], K)
+		if err != nil {
+			return fmt.Errorf("block %d: %v", i, err)
+		}
+		k, err := helpers.RandomBigInt(new(big.Int).Sub(c.N, big.NewInt(1)))
+		if err != nil {
+			return err
+		}
+		k.Add(k, big.NewInt(1))
+		c1 := c.ScalarMult(k, c.G)
+		c2 := c.Add(point, c.ScalarMult(k, Q))
+
+		// Decryption only knows d, C1 and C2
+		dec := c.Add(c2, c.Neg(c.ScalarMult(d, c1)))
+		digit := helpers.KoblitzDecode(dec, K)
+		if i >= len(digits)-maxTracedBlocks {
+			fmt.Fprintf(w, "  block %d: m_i = %s → M_i = %s, k = %s, C1 = %s, C2 = %s → M_i = %s, m_i = %s\n",
+				i, digits[i], point, k, c1, c2, dec, digit)
+		}
+		recovered.Mul(recovered, M)
+		recovered.Add(recovered, digit)
+	}
+	if len(digits) > maxTracedBlocks {
+		fmt.Fprintf(w, "  ... %d more block(s)\n", len(digits)-maxTracedBlocks)
+	}
+	fmt.Fprintf(w, "Decrypted m = %s, matches: %v\n", recovered, recovered.Cmp(m) == 0)
+	if recovered.Cmp(m) != 0 {
+		return fmt.Errorf("EC-ElGamal round trip failed")
+	}
+	return nil
+}
@@ -2,6 +2,8 @@
 package flagfunc
 
 import (
+	"crypto"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"math/big"
@@ -23,11 +25,29 @@ const (
 	VerifyFile     = "files/verify.txt"
 )
 
-
-var MessageString string = "Bla"
+var (
+	// SignHash is the hash function used by -s (set from the -hash flag)
+	SignHash = "sha256"
+	// DocumentFile is the document signed by -s and checked by -v (set from the -msg flag)
+	DocumentFile = MessageFile
+	// SignaturePath is the signature written by -s and read by -v (set from the -sig flag)
+	SignaturePath = SignatureFile
+	// FixedK forces the nonce of -s (set from the -fixk flag); nil means a random k
+	FixedK *big.Int
+)
 
 func ExecuteCipher(operation string) error {
 	switch operation {
+	case "gp":
+		// Generate safe prime p = 2q+1 and generator g of the order-q subgroup
+		// OUT ElgamalFile
+		err := GenerateParams(ParamBits, ParamQBits, ElgamalFile)
+		if err != nil {
+			return fmt.Errorf("error during generation of parameters: %v", err)
+		}
+		log.Println("[INFO] Parameters p and g have been successfully created.")
+		return nil
+
 	case "k":
 		// Generate public and private key for Bolek
 		// IN ElgamalFile, OUT PrivateKeyFile, PublicKeyFile 
@@ -39,8 +59,8 @@ func ExecuteCipher(operation string) error {
 		return nil
 
 	case "e":
-		// IN PlainFile, PublicKeyFile, OUT CryptoFile
-		err := EncryptElgamal(PlainFile, PublicKeyFile)
+		// IN PlainPath, PublicKeyFile, OUT CipherPath
+		err := EncryptElgamal(PlainPath, PublicKeyFile)
 		if err != nil {
 			return fmt.Errorf("failed to encrypt the text: %v", err)
 		}
@@ -49,17 +69,37 @@ func ExecuteCipher(operation string) error {
 
 	case "d":
 		// Decrypt crypto.txt using private.txt
-		// IN CryptoFile, PrivateKeyFile, OUT DecryptedFile
-		err := DecryptElgamal(CryptoFile, PrivateKeyFile)
+		// IN CipherPath, PrivateKeyFile, OUT DecryptedFile
+		err := DecryptElgamal(CipherPath, PrivateKeyFile)
 		if err != nil {
 			return fmt.Errorf("failed to decrypt the text: %v", err)
 		}
 		log.Println("[INFO] Text successfully decrypted into decrypt.txt.")
 		return nil
+	case "he":
+		// Hybrid encryption of a message of any length
+		// IN PlainFile, PublicKeyFile, OUT HybridFile
+		err := EncryptHybrid(PlainFile, PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt the file: %v", err)
+		}
+		log.Println("[INFO] File successfully encrypted into crypto_hybrid.txt.")
+		return nil
+
+	case "hd":
+		// Hybrid decryption, the tag is verified before anything is written
+		// IN HybridFile, PrivateKeyFile, OUT DecryptedFile
+		err := DecryptHybrid(HybridFile, PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt the file: %v", err)
+		}
+		log.Println("[INFO] File successfully decrypted into decrypt.txt.")
+		return nil
+
 	case "s":
 		// Sign the message
-		// IN MessageFile, PrivateKeyFile, OUT SignatureFile
-		err := SignMsg(MessageFile, PrivateKeyFile)
+		// IN DocumentFile, PrivateKeyFile, OUT SignaturePath
+		err := SignMsg(DocumentFile, PrivateKeyFile)
 		if err != nil {
 			return fmt.Errorf("failed to sing the message: %v", err)
 		}
@@ -67,14 +107,242 @@ func ExecuteCipher(operation string) error {
 		return nil
 	case "v":
 		// Verify the signed message
-		// IN MessageFile, PublicKeyFile, SignatureFile, OUT VerifyFile
-		err := VerifySignature(MessageFile, PublicKeyFile, SignatureFile)
+		// IN DocumentFile, PublicKeyFile, SignaturePath, OUT VerifyFile
+		err := VerifySignature(DocumentFile, PublicKeyFile, SignaturePath)
 		if err != nil {
 			return fmt.Errorf("failed to verify the sign: %v", err)
 		}
 		log.Println("[INFO] Signature successfully verified.")
 		return nil
 
+	case "dk":
+		// Generate DSA/Schnorr keys in the prime-order subgroup
+		// IN ElgamalFile, OUT DSAPrivateKeyFile, DSAPublicKeyFile
+		err := GenerateDSAKeys(ElgamalFile)
+		if err != nil {
+			return fmt.Errorf("error during generation of DSA keys: %v", err)
+		}
+		log.Println("[INFO] DSA/Schnorr keys have been successfully created.")
+		return nil
+
+	case "ds":
+		// IN DocumentFile, DSAPrivateKeyFile, OUT DSASignatureFile
+		err := SignDSA(DocumentFile, DSAPrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to sign the message with DSA: %v", err)
+		}
+		log.Println("[INFO] Message successfully signed into dsa_signature.txt.")
+		return nil
+
+	case "dv":
+		// IN DocumentFile, DSAPublicKeyFile, DSASignatureFile, OUT VerifyFile
+		err := VerifyDSA(DocumentFile, DSAPublicKeyFile, DSASignatureFile)
+		if err != nil {
+			return fmt.Errorf("failed to verify the DSA signature: %v", err)
+		}
+		log.Println("[INFO] DSA signature successfully verified.")
+		return nil
+
+	case "ss":
+		// IN DocumentFile, DSAPrivateKeyFile, OUT SchnorrSignatureFile
+		err := SignSchnorr(DocumentFile, DSAPrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to sign the message with Schnorr: %v", err)
+		}
+		log.Println("[INFO] Message successfully signed into schnorr_signature.txt.")
+		return nil
+
+	case "sv":
+		// IN DocumentFile, DSAPublicKeyFile, SchnorrSignatureFile, OUT VerifyFile
+		err := VerifySchnorr(DocumentFile, DSAPublicKeyFile, SchnorrSignatureFile)
+		if err != nil {
+			return fmt.Errorf("failed to verify the Schnorr signature: %v", err)
+		}
+		log.Println("[INFO] Schnorr signature successfully verified.")
+		return nil
+
+	case "na":
+		// IN PublicKeyFile, two documents signed with the same k, OUT NonceAttackFile
+		err := NonceReuseAttack(PublicKeyFile, DocumentFile, SignaturePath, Document2File, Signature2Path)
+		if err != nil {
+			return fmt.Errorf("nonce-reuse attack failed: %v", err)
+		}
+		log.Println("[INFO] Nonce-reuse attack finished.")
+		return nil
+
+	case "ek":
+		// IN Curve, OUT ECPrivateKeyFile, ECPublicKeyFile
+		curve := Curve
+		if curve == "" {
+			curve = CurveP256
+		}
+		err := GenerateECKeys(curve)
+		if err != nil {
+			return fmt.Errorf("error during generation of EC keys: %v", err)
+		}
+		log.Println("[INFO] EC keys have been successfully created.")
+		return nil
+
+	case "ee":
+		// IN PlainFile, ECPublicKeyFile, OUT ECCryptoFile
+		err := EncryptEC(PlainFile, ECPublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("EC-ElGamal encryption failed: %v", err)
+		}
+		log.Println("[INFO] Message successfully encrypted with EC-ElGamal.")
+		return nil
+
+	case "ed":
+		// IN ECCryptoFile, ECPrivateKeyFile, OUT DecryptedFile
+		err := DecryptEC(ECCryptoFile, ECPrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("EC-ElGamal decryption failed: %v", err)
+		}
+		log.Println("[INFO] Message successfully decrypted with EC-ElGamal.")
+		return nil
+
+	case "es":
+		// IN DocumentFile, ECPrivateKeyFile, OUT ECSignatureFile
+		err := SignEC(DocumentFile, ECPrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to sign the message with ECDSA/Ed25519: %v", err)
+		}
+		log.Println("[INFO] Message successfully signed into ec_signature.txt.")
+		return nil
+
+	case "ev":
+		// IN DocumentFile, ECPublicKeyFile, ECSignatureFile, OUT VerifyFile
+		err := VerifyEC(DocumentFile, ECPublicKeyFile, ECSignatureFile)
+		if err != nil {
+			return fmt.Errorf("failed to verify the EC signature: %v", err)
+		}
+		log.Println("[INFO] EC signature successfully verified.")
+		return nil
+
+	case "et":
+		// IN Curve, PlainFile, OUT ECToyFile
+		curve := Curve
+		if curve == "" {
+			curve = "toy17"
+		}
+		err := ECTeaching(curve, PlainFile)
+		if err != nil {
+			return fmt.Errorf("teaching mode failed: %v", err)
+		}
+		return nil
+
+	case "cv":
+		// IN PrivateKeyFile, PublicKeyFile (any format), OUT the same files in KeyFormat
+		err := ConvertKeys(PrivateKeyFile, PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("key conversion failed: %v", err)
+		}
+		return nil
+
+	case "fp":
+		// IN PublicKeyFile, PrivateKeyFile
+		err := ShowFingerprint(PrivateKeyFile, PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read keys: %v", err)
+		}
+		return nil
+
+	case "mul":
+		// IN CipherPath, Cipher2Path, PublicKeyFile, OUT ProductFile
+		err := MultiplyCiphertexts(PublicKeyFile, CipherPath, Cipher2Path)
+		if err != nil {
+			return fmt.Errorf("failed to multiply ciphertexts: %v", err)
+		}
+		return nil
+
+	case "rr":
+		// IN CipherPath, PublicKeyFile, OUT RerandomizedFile
+		err := RerandomizeCiphertext(PublicKeyFile, CipherPath)
+		if err != nil {
+			return fmt.Errorf("failed to re-randomize the ciphertext: %v", err)
+		}
+		return nil
+
+	case "xe":
+		// IN PlainPath, PublicKeyFile, OUT CipherPath
+		err := EncryptExp(PlainPath, PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("exponential ElGamal encryption failed: %v", err)
+		}
+		return nil
+
+	case "xd":
+		// IN CipherPath, PrivateKeyFile, OUT DecryptedFile
+		err := DecryptExp(CipherPath, PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("exponential ElGamal decryption failed: %v", err)
+		}
+		return nil
+
+	case "xa":
+		// IN CipherPath, Cipher2Path, PublicKeyFile, OUT SumFile
+		err := AddExpCiphertexts(PublicKeyFile, CipherPath, Cipher2Path)
+		if err != nil {
+			return fmt.Errorf("failed to add ciphertexts: %v", err)
+		}
+		return nil
+
+	case "vote":
+		// IN VotesFile, PublicKeyFile, PrivateKeyFile, OUT VoteReportFile
+		err := Vote(VotesFile, PublicKeyFile, PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("voting demo failed: %v", err)
+		}
+		return nil
+
+	case "split":
+		// IN PrivateKeyFile, ElgamalFile, OUT SharesDir/share_<i>.txt, CommitmentsFile
+		err := SplitKey(PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to split the private key: %v", err)
+		}
+		return nil
+
+	case "vs":
+		// IN SharesDir/share_<i>.txt, CommitmentsFile, PublicKeyFile
+		err := VerifyShares(PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("share verification failed: %v", err)
+		}
+		return nil
+
+	case "combine":
+		// IN SharesDir/share_<i>.txt, PublicKeyFile, OUT ReconstructFile
+		err := CombineKey(PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to reconstruct the private key: %v", err)
+		}
+		return nil
+
+	case "partial":
+		// IN CipherPath, SharesDir/share_<ShareIndex>.txt, OUT SharesDir/partial_<ShareIndex>.txt
+		err := PartialDecrypt(CipherPath)
+		if err != nil {
+			return fmt.Errorf("partial decryption failed: %v", err)
+		}
+		return nil
+
+	case "td":
+		// IN CipherPath, SharesDir/partial_<i>.txt, CommitmentsFile, OUT DecryptedFile
+		err := ThresholdDecrypt(CipherPath)
+		if err != nil {
+			return fmt.Errorf("threshold decryption failed: %v", err)
+		}
+		return nil
+
+	case "dlog":
+		// IN PublicKeyFile, CipherPath, OUT DlogReportFile, RecoveredKeyFile, DecryptedFile
+		err := DiscreteLogAttack(PublicKeyFile, CipherPath)
+		if err != nil {
+			return fmt.Errorf("discrete logarithm attack failed: %v", err)
+		}
+		return nil
+
 	default:
 		return fmt.Errorf("unsupported operation: %s", operation)
 	}
@@ -84,8 +352,22 @@ func ExecuteCipher(operation string) error {
 // Function generate 2 keys: beta is public key, b is private key and saves these values to file
 func GenerateKeys(ElgamalFile string) error {
 	// Read p and g from file
-	params, _ := helpers.ReadBigIntsFromFile(ElgamalFile, 2)
-	p, g := params[0], params[1]
+	p, g, q, err := ReadGroup(ElgamalFile)
+	if err != nil {
+		return fmt.Errorf("failed to read parameters: %v", err)
+	}
+
+	// Reject weak or invalid parameters before creating keys
+	warnings, err := ValidateParams(p, g, q, AllowWeak)
+	for _, w := range warnings {
+		fmt.Println("Warning:", w)
+		log.Printf("[WARN] %s", w)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return err
+	}
+	log.Println("[INFO] Parameters p and g passed validation")
 
 	// Generate b (random), where  1 <= b < p-1
 	upperLimit := new(big.Int).Sub(p, big.NewInt(2)) // p - 2
@@ -110,17 +392,25 @@ func GenerateKeys(ElgamalFile string) error {
 	log.Println("[INFO] Private b and public beta are < p")
 
 	// Save public and private keys
-	log.Printf("[INFO] Public and private keys were saved to file")
-	helpers.WriteBigIntsToFile(PrivateKeyFile, []*big.Int{p, g, b})
-	helpers.WriteBigIntsToFile(PublicKeyFile, []*big.Int{p, g, beta})
+	key := &helpers.ElGamalKey{P: p, G: g, Beta: beta, B: b}
+	if err := helpers.WriteKeyFile(PrivateKeyFile, key, KeyFormat, Passphrase); err != nil {
+		return fmt.Errorf("failed to save private key: %v", err)
+	}
+	if err := helpers.WriteKeyFile(PublicKeyFile, key.Public(), KeyFormat, ""); err != nil {
+		return fmt.Errorf("failed to save public key: %v", err)
+	}
+	log.Printf("[INFO] Public and private keys were saved to file (%s), fingerprint %s", KeyFormat, key.Fingerprint())
 
 	return nil
 }
 
 func EncryptElgamal(PlainFile, PublicKeyFile string) (error) {
-	// Read public key with 3 values
-	params, _ := helpers.ReadBigIntsFromFile(PublicKeyFile, 3)
-	p, g, beta := params[0], params[1], params[2]
+	// Read public key (PEM, JSON or legacy numbers)
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	p, g, beta := pub.P, pub.G, pub.Beta
 
 	// Read raw content from plain.txt
 	content, err := os.ReadFile(PlainFile)
@@ -161,14 +451,17 @@ func EncryptElgamal(PlainFile, PublicKeyFile string) (error) {
 	c2.Mod(c2, p)
 
 	//Save cryptogram as 2 values to file
-	helpers.WriteBigIntsToFile(CryptoFile, []*big.Int{c1, c2})
+	helpers.WriteBigIntsToFile(CipherPath, []*big.Int{c1, c2})
 	return  nil
 }
 
 func DecryptElgamal(CryptoFile, PrivateKeyFile string) (error) {
 	//Read params from private key file
-	params, _ := helpers.ReadBigIntsFromFile(PrivateKeyFile, 3)
-	p, _, b := params[0], params[1], params[2]
+	priv, err := readPrivateKey(PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	p, b := priv.P, priv.B
 
 	//Read cryptogram (c1,c2)
 	cipher, _ := helpers.ReadBigIntsFromFile(CryptoFile, 2)
@@ -216,6 +509,11 @@ func DecryptElgamal(CryptoFile, PrivateKeyFile string) (error) {
 	}
 	
 	// -------- Compare to plain.txt --------
+	// Results of -mul or -rerand (given with -ct) have no plaintext file to compare with
+	if !ComparePlain {
+		fmt.Println(output)
+		return nil
+	}
 	plainRaw, err := os.ReadFile(PlainFile)
 	if err != nil {
 		return fmt.Errorf("failed to read original plaintext: %v", err)
@@ -246,35 +544,86 @@ func DecryptElgamal(CryptoFile, PrivateKeyFile string) (error) {
 }
 
 // Signing part------------------------------------------------------------------------------------------
+// messageRepresentative hashes the document and reduces the digest modulo p-1.
+func messageRepresentative(MessageFile string, h crypto.Hash, p *big.Int) (*big.Int, error) {
+	digest, err := helpers.HashFile(MessageFile, h)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %v", MessageFile, err)
+	}
+	log.Printf("[INFO] %s(%s) = %s", h.String(), MessageFile, hex.EncodeToString(digest))
+
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	m := new(big.Int).SetBytes(digest)
+	return m.Mod(m, pm1), nil
+}
+
+// SignMsg signs the content of MessageFile: m = H(document) mod (p-1).
+// The signature (r, x) is saved together with the algorithm and hash name.
 func SignMsg(MessageFile, PrivateKeyFile string) error {
 	// Read p,g,b from private key file
-	params, _ := helpers.ReadBigIntsFromFile(PrivateKeyFile, 3)
-	p, g, b := params[0], params[1], params[2]
+	priv, err := readPrivateKey(PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	p, g, b := priv.P, priv.G, priv.B
 	pm1 := new(big.Int).Sub(p, big.NewInt(1))// p-1
 
-	// Convert sample string to Big Int
-	helpers.CreateShortcutSHA(MessageString, MessageFile)
-	//Read message to signing
-	messages, _ := helpers.ReadBigIntsFromFile(MessageFile, 1)
-	m := messages[0]
+	h, err := helpers.ParseHash(SignHash)
+	if err != nil {
+		return err
+	}
+	// Hash the document to sign
+	m, err := messageRepresentative(MessageFile, h, p)
+	if err != nil {
+		return err
+	}
 
 	//k is random, where 1 ≤ k < p-1, and gcd(k, p-1) = 1 (IsCoprime)
-	var k, kInv *big.Int
-	for {
-		kCandidate, err := helpers.RandomBigInt(pm1) // 0 <= k < p-1
-		if err != nil {
-			return fmt.Errorf("failed to generate random k: %v", err)
-		}
-		kCandidate.Add(kCandidate, big.NewInt(1)) // 1 <= k < p
-		if helpers.IsCoprime(kCandidate, pm1) {
-			k = kCandidate
-			break
+	var k *big.Int
+	if FixedK != nil {
+		// Only for the nonce-reuse demonstration (-fixk)
+		k = new(big.Int).Set(FixedK)
+		log.Printf("[WARN] Signing with fixed k = %s; two such signatures reveal the private key", k)
+	} else {
+		for {
+			kCandidate, err := helpers.RandomBigInt(pm1) // 0 <= k < p-1
+			if err != nil {
+				return fmt.Errorf("failed to generate random k: %v", err)
+			}
+			kCandidate.Add(kCandidate, big.NewInt(1)) // 1 <= k < p
+			if helpers.IsCoprime(kCandidate, pm1) {
+				k = kCandidate
+				break
+			}
 		}
 	}
+
+	r, x, err := elgamalSign(p, g, b, m, k)
+	if err != nil {
+		return err
+	}
+
+	// Save signature with its metadata
+	meta := map[string]string{"algorithm": "ElGamal", "hash": h.String()}
+	err = helpers.WriteRecord(SignaturePath, []string{"algorithm", "hash"}, meta, []*big.Int{r, x})
+	if err != nil {
+		return fmt.Errorf("failed to save signature: %v", err)
+	}
+
+	log.Printf("[INFO] Signed %s and saved signature (r, x) to %s", MessageFile, SignaturePath)
+	return nil
+}
+
+// elgamalSign computes the ElGamal signature (r, x) of the representative m with nonce k.
+func elgamalSign(p, g, b, m, k *big.Int) (*big.Int, *big.Int, error) {
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	if k.Sign() <= 0 || k.Cmp(pm1) >= 0 {
+		return nil, nil, fmt.Errorf("k must satisfy 1 <= k < p-1")
+	}
 	//k_inv = inversed k mod (p−1)
 	kInv, err := helpers.ModInverse(k, pm1)
 	if err != nil || kInv == nil {
-		return fmt.Errorf("failed to compute modular inverse of k")
+		return nil, nil, fmt.Errorf("failed to compute modular inverse of k: gcd(k, p-1) != 1")
 	}
 
 	// Calculate signature (r,x)
@@ -285,43 +634,62 @@ func SignMsg(MessageFile, PrivateKeyFile string) error {
 	x.Sub(m, x)
 	x.Mul(x, kInv)
 	x.Mod(x, pm1)
+	return r, x, nil
+}
 
-	// Save signature
-	err = helpers.WriteBigIntsToFile(SignatureFile, []*big.Int{r, x})
+// VerifySignature re-hashes the document with the hash named in the signature file and checks
+// g^m ≡ r^x · β^r (mod p). An invalid signature writes "N" and returns an error.
+func VerifySignature(MessageFile, PublicKeyFile, SignatureFile string) error {
+	//Read p,g,beta from public key
+	pub, err := readPublicKey(PublicKeyFile)
 	if err != nil {
-		return fmt.Errorf("failed to save signature: %v", err)
+		return err
 	}
+	p, g, beta := pub.P, pub.G, pub.Beta
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
 
-	log.Printf("[INFO] Signed message and saved signature (r, x) to %s", SignatureFile)
-	return nil
-}
+	// Read signature with metadata
+	sig, err := helpers.ReadRecord(SignatureFile)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %v", err)
+	}
+	if alg := sig.Meta["algorithm"]; alg != "ElGamal" {
+		return fmt.Errorf("%s is not an ElGamal signature (algorithm %q)", SignatureFile, alg)
+	}
+	if len(sig.Values) != 2 {
+		return fmt.Errorf("expected 2 numbers (r, x) in %s, got %d", SignatureFile, len(sig.Values))
+	}
+	r, x := sig.Values[0], sig.Values[1]
+	h, err := sig.Hash()
+	if err != nil {
+		return err
+	}
 
-func VerifySignature(MessageFile, PublicKeyFile, SignatureFile string) error {
-	//Read p,g,beta from public key
-	params, _ := helpers.ReadBigIntsFromFile(PublicKeyFile, 3)
-	p, g, beta := params[0], params[1], params[2]
-
-	// Read oryginal message
-	messages, _ := helpers.ReadBigIntsFromFile(MessageFile, 1)
-	m := messages[0]
-	// Read signature 
-	sig, _ := helpers.ReadBigIntsFromFile(SignatureFile, 2)
-	r, x := sig[0], sig[1]
-	
-	//g^m  ≡ r^x · β^r mod p
-	left := new(big.Int).Exp(g, m, p)
-	right1 := new(big.Int).Exp(r, x, p)
-	right2 := new(big.Int).Exp(beta, r, p)
-	right := new(big.Int).Mul(right1, right2)
-	right.Mod(right, p)
+	// Re-hash the original document
+	m, err := messageRepresentative(MessageFile, h, p)
+	if err != nil {
+		return err
+	}
 
-	result := "N"
-	if left.Cmp(right) == 0 {
-		result = "T"
+	valid := true
+	reason := ""
+	// 0 < r < p and 0 <= x < p-1, otherwise forgeries are possible
+	if r.Sign() <= 0 || r.Cmp(p) >= 0 {
+		valid, reason = false, "r is not in the range 0 < r < p"
+	} else if x.Sign() < 0 || x.Cmp(pm1) >= 0 {
+		valid, reason = false, "x is not in the range 0 <= x < p-1"
+	} else {
+		//g^m  ≡ r^x · β^r mod p
+		left := new(big.Int).Exp(g, m, p)
+		right1 := new(big.Int).Exp(r, x, p)
+		right2 := new(big.Int).Exp(beta, r, p)
+		right := new(big.Int).Mul(right1, right2)
+		right.Mod(right, p)
+		if left.Cmp(right) != 0 {
+			valid, reason = false, "g^m != r^x · beta^r (mod p)"
+		}
 	}
 
 	// Save result
-	os.WriteFile(VerifyFile, []byte(result), 0644)
-	fmt.Println(result)
-	return nil
+	return writeVerifyResult(valid, reason)
 }
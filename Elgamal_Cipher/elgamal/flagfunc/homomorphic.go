@@ -0,0 +1,344 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"elgamal/helpers"
+)
+
+const (
+	Crypto2File      = "files/crypto2.txt"
+	ProductFile      = "files/crypto_mul.txt"
+	RerandomizedFile = "files/crypto_rerand.txt"
+	SumFile          = "files/crypto_sum.txt"
+	VotesFile        = "files/votes.txt"
+	VoteReportFile   = "files/vote_report.txt"
+)
+
+var (
+	// PlainPath is the plaintext of -e and -exp-e (set from the -plain flag)
+	PlainPath = PlainFile
+	// CipherPath is the ciphertext written by -e/-exp-e and read by -d/-exp-d/-rerand (set from the -ct flag)
+	CipherPath = CryptoFile
+	// Cipher2Path is the second ciphertext of -mul and -exp-add (set from the -ct2 flag)
+	Cipher2Path = Crypto2File
+	// OutPath overrides the result file of -mul, -exp-add and -rerand (set from the -out flag)
+	OutPath = ""
+	// ComparePlain makes -d check the result against plain.txt (only for the default crypto.txt)
+	ComparePlain = true
+	// MaxExpMessage bounds the discrete-log search of -exp-d (set from the -maxm flag)
+	MaxExpMessage int64 = 1000000
+)
+
+// Homomorphic part------------------------------------------------------------------------------------
+// (c1, c2) = (gᵏ, m·βᵏ). Multiplying two ciphertexts component-wise gives
+// (gᵏ¹⁺ᵏ², m1·m2·βᵏ¹⁺ᵏ²), a valid encryption of m1·m2. Exponential ElGamal encrypts gᵐ
+// instead of m, so the same product encrypts gᵐ¹⁺ᵐ² and the scheme becomes additive.
+
+// Ciphertext is an ElGamal pair (c1, c2).
+type Ciphertext struct {
+	C1, C2 *big.Int
+}
+
+// randomExponent returns k with 1 <= k < p-1.
+func randomExponent(p *big.Int) (*big.Int, error) {
+	k, err := helpers.RandomBigInt(new(big.Int).Sub(p, big.NewInt(2)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate random k: %v", err)
+	}
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+// elgamalEncrypt returns (gᵏ, m·βᵏ) for a random k.
+func elgamalEncrypt(p, g, beta, m *big.Int) (Ciphertext, error) {
+	k, err := randomExponent(p)
+	if err != nil {
+		return Ciphertext{}, err
+	}
+	c1 := new(big.Int).Exp(g, k, p)
+	c2 := new(big.Int).Exp(beta, k, p)
+	c2.Mul(c2, m)
+	c2.Mod(c2, p)
+	return Ciphertext{c1, c2}, nil
+}
+
+// elgamalDecrypt returns c2 · (c1ᵇ)⁻¹ mod p.
+func elgamalDecrypt(p, b *big.Int, ct Ciphertext) (*big.Int, error) {
+	s := new(big.Int).Exp(ct.C1, b, p)
+	sInv, err := helpers.ModInverse(s, p)
+	if err != nil {
+		return nil, err
+	}
+	m := new(big.Int).Mul(ct.C2, sInv)
+	return m.Mod(m, p), nil
+}
+
+// MulCiphertexts returns (c1·c1', c2·c2') mod p, an encryption of m·m'.
+func MulCiphertexts(p *big.Int, a, b Ciphertext) Ciphertext {
+	c1 := new(big.Int).Mul(a.C1, b.C1)
+	c2 := new(big.Int).Mul(a.C2, b.C2)
+	return Ciphertext{c1.Mod(c1, p), c2.Mod(c2, p)}
+}
+
+// Rerandomize multiplies by a fresh encryption of 1: (c1·gʳ, c2·βʳ). The plaintext stays the same,
+// but the new ciphertext cannot be linked to the old one without the private key.
+func Rerandomize(p, g, beta *big.Int, ct Ciphertext) (Ciphertext, error) {
+	one, err := elgamalEncrypt(p, g, beta, big.NewInt(1))
+	if err != nil {
+		return Ciphertext{}, err
+	}
+	return MulCiphertexts(p, ct, one), nil
+}
+
+// ExpEncrypt encrypts gᵐ (exponential ElGamal).
+func ExpEncrypt(p, g, beta *big.Int, m int64) (Ciphertext, error) {
+	if m < 0 {
+		return Ciphertext{}, fmt.Errorf("exponential ElGamal needs m >= 0")
+	}
+	return elgamalEncrypt(p, g, beta, new(big.Int).Exp(g, big.NewInt(m), p))
+}
+
+// ExpDecrypt decrypts to gᵐ and finds m in [0, max] by trying g⁰, g¹, g², ...
+// This is only feasible because the messages (counters, votes) are small.
+func ExpDecrypt(p, g, b *big.Int, ct Ciphertext, max int64) (int64, error) {
+	gm, err := elgamalDecrypt(p, b, ct)
+	if err != nil {
+		return 0, err
+	}
+	power := big.NewInt(1)
+	for m := int64(0); m <= max; m++ {
+		if power.Cmp(gm) == 0 {
+			return m, nil
+		}
+		power.Mul(power, g)
+		power.Mod(power, p)
+	}
+	return 0, fmt.Errorf("no m in [0, %d] with g^m = %s", max, gm)
+}
+
+func readCiphertext(path string) (Ciphertext, error) {
+	values, err := helpers.ReadBigIntsFromFile(path, 2)
+	if err != nil {
+		return Ciphertext{}, fmt.Errorf("failed to read ciphertext %s: %v", path, err)
+	}
+	return Ciphertext{values[0], values[1]}, nil
+}
+
+func writeCiphertext(path string, ct Ciphertext) error {
+	return helpers.WriteBigIntsToFile(path, []*big.Int{ct.C1, ct.C2})
+}
+
+func outputPath(def string) string {
+	if OutPath != "" {
+		return OutPath
+	}
+	return def
+}
+
+// MultiplyCiphertexts writes the encryption of m1·m2 computed from two ciphertexts only.
+func MultiplyCiphertexts(PublicKeyFile, first, second string) error {
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	a, err := readCiphertext(first)
+	if err != nil {
+		return err
+	}
+	b, err := readCiphertext(second)
+	if err != nil {
+		return err
+	}
+	out := outputPath(ProductFile)
+	if err := writeCiphertext(out, MulCiphertexts(pub.P, a, b)); err != nil {
+		return err
+	}
+	log.Printf("[INFO] Product of %s and %s saved to %s", first, second, out)
+	return nil
+}
+
+// RerandomizeCiphertext writes a fresh-looking ciphertext of the same plaintext.
+func RerandomizeCiphertext(PublicKeyFile, path string) error {
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	ct, err := readCiphertext(path)
+	if err != nil {
+		return err
+	}
+	fresh, err := Rerandomize(pub.P, pub.G, pub.Beta, ct)
+	if err != nil {
+		return err
+	}
+	out := outputPath(RerandomizedFile)
+	if err := writeCiphertext(out, fresh); err != nil {
+		return err
+	}
+	log.Printf("[INFO] Re-randomized %s saved to %s", path, out)
+	return nil
+}
+
+// EncryptExp encrypts the small number in PlainFile with exponential ElGamal.
+func EncryptExp(PlainFile, PublicKeyFile string) error {
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(PlainFile)
+	if err != nil {
+		return err
+	}
+	m, ok := new(big.Int).SetString(strings.TrimSpace(string(content)), 10)
+	if !ok || !m.IsInt64() || m.Sign() < 0 || m.Int64() > MaxExpMessage {
+		return fmt.Errorf("exponential ElGamal needs a number 0 <= m <= %d in %s", MaxExpMessage, PlainFile)
+	}
+	ct, err := ExpEncrypt(pub.P, pub.G, pub.Beta, m.Int64())
+	if err != nil {
+		return err
+	}
+	if err := writeCiphertext(CipherPath, ct); err != nil {
+		return err
+	}
+	log.Printf("[INFO] g^m encrypted into %s", CipherPath)
+	return nil
+}
+
+// AddExpCiphertexts writes the encryption of m1 + m2 (the product of two exponential ciphertexts).
+func AddExpCiphertexts(PublicKeyFile, first, second string) error {
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	a, err := readCiphertext(first)
+	if err != nil {
+		return err
+	}
+	b, err := readCiphertext(second)
+	if err != nil {
+		return err
+	}
+	out := outputPath(SumFile)
+	if err := writeCiphertext(out, MulCiphertexts(pub.P, a, b)); err != nil {
+		return err
+	}
+	log.Printf("[INFO] Encrypted sum of %s and %s saved to %s", first, second, out)
+	return nil
+}
+
+// DecryptExp decrypts an exponential ciphertext and writes m to DecryptedFile.
+func DecryptExp(path, PrivateKeyFile string) error {
+	priv, err := readPrivateKey(PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	ct, err := readCiphertext(path)
+	if err != nil {
+		return err
+	}
+	m, err := ExpDecrypt(priv.P, priv.G, priv.B, ct, MaxExpMessage)
+	if err != nil {
+		return err
+	}
+	fmt.Println(m)
+	if err := os.WriteFile(DecryptedFile, []byte(fmt.Sprintf("%d\n", m)), 0644); err != nil {
+		return fmt.Errorf("failed to write decrypted message: %v", err)
+	}
+	log.Printf("[INFO] Exponential ElGamal: m = %d", m)
+	return nil
+}
+
+// Voting demo----------------------------------------------------------------------------------------
+
+// readVotes reads one vote per line: yes/tak/1 or no/nie/0; empty lines and # comments are skipped.
+func readVotes(path string) ([]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var votes []int64
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		v := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if v == "" || strings.HasPrefix(v, "#") {
+			continue
+		}
+		switch v {
+		case "yes", "tak", "1":
+			votes = append(votes, 1)
+		case "no", "nie", "0":
+			votes = append(votes, 0)
+		default:
+			return nil, fmt.Errorf("%s line %d: %q is not a yes/no vote", path, line, v)
+		}
+	}
+	return votes, scanner.Err()
+}
+
+// Vote encrypts every ballot with exponential ElGamal, multiplies all ballots into one tally
+// and decrypts only that tally, so no single vote is ever decrypted.
+func Vote(VotesFile, PublicKeyFile, PrivateKeyFile string) error {
+	votes, err := readVotes(VotesFile)
+	if err != nil {
+		return err
+	}
+	if len(votes) == 0 {
+		return fmt.Errorf("no votes in %s", VotesFile)
+	}
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	p := pub.P
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Encrypted voting with exponential ElGamal (%d ballots)\n", len(votes))
+	fmt.Fprintf(&sb, "ballot = (g^k, g^v · beta^k), v = 1 for yes, 0 for no\n\n")
+
+	// Voters: each ballot is encrypted with the public key only
+	tally := Ciphertext{big.NewInt(1), big.NewInt(1)} // encryption of g^0 with k = 0
+	for i, v := range votes {
+		ballot, err := ExpEncrypt(p, pub.G, pub.Beta, v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "ballot %3d: c1 = %s, c2 = %s\n", i+1, shorten(ballot.C1), shorten(ballot.C2))
+		tally = MulCiphertexts(p, tally, ballot)
+	}
+	fmt.Fprintf(&sb, "\ntally = product of all ballots: c1 = %s, c2 = %s\n", shorten(tally.C1), shorten(tally.C2))
+
+	// Election authority: decrypts the tally only
+	priv, err := readPrivateKey(PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	yes, err := ExpDecrypt(p, priv.G, priv.B, tally, int64(len(votes)))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&sb, "decrypted tally: g^%d, yes = %d, no = %d\n", yes, yes, int64(len(votes))-yes)
+	fmt.Print(sb.String())
+
+	if err := os.WriteFile(VoteReportFile, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to save report: %v", err)
+	}
+	log.Printf("[INFO] %d ballots counted: yes = %d, no = %d", len(votes), yes, int64(len(votes))-yes)
+	return nil
+}
+
+// shorten prints long numbers as their first and last digits.
+func shorten(n *big.Int) string {
+	s := n.String()
+	if len(s) <= 24 {
+		return s
+	}
+	return s[:10] + "..." + s[len(s)-10:]
+}
@@ -0,0 +1,90 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestMulCiphertexts checks E(a)·E(b) = E(a·b mod p) in the group p = 467, g = 2, b = 127.
+func TestMulCiphertexts(t *testing.T) {
+	p, g, b := big.NewInt(467), big.NewInt(2), big.NewInt(127)
+	beta := new(big.Int).Exp(g, b, p)
+	tests := []struct{ x, y, want int64 }{
+		{5, 7, 35},
+		{1, 466, 466},
+		{100, 100, 10000 % 467},
+		{466, 466, 1},
+	}
+	for _, tt := range tests {
+		cx, err := elgamalEncrypt(p, g, beta, big.NewInt(tt.x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cy, err := elgamalEncrypt(p, g, beta, big.NewInt(tt.y))
+		if err != nil {
+			t.Fatal(err)
+		}
+		prod, err := elgamalDecrypt(p, b, MulCiphertexts(p, cx, cy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prod.Int64() != tt.want {
+			t.Errorf("E(%d)·E(%d) decrypts to %s, expected %d", tt.x, tt.y, prod, tt.want)
+		}
+	}
+}
+
+// TestRerandomize checks that a re-randomized ciphertext has a new c1 and the same message.
+func TestRerandomize(t *testing.T) {
+	p, g, b := big.NewInt(467), big.NewInt(2), big.NewInt(127)
+	beta := new(big.Int).Exp(g, b, p)
+	for _, m := range []int64{1, 5, 466} {
+		ct, err := elgamalEncrypt(p, g, beta, big.NewInt(m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fresh, err := Rerandomize(p, g, beta, ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		same, err := elgamalDecrypt(p, b, fresh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if same.Int64() != m {
+			t.Errorf("re-randomized E(%d) decrypts to %s", m, same)
+		}
+		if fresh.C1.Cmp(ct.C1) == 0 {
+			t.Errorf("re-randomization of E(%d) kept c1 = %s", m, ct.C1)
+		}
+	}
+}
+
+// TestExpElGamal adds small numbers under exponential ElGamal: E(x)·E(y) decrypts to x + y.
+func TestExpElGamal(t *testing.T) {
+	p, g, b := big.NewInt(467), big.NewInt(2), big.NewInt(127)
+	beta := new(big.Int).Exp(g, b, p)
+	tests := []struct{ x, y, want int64 }{
+		{3, 4, 7},
+		{0, 0, 0},
+		{50, 49, 99},
+	}
+	for _, tt := range tests {
+		ex, err := ExpEncrypt(p, g, beta, tt.x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ey, err := ExpEncrypt(p, g, beta, tt.y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum, err := ExpDecrypt(p, g, b, MulCiphertexts(p, ex, ey), 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum != tt.want {
+			t.Errorf("E(%d)·E(%d) decrypts to %d, expected %d", tt.x, tt.y, sum, tt.want)
+		}
+	}
+}
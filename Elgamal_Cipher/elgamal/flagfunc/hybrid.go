@@ -0,0 +1,169 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"elgamal/helpers"
+)
+
+const (
+	HybridFile = "files/crypto_hybrid.txt"
+
+	// hybridHeader is the first line of the container and part of the authenticated data
+	hybridHeader = "ELGAMAL-HYBRID-V1"
+	hybridInfo   = "elgamal hybrid aes-256-gcm v1"
+)
+
+// Hybrid part------------------------------------------------------------------------------------------
+// The shared secret s = βᵏ = c1ᵇ mod p is agreed with ElGamal (Diffie-Hellman) and only
+// used to derive an AES-256-GCM key with HKDF, so messages of any length can be encrypted.
+
+// hybridKey derives the AES key from the shared secret s, bound to c1.
+func hybridKey(s, c1, p *big.Int) ([]byte, error) {
+	size := (p.BitLen() + 7) / 8
+	return helpers.HKDF(helpers.FixedBytes(s.Bytes(), size), helpers.FixedBytes(c1.Bytes(), size), []byte(hybridInfo), 32)
+}
+
+// hybridAAD authenticates the container header and c1 together with the ciphertext.
+func hybridAAD(c1 *big.Int) []byte {
+	return []byte(hybridHeader + "\n" + c1.String())
+}
+
+// EncryptHybrid encrypts the whole PlainFile (any size) and writes the container
+// (header, c1, nonce, ciphertext with tag) to HybridFile.
+func EncryptHybrid(PlainFile, PublicKeyFile string) error {
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	p, g, beta := pub.P, pub.G, pub.Beta
+
+	plain, err := os.ReadFile(PlainFile)
+	if err != nil {
+		return err
+	}
+
+	// k random, 1 <= k < p-1
+	k, err := helpers.RandomBigInt(new(big.Int).Sub(p, big.NewInt(2)))
+	if err != nil {
+		return fmt.Errorf("failed to generate random k: %v", err)
+	}
+	k.Add(k, big.NewInt(1))
+
+	// c1 = gᵏ mod p, s = βᵏ mod p
+	c1 := new(big.Int).Exp(g, k, p)
+	s := new(big.Int).Exp(beta, k, p)
+
+	key, err := hybridKey(s, c1, p)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	sealed := gcm.Seal(nil, nonce, plain, hybridAAD(c1))
+
+	container := strings.Join([]string{
+		hybridHeader,
+		c1.String(),
+		base64.StdEncoding.EncodeToString(nonce),
+		base64.StdEncoding.EncodeToString(sealed),
+	}, "\n") + "\n"
+	if err := os.WriteFile(HybridFile, []byte(container), 0644); err != nil {
+		return fmt.Errorf("failed to write container: %v", err)
+	}
+
+	log.Printf("[INFO] Encrypted %d bytes with AES-256-GCM, c1 = %s", len(plain), c1.String())
+	return nil
+}
+
+// DecryptHybrid reads the container, recomputes the AES key from c1ᵇ and writes the
+// plaintext to DecryptedFile only after the GCM tag has been verified.
+func DecryptHybrid(HybridFile, PrivateKeyFile string) error {
+	priv, err := readPrivateKey(PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	p, b := priv.P, priv.B
+
+	c1, nonce, sealed, err := readHybridContainer(HybridFile)
+	if err != nil {
+		return err
+	}
+	if c1.Sign() <= 0 || c1.Cmp(p) >= 0 {
+		return fmt.Errorf("invalid container: c1 is not in [1, p-1]")
+	}
+
+	// s = c1ᵇ mod p
+	s := new(big.Int).Exp(c1, b, p)
+	key, err := hybridKey(s, c1, p)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return fmt.Errorf("invalid container: nonce has %d bytes, expected %d", len(nonce), gcm.NonceSize())
+	}
+
+	plain, err := gcm.Open(nil, nonce, sealed, hybridAAD(c1))
+	if err != nil {
+		fmt.Println("Authentication failed: wrong key or modified container")
+		return fmt.Errorf("authentication tag verification failed: %v", err)
+	}
+	log.Println("[INFO] Authentication tag verified")
+
+	if err := os.WriteFile(DecryptedFile, plain, 0644); err != nil {
+		return fmt.Errorf("failed to write decrypted message: %v", err)
+	}
+	log.Printf("[INFO] Decrypted %d bytes", len(plain))
+	return nil
+}
+
+// readHybridContainer parses the four lines of the container file.
+func readHybridContainer(path string) (*big.Int, []byte, []byte, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(lines) != 4 || lines[0] != hybridHeader {
+		return nil, nil, nil, fmt.Errorf("%s is not a %s container", path, hybridHeader)
+	}
+	c1, ok := new(big.Int).SetString(lines[1], 10)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("invalid c1 in %s", path)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid nonce in %s: %v", path, err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid ciphertext in %s: %v", path, err)
+	}
+	return c1, nonce, sealed, nil
+}
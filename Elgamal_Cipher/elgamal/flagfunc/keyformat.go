@@ -0,0 +1,96 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"elgamal/helpers"
+)
+
+// PassphraseEnv is read when -pass is not given, so the passphrase does not appear in the shell history.
+const PassphraseEnv = "ELGAMAL_PASSPHRASE"
+
+var (
+	// KeyFormat is the format of new key files: pem, json or legacy (set from the -keyfmt flag)
+	KeyFormat = helpers.FormatPEM
+	// Passphrase protects the private key file; empty means not encrypted (set from -pass or ELGAMAL_PASSPHRASE)
+	Passphrase = ""
+)
+
+// readPublicKey reads the public key in any format; a private key file also provides the public part.
+func readPublicKey(path string) (*helpers.ElGamalKey, error) {
+	key, format, err := helpers.ReadKeyFile(path, false, Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %v", err)
+	}
+	if format == helpers.FormatLegacy {
+		log.Printf("[WARN] %s uses the legacy number format, run -convert to upgrade it", path)
+	}
+	return key.Public(), nil
+}
+
+// readPrivateKey reads the private key in any format, decrypting it with Passphrase when needed.
+func readPrivateKey(path string) (*helpers.ElGamalKey, error) {
+	key, format, err := helpers.ReadKeyFile(path, true, Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %v", err)
+	}
+	if format == helpers.FormatLegacy {
+		log.Printf("[WARN] %s uses the legacy number format, run -convert to upgrade it", path)
+	}
+	return key, nil
+}
+
+// ConvertKeys imports the key pair (legacy numbers, PEM or JSON) and writes it again in KeyFormat,
+// encrypting the private key when a passphrase is set.
+func ConvertKeys(PrivateKeyFile, PublicKeyFile string) error {
+	key, from, err := helpers.ReadKeyFile(PrivateKeyFile, true, Passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to read private key: %v", err)
+	}
+
+	// A separate public key file must belong to the same pair
+	if pub, _, err := helpers.ReadKeyFile(PublicKeyFile, false, ""); err == nil && pub.Fingerprint() != key.Fingerprint() {
+		return fmt.Errorf("%s and %s are not one key pair (fingerprints %s, %s)",
+			PrivateKeyFile, PublicKeyFile, key.Fingerprint(), pub.Fingerprint())
+	}
+
+	if err := helpers.WriteKeyFile(PrivateKeyFile, key, KeyFormat, Passphrase); err != nil {
+		return fmt.Errorf("failed to save private key: %v", err)
+	}
+	if err := helpers.WriteKeyFile(PublicKeyFile, key.Public(), KeyFormat, ""); err != nil {
+		return fmt.Errorf("failed to save public key: %v", err)
+	}
+	encrypted := ""
+	if Passphrase != "" {
+		encrypted = ", private key encrypted"
+	}
+	fmt.Printf("Converted %s -> %s%s\nFingerprint: %s\n", from, KeyFormat, encrypted, key.Fingerprint())
+	log.Printf("[INFO] Keys converted from %s to %s%s", from, KeyFormat, encrypted)
+	return nil
+}
+
+// ShowFingerprint prints the format and fingerprint of the key files that exist.
+func ShowFingerprint(PrivateKeyFile, PublicKeyFile string) error {
+	found := false
+	for _, f := range []struct {
+		path    string
+		private bool
+	}{{PublicKeyFile, false}, {PrivateKeyFile, true}} {
+		if _, err := os.Stat(f.path); err != nil {
+			continue
+		}
+		key, format, err := helpers.ReadKeyFile(f.path, f.private, Passphrase)
+		if err != nil {
+			return err
+		}
+		found = true
+		fmt.Printf("%s (%s, %d-bit p): %s\n", f.path, format, key.P.BitLen(), key.Fingerprint())
+	}
+	if !found {
+		return fmt.Errorf("no key files found")
+	}
+	return nil
+}
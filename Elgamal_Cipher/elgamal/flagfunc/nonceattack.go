@@ -0,0 +1,179 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"elgamal/helpers"
+)
+
+const (
+	Message2File     = "files/message2.txt"
+	Signature2File   = "files/signature2.txt"
+	NonceAttackFile  = "files/nonce_attack.txt"
+	maxListedResults = 20
+)
+
+var (
+	// Document2File and Signature2Path are the second signed document for -nonce-attack (set from -msg2 and -sig2)
+	Document2File  = Message2File
+	Signature2Path = Signature2File
+)
+
+// NonceReuseResult holds the candidates found by the nonce-reuse attack.
+type NonceReuseResult struct {
+	KCandidates []*big.Int // solutions of k·(x1 − x2) ≡ m1 − m2 (mod p−1)
+	K           *big.Int   // candidate confirmed by g^k ≡ r
+	BCandidates []*big.Int // solutions of b·r ≡ m1 − k·x1 (mod p−1)
+	B           *big.Int   // candidate confirmed by g^b ≡ β
+}
+
+// nonceReuseAttack recovers k and b from two ElGamal signatures (r, x1), (r, x2) made with the same k.
+//
+//	x1·k ≡ m1 − b·r,  x2·k ≡ m2 − b·r  (mod p−1)
+//	⇒ (x1 − x2)·k ≡ m1 − m2            (mod p−1)
+//	⇒ b·r ≡ m1 − k·x1                  (mod p−1)
+//
+// When x1 − x2 or r is not invertible mod p−1 every congruence has gcd solutions,
+// so all of them are listed and the right one is confirmed with g^k ≡ r and g^b ≡ β.
+func nonceReuseAttack(p, g, beta, r, m1, x1, m2, x2 *big.Int) (*NonceReuseResult, error) {
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	res := &NonceReuseResult{}
+
+	dx := new(big.Int).Sub(x1, x2)
+	dm := new(big.Int).Sub(m1, m2)
+	if new(big.Int).Mod(dx, pm1).Sign() == 0 {
+		return nil, fmt.Errorf("x1 = x2: the signatures are of the same message representative, nothing to solve")
+	}
+
+	var err error
+	res.KCandidates, err = helpers.SolveLinearCongruence(dx, dm, pm1)
+	if err != nil {
+		return nil, fmt.Errorf("solving for k: %v", err)
+	}
+	if len(res.KCandidates) == 0 {
+		return res, fmt.Errorf("(x1 - x2)·k = m1 - m2 (mod p-1) has no solution; the signatures do not share k")
+	}
+	for _, k := range res.KCandidates {
+		if new(big.Int).Exp(g, k, p).Cmp(r) == 0 {
+			res.K = k
+			break
+		}
+	}
+	if res.K == nil {
+		return res, fmt.Errorf("no candidate k satisfies g^k = r; the signatures do not share k")
+	}
+
+	// b·r ≡ m1 − k·x1 (mod p−1)
+	rhs := new(big.Int).Mul(res.K, x1)
+	rhs.Sub(m1, rhs)
+	res.BCandidates, err = helpers.SolveLinearCongruence(r, rhs, pm1)
+	if err != nil {
+		return res, fmt.Errorf("solving for b: %v", err)
+	}
+	for _, b := range res.BCandidates {
+		if new(big.Int).Exp(g, b, p).Cmp(beta) == 0 {
+			res.B = b
+			break
+		}
+	}
+	if res.B == nil {
+		return res, fmt.Errorf("no candidate b satisfies g^b = beta")
+	}
+	return res, nil
+}
+
+// Text formats the attack result for the report file.
+func (res *NonceReuseResult) Text() string {
+	var sb strings.Builder
+	list := func(name string, values []*big.Int, confirmed *big.Int) {
+		fmt.Fprintf(&sb, "%s candidates: %d\n", name, len(values))
+		for i, v := range values {
+			if i == maxListedResults {
+				fmt.Fprintf(&sb, "  ... %d more\n", len(values)-maxListedResults)
+				break
+			}
+			mark := ""
+			if confirmed != nil && v.Cmp(confirmed) == 0 {
+				mark = "  <- confirmed"
+			}
+			fmt.Fprintf(&sb, "  %s%s\n", v, mark)
+		}
+	}
+	list("k", res.KCandidates, res.K)
+	list("b", res.BCandidates, res.B)
+	if res.B != nil {
+		fmt.Fprintf(&sb, "recovered k = %s\nrecovered b = %s\n", res.K, res.B)
+	}
+	return sb.String()
+}
+
+// NonceReuseAttack reads the public key and two signed documents and recovers the private key
+// when both signatures were made with the same k. The report is saved to NonceAttackFile.
+func NonceReuseAttack(PublicKeyFile, doc1, sig1File, doc2, sig2File string) error {
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	p, g, beta := pub.P, pub.G, pub.Beta
+
+	read := func(doc, sigFile string) (r, x, m *big.Int, err error) {
+		sig, err := helpers.ReadRecord(sigFile)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read signature: %v", err)
+		}
+		if alg := sig.Meta["algorithm"]; alg != "ElGamal" {
+			return nil, nil, nil, fmt.Errorf("%s is not an ElGamal signature (algorithm %q)", sigFile, alg)
+		}
+		if len(sig.Values) != 2 {
+			return nil, nil, nil, fmt.Errorf("expected 2 numbers (r, x) in %s, got %d", sigFile, len(sig.Values))
+		}
+		h, err := sig.Hash()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		m, err = messageRepresentative(doc, h, p)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return sig.Values[0], sig.Values[1], m, nil
+	}
+	r1, x1, m1, err := read(doc1, sig1File)
+	if err != nil {
+		return err
+	}
+	r2, x2, m2, err := read(doc2, sig2File)
+	if err != nil {
+		return err
+	}
+	if r1.Cmp(r2) != 0 {
+		return fmt.Errorf("r differs between %s and %s: the signatures use different k", sig1File, sig2File)
+	}
+	log.Printf("[WARN] Both signatures share r = %s, k was reused", r1)
+
+	res, attackErr := nonceReuseAttack(p, g, beta, r1, m1, x1, m2, x2)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "ElGamal nonce-reuse attack\n")
+	fmt.Fprintf(&sb, "signature 1: %s (%s)\nsignature 2: %s (%s)\n", sig1File, doc1, sig2File, doc2)
+	fmt.Fprintf(&sb, "r  = %s\nm1 = %s\nx1 = %s\nm2 = %s\nx2 = %s\n", r1, m1, x1, m2, x2)
+	if res != nil {
+		sb.WriteString(res.Text())
+	}
+	if attackErr != nil {
+		fmt.Fprintf(&sb, "attack failed: %v\n", attackErr)
+	}
+	fmt.Print(sb.String())
+	if err := os.WriteFile(NonceAttackFile, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to save report: %v", err)
+	}
+	if attackErr != nil {
+		return attackErr
+	}
+	log.Printf("[INFO] Private key b recovered, report saved to %s", NonceAttackFile)
+	return nil
+}
@@ -0,0 +1,36 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestNonceReuseAttack signs m1 = 100 and m2 = 102 with k = 213 in p = 467, g = 2, b = 127.
+// x1 − x2 = −2·k⁻¹ is even, so k has two candidates mod 466 and only g^k = r picks the right one.
+func TestNonceReuseAttack(t *testing.T) {
+	p, g, b, k := big.NewInt(467), big.NewInt(2), big.NewInt(127), big.NewInt(213)
+	beta := new(big.Int).Exp(g, b, p)
+	r, x1, err := elgamalSign(p, g, b, big.NewInt(100), k)
+	if err != nil {
+		t.Fatalf("signing m1: %v", err)
+	}
+	_, x2, err := elgamalSign(p, g, b, big.NewInt(102), k)
+	if err != nil {
+		t.Fatalf("signing m2: %v", err)
+	}
+
+	res, err := nonceReuseAttack(p, g, beta, r, big.NewInt(100), x1, big.NewInt(102), x2)
+	if err != nil {
+		t.Fatalf("attack failed: %v", err)
+	}
+	if len(res.KCandidates) != 2 {
+		t.Errorf("%d candidates for k, expected 2", len(res.KCandidates))
+	}
+	if res.K.Cmp(k) != 0 {
+		t.Errorf("k = %s, expected 213", res.K)
+	}
+	if res.B.Cmp(b) != 0 {
+		t.Errorf("b = %s, expected 127", res.B)
+	}
+}
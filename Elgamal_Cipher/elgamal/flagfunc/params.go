@@ -0,0 +1,176 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"elgamal/helpers"
+)
+
+const (
+	// MinPrimeBits is the smallest accepted size of p
+	MinPrimeBits = 256
+	// MinSubgroupBits is the smallest accepted prime factor of p-1 that the order of g must contain
+	MinSubgroupBits = 160
+)
+
+var (
+	// ParamBits is the size of p created by -gen-params (set from the -bits flag)
+	ParamBits = 512
+	// ParamQBits is the size of the subgroup order q; 0 means a safe prime p = 2q+1 (set from -qbits)
+	ParamQBits = 0
+	// AllowWeak lets -k continue with parameters that failed validation (for attack demos)
+	AllowWeak = false
+)
+
+// GenerateParams creates p, a generator g and the prime order q of the subgroup generated by g,
+// and saves p, g, q to ElgamalFile. With qbits = 0 p is a safe prime 2q+1; otherwise q has
+// qbits bits and p-1 is a multiple of q (DSA style group, needed for crypto/dsa compatibility).
+func GenerateParams(bits, qbits int, ElgamalFile string) error {
+	var p, q, g *big.Int
+	var err error
+	if qbits == 0 {
+		log.Printf("[INFO] Searching for a %d-bit safe prime", bits)
+		p, q, err = helpers.GenerateSafePrime(bits)
+		if err != nil {
+			return fmt.Errorf("failed to generate safe prime: %v", err)
+		}
+		log.Printf("[INFO] Safe prime p = %s\n", p.String())
+		log.Printf("[INFO] Subgroup order q = (p-1)/2 = %s\n", q.String())
+
+		g, err = helpers.SubgroupGenerator(p, q)
+		if err != nil {
+			return fmt.Errorf("failed to find generator: %v", err)
+		}
+	} else {
+		log.Printf("[INFO] Searching for a %d-bit prime p with a %d-bit prime q dividing p-1", bits, qbits)
+		p, q, g, err = helpers.GenerateDSAGroup(bits, qbits)
+		if err != nil {
+			return fmt.Errorf("failed to generate group: %v", err)
+		}
+		log.Printf("[INFO] Prime p = %s\n", p.String())
+		log.Printf("[INFO] Subgroup order q = %s\n", q.String())
+	}
+	log.Printf("[INFO] Generator g = %s (g^q mod p = 1, g != 1)\n", g.String())
+
+	if bits < MinPrimeBits {
+		fmt.Printf("Warning: %d-bit p is too small for real use (minimum %d bits)\n", bits, MinPrimeBits)
+		log.Printf("[WARN] %d-bit p is below the minimum of %d bits", bits, MinPrimeBits)
+	}
+
+	return helpers.WriteBigIntsToFile(ElgamalFile, []*big.Int{p, g, q})
+}
+
+// ReadGroup reads p, g and the optional subgroup order q from ElgamalFile.
+// q is nil when the file only holds p and g.
+func ReadGroup(ElgamalFile string) (*big.Int, *big.Int, *big.Int, error) {
+	values, err := helpers.ReadAllBigIntsFromFile(ElgamalFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	switch len(values) {
+	case 2:
+		return values[0], values[1], nil, nil
+	case 3:
+		return values[0], values[1], values[2], nil
+	default:
+		return nil, nil, nil, fmt.Errorf("expected p, g and optional q in %s, got %d numbers", ElgamalFile, len(values))
+	}
+}
+
+// SubgroupOrder returns the prime order q of g. It is taken from ElgamalFile when present,
+// otherwise q = (p-1)/2 is used when p is a safe prime. An error explains when neither works.
+func SubgroupOrder(p, g, q *big.Int) (*big.Int, error) {
+	one := big.NewInt(1)
+	if q == nil {
+		q = new(big.Int).Rsh(new(big.Int).Sub(p, one), 1)
+		if !q.ProbablyPrime(helpers.PrimeRounds) {
+			return nil, fmt.Errorf("p is not a safe prime and no q is given; run -gen-params to create a prime-order group")
+		}
+	}
+	if !q.ProbablyPrime(helpers.PrimeRounds) {
+		return nil, fmt.Errorf("q is not prime")
+	}
+	if new(big.Int).Mod(new(big.Int).Sub(p, one), q).Sign() != 0 {
+		return nil, fmt.Errorf("q does not divide p-1")
+	}
+	if g.Cmp(one) == 0 || new(big.Int).Exp(g, q, p).Cmp(one) != 0 {
+		return nil, fmt.Errorf("g does not generate the subgroup of order q (g^q mod p != 1)")
+	}
+	return q, nil
+}
+
+// ValidateParams checks p and g read from elgamal.txt.
+// Structural errors (p not prime, g out of range) are always returned as an error.
+// Weak parameters (small p, smooth p-1, g of small order) are returned as an error
+// unless allowWeak is set, in which case they become warnings.
+// Properties that cannot be proven (p-1 not fully factored) are returned as warnings.
+// When q (the order of g) is known, the subgroup is checked directly.
+func ValidateParams(p, g, q *big.Int, allowWeak bool) ([]string, error) {
+	one := big.NewInt(1)
+	if p.Cmp(big.NewInt(5)) < 0 {
+		return nil, fmt.Errorf("p = %s is too small to be an ElGamal modulus", p.String())
+	}
+	if !p.ProbablyPrime(helpers.PrimeRounds) {
+		return nil, fmt.Errorf("p is not prime: Z*_p is not a cyclic group and decryption/verification can fail")
+	}
+	pm1 := new(big.Int).Sub(p, one)
+	if g.Cmp(big.NewInt(2)) < 0 || g.Cmp(pm1) >= 0 {
+		return nil, fmt.Errorf("g must satisfy 2 <= g <= p-2 (g = 0, 1 or p-1 leaks the message)")
+	}
+
+	var weak, warnings []string
+	if p.BitLen() < MinPrimeBits {
+		weak = append(weak, fmt.Sprintf("p has only %d bits (minimum %d): discrete logarithms mod p are feasible", p.BitLen(), MinPrimeBits))
+	}
+
+	if q != nil {
+		if _, err := SubgroupOrder(p, g, q); err != nil {
+			return nil, fmt.Errorf("invalid subgroup: %v", err)
+		}
+		if q.BitLen() < MinSubgroupBits {
+			weak = append(weak, fmt.Sprintf("the order q of g has only %d bits (minimum %d): baby-step giant-step and Pollard rho are feasible", q.BitLen(), MinSubgroupBits))
+		}
+		return finishValidation(weak, warnings, allowWeak)
+	}
+
+	// p-1 = S·c, where S is the product of the small prime factors and c is the cofactor
+	small, cofactor := helpers.TrialFactor(pm1)
+	smooth := new(big.Int).Div(pm1, cofactor)
+
+	switch {
+	case cofactor.Cmp(one) == 0:
+		weak = append(weak, "p-1 has only prime factors below 2000: Pohlig-Hellman solves discrete logs in every subgroup")
+	case new(big.Int).Exp(g, smooth, p).Cmp(one) == 0:
+		weak = append(weak, fmt.Sprintf("g has small order (a divisor of %s): b can be found by brute force", smooth.String()))
+	case cofactor.ProbablyPrime(helpers.PrimeRounds):
+		if cofactor.BitLen() < MinSubgroupBits {
+			weak = append(weak, fmt.Sprintf("the largest prime factor of p-1 has only %d bits (minimum %d): Pohlig-Hellman is feasible", cofactor.BitLen(), MinSubgroupBits))
+		}
+	case cofactor.BitLen() < MinSubgroupBits:
+		weak = append(weak, fmt.Sprintf("after removing small factors, p-1 leaves a composite %d-bit cofactor (minimum %d-bit prime): p-1 is smooth", cofactor.BitLen(), MinSubgroupBits))
+	default:
+		warnings = append(warnings, fmt.Sprintf("p-1 = %s · c with a composite %d-bit cofactor c that could not be factored; the order of g cannot be proven large (use -gen-params for verified parameters)", joinInts(small), cofactor.BitLen()))
+	}
+
+	return finishValidation(weak, warnings, allowWeak)
+}
+
+// finishValidation turns the weaknesses into an error, or into warnings when allowWeak is set.
+func finishValidation(weak, warnings []string, allowWeak bool) ([]string, error) {
+	if len(weak) > 0 && !allowWeak {
+		return warnings, fmt.Errorf("weak ElGamal parameters:\n  - %s\n(use -gen-params to create safe parameters, or -allow-weak for attack demonstrations)", strings.Join(weak, "\n  - "))
+	}
+	return append(warnings, weak...), nil
+}
+
+func joinInts(values []*big.Int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = v.String()
+	}
+	return strings.Join(parts, "·")
+}
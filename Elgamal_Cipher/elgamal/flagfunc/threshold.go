@@ -0,0 +1,365 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"elgamal/helpers"
+)
+
+const (
+	SharesDir       = "files/shares"
+	CommitmentsFile = "files/shares/commitments.txt"
+	ReconstructFile = "files/private_reconstructed.txt"
+	shareScheme     = "Shamir-Feldman"
+)
+
+var (
+	// Threshold and ShareCount are t and n of -split (set from the -t and -n flags)
+	Threshold  = 3
+	ShareCount = 5
+	// ShareIndex is the shareholder of -partial (set from the -share flag)
+	ShareIndex = 1
+	// ShareList selects the shares/partials used by -combine and -tdecrypt, e.g. "1,3,5"; empty means all
+	ShareList = ""
+)
+
+// Threshold part-------------------------------------------------------------------------------------
+// b is split with Shamir's scheme over Z_q, q = order of g. Shareholder i keeps b_i = f(i) and
+// publishes only c1^(b_i); since c1 = g^k lies in the subgroup of order q,
+// Π (c1^(b_i))^(λ_i) = c1^(Σ λ_i·b_i) = c1^b, so the key is never put together.
+
+func shareFile(i int64) string {
+	return filepath.Join(SharesDir, fmt.Sprintf("share_%d.txt", i))
+}
+
+func partialFile(i int64) string {
+	return filepath.Join(SharesDir, fmt.Sprintf("partial_%d.txt", i))
+}
+
+// groupOrderFor returns q for the key, taken from elgamal.txt when it describes the same group.
+func groupOrderFor(p, g *big.Int) (*big.Int, error) {
+	var q *big.Int
+	if gp, gg, gq, err := ReadGroup(ElgamalFile); err == nil && gp.Cmp(p) == 0 && gg.Cmp(g) == 0 {
+		q = gq
+	}
+	q, err := SubgroupOrder(p, g, q)
+	if err != nil {
+		fmt.Println("Threshold ElGamal needs g of prime order q: run -gen-params, then -k")
+		return nil, fmt.Errorf("threshold ElGamal needs g of prime order q: %v", err)
+	}
+	return q, nil
+}
+
+// Share is one shareholder's file.
+type Share struct {
+	Index     int64
+	Threshold int
+	P, G, Q   *big.Int
+	Value     *big.Int
+}
+
+func readShare(path string) (*Share, error) {
+	f, err := helpers.ReadRecord(path)
+	if err != nil {
+		return nil, err
+	}
+	if f.Meta["scheme"] != shareScheme || len(f.Values) != 4 {
+		return nil, fmt.Errorf("%s is not a %s share", path, shareScheme)
+	}
+	index, err1 := strconv.ParseInt(f.Meta["index"], 10, 64)
+	t, err2 := strconv.Atoi(f.Meta["threshold"])
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("%s: invalid index or threshold", path)
+	}
+	return &Share{Index: index, Threshold: t, P: f.Values[0], G: f.Values[1], Q: f.Values[2], Value: f.Values[3]}, nil
+}
+
+// readCommitments returns t, the group (p, g, q) and the Feldman commitments C_0..C_(t-1).
+func readCommitments(path string) (int, []*big.Int, []*big.Int, error) {
+	f, err := helpers.ReadRecord(path)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	t, err := strconv.Atoi(f.Meta["threshold"])
+	if err != nil || f.Meta["scheme"] != shareScheme || len(f.Values) != 3+t {
+		return 0, nil, nil, fmt.Errorf("%s is not a %s commitment file", path, shareScheme)
+	}
+	return t, f.Values[:3], f.Values[3:], nil
+}
+
+// selectedIndices parses ShareList, or lists every file with the given prefix in SharesDir.
+func selectedIndices(prefix string) ([]int64, error) {
+	var indices []int64
+	if ShareList != "" {
+		for _, field := range strings.Split(ShareList, ",") {
+			i, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil || i <= 0 {
+				return nil, fmt.Errorf("invalid share index %q in -shares", field)
+			}
+			indices = append(indices, i)
+		}
+		return indices, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join(SharesDir, prefix+"_*.txt"))
+	for _, m := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), prefix+"_"), ".txt")
+		if i, err := strconv.ParseInt(name, 10, 64); err == nil {
+			indices = append(indices, i)
+		}
+	}
+	sort.Slice(indices, func(a, b int) bool { return indices[a] < indices[b] })
+	return indices, nil
+}
+
+// SplitKey splits b from the private key into ShareCount shares with threshold Threshold
+// and publishes the Feldman commitments.
+func SplitKey(PrivateKeyFile string) error {
+	priv, err := readPrivateKey(PrivateKeyFile)
+	if err != nil {
+		return err
+	}
+	q, err := groupOrderFor(priv.P, priv.G)
+	if err != nil {
+		return err
+	}
+	shares, coeffs, err := helpers.SplitSecret(priv.B, q, Threshold, ShareCount)
+	if err != nil {
+		return err
+	}
+	commits := helpers.FeldmanCommitments(coeffs, priv.G, priv.P)
+
+	if err := os.MkdirAll(SharesDir, 0755); err != nil {
+		return err
+	}
+	// Old shares of another split must not be mixed with the new ones
+	for _, prefix := range []string{"share", "partial"} {
+		old, err := filepath.Glob(filepath.Join(SharesDir, prefix+"_*.txt"))
+		if err != nil {
+			return err
+		}
+		for _, f := range old {
+			if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove old %s: %v", f, err)
+			}
+		}
+	}
+
+	meta := map[string]string{
+		"scheme":      shareScheme,
+		"threshold":   strconv.Itoa(Threshold),
+		"shares":      strconv.Itoa(ShareCount),
+		"fingerprint": priv.Fingerprint(),
+	}
+	values := append([]*big.Int{priv.P, priv.G, q}, commits...)
+	if err := helpers.WriteRecord(CommitmentsFile, []string{"scheme", "threshold", "shares", "fingerprint"}, meta, values); err != nil {
+		return fmt.Errorf("failed to save commitments: %v", err)
+	}
+	for i, s := range shares {
+		meta["index"] = strconv.Itoa(i + 1)
+		keys := []string{"scheme", "threshold", "shares", "index", "fingerprint"}
+		if err := helpers.WriteRecord(shareFile(int64(i+1)), keys, meta, []*big.Int{priv.P, priv.G, q, s}); err != nil {
+			return fmt.Errorf("failed to save share %d: %v", i+1, err)
+		}
+	}
+
+	fmt.Printf("b split into %d shares, any %d of them decrypt (q has %d bits)\n", ShareCount, Threshold, q.BitLen())
+	fmt.Printf("Shares: %s/share_1..%d.txt, commitments: %s\n", SharesDir, ShareCount, CommitmentsFile)
+	log.Printf("[INFO] Private key split into %d-of-%d Shamir shares", Threshold, ShareCount)
+	return nil
+}
+
+// VerifyShares checks every share against the Feldman commitments and C_0 against β of the public key.
+func VerifyShares(PublicKeyFile string) error {
+	t, group, commits, err := readCommitments(CommitmentsFile)
+	if err != nil {
+		return err
+	}
+	p, g, q := group[0], group[1], group[2]
+	pub, err := readPublicKey(PublicKeyFile)
+	if err != nil {
+		return err
+	}
+	if pub.P.Cmp(p) != 0 || pub.G.Cmp(g) != 0 || pub.Beta.Cmp(commits[0]) != 0 {
+		fmt.Println("C_0 != beta: the commitments do not belong to this public key")
+		return fmt.Errorf("commitments do not match %s", PublicKeyFile)
+	}
+	fmt.Printf("C_0 = beta of %s, threshold t = %d\n", PublicKeyFile, t)
+
+	indices, err := selectedIndices("share")
+	if err != nil {
+		return err
+	}
+	bad := 0
+	for _, i := range indices {
+		share, err := readShare(shareFile(i))
+		if err != nil {
+			return err
+		}
+		ok := share.Index == i && share.P.Cmp(p) == 0 && feldmanOK(share, commits, g, p, q)
+		status := "OK"
+		if !ok {
+			status = "INVALID"
+			bad++
+		}
+		fmt.Printf("share %d: %s\n", i, status)
+	}
+	if bad > 0 {
+		return fmt.Errorf("%d share(s) do not match the commitments", bad)
+	}
+	log.Printf("[INFO] %d shares verified against the Feldman commitments", len(indices))
+	return nil
+}
+
+// feldmanOK checks one share against the commitments.
+func feldmanOK(share *Share, commits []*big.Int, g, p, q *big.Int) bool {
+	return helpers.FeldmanVerify(share.Index, share.Value, commits, g, p, q)
+}
+
+// CombineKey reconstructs b from t shares and saves the private key to ReconstructFile.
+func CombineKey(PublicKeyFile string) error {
+	indices, err := selectedIndices("share")
+	if err != nil {
+		return err
+	}
+	shares := map[int64]*big.Int{}
+	var first *Share
+	for _, i := range indices {
+		share, err := readShare(shareFile(i))
+		if err != nil {
+			return err
+		}
+		if first == nil {
+			first = share
+		}
+		shares[share.Index] = share.Value
+	}
+	if first == nil || len(shares) < first.Threshold {
+		return fmt.Errorf("need at least %d shares, found %d", thresholdOf(first), len(shares))
+	}
+
+	b, err := helpers.CombineShares(shares, first.Q)
+	if err != nil {
+		return err
+	}
+	beta := new(big.Int).Exp(first.G, b, first.P)
+	if pub, err := readPublicKey(PublicKeyFile); err == nil && pub.Beta.Cmp(beta) != 0 {
+		return fmt.Errorf("reconstructed b does not match beta of %s (wrong or damaged share)", PublicKeyFile)
+	}
+	key := &helpers.ElGamalKey{P: first.P, G: first.G, Beta: beta, B: b}
+	if err := helpers.WriteKeyFile(ReconstructFile, key, KeyFormat, Passphrase); err != nil {
+		return fmt.Errorf("failed to save reconstructed key: %v", err)
+	}
+	fmt.Printf("b reconstructed from shares %v, g^b = beta, saved to %s\n", sortedKeys(shares), ReconstructFile)
+	log.Printf("[INFO] Private key reconstructed from %d shares", len(shares))
+	return nil
+}
+
+func thresholdOf(s *Share) int {
+	if s == nil {
+		return Threshold
+	}
+	return s.Threshold
+}
+
+func sortedKeys(m map[int64]*big.Int) []int64 {
+	keys := make([]int64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+	return keys
+}
+
+// PartialDecrypt is run by shareholder ShareIndex: it checks its share and publishes d_i = c1^(b_i) mod p.
+func PartialDecrypt(CipherPath string) error {
+	share, err := readShare(shareFile(int64(ShareIndex)))
+	if err != nil {
+		return err
+	}
+	if _, _, commits, err := readCommitments(CommitmentsFile); err == nil && !feldmanOK(share, commits, share.G, share.P, share.Q) {
+		return fmt.Errorf("share %d does not match the commitments", share.Index)
+	}
+	ct, err := readCiphertext(CipherPath)
+	if err != nil {
+		return err
+	}
+	// c1 must lie in the subgroup of order q, otherwise the exponents cannot be combined mod q
+	if new(big.Int).Exp(ct.C1, share.Q, share.P).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("c1 is not in the subgroup of order q")
+	}
+
+	d := new(big.Int).Exp(ct.C1, share.Value, share.P)
+	meta := map[string]string{"scheme": shareScheme, "index": strconv.FormatInt(share.Index, 10), "ciphertext": CipherPath}
+	if err := helpers.WriteRecord(partialFile(share.Index), []string{"scheme", "index", "ciphertext"}, meta, []*big.Int{ct.C1, d}); err != nil {
+		return fmt.Errorf("failed to save partial decryption: %v", err)
+	}
+	fmt.Printf("partial decryption d_%d = c1^(b_%d) saved to %s\n", share.Index, share.Index, partialFile(share.Index))
+	log.Printf("[INFO] Partial decryption of shareholder %d", share.Index)
+	return nil
+}
+
+// ThresholdDecrypt combines t partial decryptions: c1^b = Π d_i^(λ_i), m = c2 · (c1^b)⁻¹.
+func ThresholdDecrypt(CipherPath string) error {
+	t, group, _, err := readCommitments(CommitmentsFile)
+	if err != nil {
+		return err
+	}
+	p, q := group[0], group[2]
+	ct, err := readCiphertext(CipherPath)
+	if err != nil {
+		return err
+	}
+
+	indices, err := selectedIndices("partial")
+	if err != nil {
+		return err
+	}
+	partials := map[int64]*big.Int{}
+	for _, i := range indices {
+		f, err := helpers.ReadRecord(partialFile(i))
+		if err != nil {
+			return err
+		}
+		if len(f.Values) != 2 || f.Values[0].Cmp(ct.C1) != 0 {
+			return fmt.Errorf("partial %d was computed for another ciphertext", i)
+		}
+		partials[i] = f.Values[1]
+		if len(partials) == t {
+			break // exactly t partials are combined
+		}
+	}
+	if len(partials) < t {
+		return fmt.Errorf("need %d partial decryptions, found %d", t, len(partials))
+	}
+
+	lambdas, err := helpers.LagrangeAtZero(sortedKeys(partials), q)
+	if err != nil {
+		return err
+	}
+	s := big.NewInt(1)
+	for i, d := range partials {
+		s.Mul(s, new(big.Int).Exp(d, lambdas[i], p))
+		s.Mod(s, p)
+	}
+	sInv, err := helpers.ModInverse(s, p)
+	if err != nil {
+		return err
+	}
+	m := new(big.Int).Mul(ct.C2, sInv)
+	m.Mod(m, p)
+
+	fmt.Printf("combined partials %v: m = %s\n", sortedKeys(partials), m)
+	if err := os.WriteFile(DecryptedFile, []byte(m.String()+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write decrypted message: %v", err)
+	}
+	log.Printf("[INFO] Threshold decryption with shareholders %v", sortedKeys(partials))
+	return nil
+}
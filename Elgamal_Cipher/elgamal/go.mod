@@ -1,3 +1,5 @@
 module elgamal
 
 go 1.23.5
+
+require golang.org/x/crypto v0.36.0
@@ -0,0 +1,52 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MaxCongruenceSolutions limits how many solutions SolveLinearCongruence lists.
+const MaxCongruenceSolutions = 1 << 16
+
+// SolveLinearCongruence returns all x in [0, n) with a·x ≡ c (mod n).
+// With d = gcd(a, n) there is no solution when d ∤ c, otherwise there are exactly d:
+// x = x0 + i·(n/d), i = 0..d-1, where x0 = (c/d)·(a/d)⁻¹ mod (n/d).
+func SolveLinearCongruence(a, c, n *big.Int) ([]*big.Int, error) {
+	if n.Sign() <= 0 {
+		return nil, fmt.Errorf("modulus must be positive")
+	}
+	a = new(big.Int).Mod(a, n)
+	c = new(big.Int).Mod(c, n)
+
+	d := new(big.Int).GCD(nil, nil, a, n)
+	if new(big.Int).Mod(c, d).Sign() != 0 {
+		return nil, nil // d does not divide c
+	}
+	if d.Cmp(big.NewInt(MaxCongruenceSolutions)) > 0 {
+		return nil, fmt.Errorf("too many solutions: gcd = %s", d)
+	}
+
+	// step = 1 means a ≡ 0 and c ≡ 0 (mod n): every residue is a solution
+	step := new(big.Int).Div(n, d)
+	ad := new(big.Int).Div(a, d)
+	cd := new(big.Int).Div(c, d)
+	x0 := big.NewInt(0)
+	if step.Cmp(big.NewInt(1)) > 0 {
+		inv, err := ModInverse(ad, step)
+		if err != nil {
+			return nil, err
+		}
+		x0.Mul(cd, inv)
+		x0.Mod(x0, step)
+	}
+
+	count := int(d.Int64())
+	solutions := make([]*big.Int, 0, count)
+	x := new(big.Int).Set(x0)
+	for i := 0; i < count; i++ {
+		solutions = append(solutions, new(big.Int).Set(x))
+		x.Add(x, step)
+	}
+	return solutions, nil
+}
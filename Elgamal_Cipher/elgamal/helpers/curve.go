@@ -0,0 +1,250 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+)
+
+// Textbook elliptic curve arithmetic------------------------------------------------------------------
+// Short Weierstrass curve y² = x³ + a·x + b over F_p, written with math/big so every step
+// of point addition and doubling can be printed. It is NOT constant time; the real
+// operations (-ec-*) use crypto/ecdh, crypto/ecdsa and crypto/ed25519.
+
+// Point is an affine point; the point at infinity O has X == nil.
+type Point struct {
+	X, Y *big.Int
+}
+
+// Infinity is the neutral element O.
+var Infinity = Point{}
+
+// IsInfinity reports whether pt is the point at infinity.
+func (pt Point) IsInfinity() bool { return pt.X == nil }
+
+func (pt Point) String() string {
+	if pt.IsInfinity() {
+		return "O"
+	}
+	return fmt.Sprintf("(%s, %s)", pt.X, pt.Y)
+}
+
+// Equal compares two points.
+func (pt Point) Equal(q Point) bool {
+	if pt.IsInfinity() || q.IsInfinity() {
+		return pt.IsInfinity() == q.IsInfinity()
+	}
+	return pt.X.Cmp(q.X) == 0 && pt.Y.Cmp(q.Y) == 0
+}
+
+// Curve is y² = x³ + A·x + B mod P with base point G of order N.
+type Curve struct {
+	Name    string
+	P, A, B *big.Int
+	G       Point
+	N       *big.Int
+	// Trace receives the formulas of Add and Double when not nil
+	Trace io.Writer
+}
+
+// TextbookCurves are the small curves of the teaching mode; the orders are computed on first use.
+var TextbookCurves = map[string]func() *Curve{
+	// y² = x³ + 2x + 2 mod 17, G = (5, 1) of order 19
+	"toy17": func() *Curve {
+		return &Curve{Name: "toy17", P: big.NewInt(17), A: big.NewInt(2), B: big.NewInt(2),
+			G: Point{big.NewInt(5), big.NewInt(1)}, N: big.NewInt(19)}
+	},
+	// E_751(−1, 188), G = (0, 376): the EC-ElGamal example with one character per point
+	"toy751": func() *Curve {
+		c := &Curve{Name: "toy751", P: big.NewInt(751), A: big.NewInt(-1), B: big.NewInt(188),
+			G: Point{big.NewInt(0), big.NewInt(376)}}
+		c.N = c.PointOrder(c.G)
+		return c
+	},
+}
+
+// P256Curve returns NIST P-256 (a = −3) for the big.Int arithmetic.
+func P256Curve() *Curve {
+	params := elliptic.P256().Params()
+	return &Curve{Name: "P-256", P: params.P, A: big.NewInt(-3), B: params.B,
+		G: Point{params.Gx, params.Gy}, N: params.N}
+}
+
+// LookupCurve returns a textbook curve or P-256 by name.
+func LookupCurve(name string) (*Curve, error) {
+	if name == "P-256" {
+		return P256Curve(), nil
+	}
+	if f, ok := TextbookCurves[name]; ok {
+		return f(), nil
+	}
+	names := make([]string, 0, len(TextbookCurves))
+	for n := range TextbookCurves {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown curve %q (textbook curves: %v, or P-256)", name, names)
+}
+
+func (c *Curve) tracef(format string, args ...interface{}) {
+	if c.Trace != nil {
+		fmt.Fprintf(c.Trace, format, args...)
+	}
+}
+
+func (c *Curve) mod(x *big.Int) *big.Int {
+	return x.Mod(x, c.P)
+}
+
+// rhs returns x³ + a·x + b mod p.
+func (c *Curve) rhs(x *big.Int) *big.Int {
+	r := new(big.Int).Exp(x, big.NewInt(3), c.P)
+	r.Add(r, new(big.Int).Mul(c.A, x))
+	r.Add(r, c.B)
+	return c.mod(r)
+}
+
+// IsOnCurve checks y² ≡ x³ + a·x + b (mod p).
+func (c *Curve) IsOnCurve(pt Point) bool {
+	if pt.IsInfinity() {
+		return true
+	}
+	y2 := new(big.Int).Mul(pt.Y, pt.Y)
+	return c.mod(y2).Cmp(c.rhs(pt.X)) == 0
+}
+
+// Neg returns −P = (x, −y).
+func (c *Curve) Neg(pt Point) Point {
+	if pt.IsInfinity() {
+		return pt
+	}
+	return Point{new(big.Int).Set(pt.X), c.mod(new(big.Int).Neg(pt.Y))}
+}
+
+// Double returns 2P with λ = (3x² + a) / 2y.
+func (c *Curve) Double(pt Point) Point {
+	if pt.IsInfinity() {
+		return pt
+	}
+	if pt.Y.Sign() == 0 {
+		c.tracef("  2·%s = O (vertical tangent)\n", pt)
+		return Infinity
+	}
+	num := new(big.Int).Mul(pt.X, pt.X)
+	num.Mul(num, big.NewInt(3))
+	num.Add(num, c.A)
+	den := new(big.Int).Lsh(pt.Y, 1)
+	lambda := c.mod(num.Mul(num, new(big.Int).ModInverse(c.mod(den), c.P)))
+
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, new(big.Int).Lsh(pt.X, 1))
+	c.mod(x3)
+	y3 := new(big.Int).Sub(pt.X, x3)
+	y3.Mul(y3, lambda)
+	y3.Sub(y3, pt.Y)
+	c.mod(y3)
+	c.tracef("  2·%s: λ = (3x² + a)/(2y) = %s, x3 = λ² − 2x = %s, y3 = λ(x − x3) − y = %s\n", pt, lambda, x3, y3)
+	return Point{x3, y3}
+}
+
+// Add returns P + Q with λ = (y2 − y1) / (x2 − x1); equal points are doubled.
+func (c *Curve) Add(p1, p2 Point) Point {
+	switch {
+	case p1.IsInfinity():
+		return p2
+	case p2.IsInfinity():
+		return p1
+	case p1.X.Cmp(p2.X) == 0:
+		if p1.Y.Cmp(p2.Y) == 0 {
+			return c.Double(p1)
+		}
+		c.tracef("  %s + %s = O (P + (−P))\n", p1, p2)
+		return Infinity
+	}
+	num := new(big.Int).Sub(p2.Y, p1.Y)
+	den := c.mod(new(big.Int).Sub(p2.X, p1.X))
+	lambda := c.mod(num.Mul(num, new(big.Int).ModInverse(den, c.P)))
+
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, p1.X)
+	x3.Sub(x3, p2.X)
+	c.mod(x3)
+	y3 := new(big.Int).Sub(p1.X, x3)
+	y3.Mul(y3, lambda)
+	y3.Sub(y3, p1.Y)
+	c.mod(y3)
+	c.tracef("  %s + %s: λ = (y2 − y1)/(x2 − x1) = %s, x3 = λ² − x1 − x2 = %s, y3 = λ(x1 − x3) − y1 = %s\n", p1, p2, lambda, x3, y3)
+	return Point{x3, y3}
+}
+
+// ScalarMult computes k·P with double-and-add from the most significant bit.
+func (c *Curve) ScalarMult(k *big.Int, pt Point) Point {
+	if k.Sign() < 0 {
+		return c.ScalarMult(new(big.Int).Neg(k), c.Neg(pt))
+	}
+	result := Infinity
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = c.Double(result)
+		if k.Bit(i) == 1 {
+			result = c.Add(result, pt)
+		}
+	}
+	return result
+}
+
+// PointOrder returns the smallest n > 0 with n·P = O by repeated addition (small curves only).
+func (c *Curve) PointOrder(pt Point) *big.Int {
+	if c.P.BitLen() > 24 {
+		return nil
+	}
+	trace := c.Trace
+	c.Trace = nil
+	defer func() { c.Trace = trace }()
+
+	n := int64(1)
+	for q := pt; !q.IsInfinity(); q = c.Add(q, pt) {
+		n++
+	}
+	return big.NewInt(n)
+}
+
+// Koblitz encoding------------------------------------------------------------------------------------
+// A number m is mapped to the point with x = m·K + j for the first j < K for which
+// x³ + a·x + b is a square; decoding is m = ⌊x / K⌋. Each try fails with probability ≈ 1/2.
+
+// KoblitzParams returns the factor K and the bound M: numbers 0 ≤ m < M = ⌊p / K⌋ can be encoded.
+// K = 30 fails with probability ≈ 2⁻³⁰; tiny curves get a smaller K.
+func KoblitzParams(p *big.Int) (K int64, M *big.Int, err error) {
+	K = 30
+	if p.Cmp(big.NewInt(2*K)) < 0 {
+		K = p.Int64() / 2
+	}
+	M = new(big.Int).Div(p, big.NewInt(K))
+	if K < 2 || M.Cmp(big.NewInt(2)) < 0 {
+		return 0, nil, fmt.Errorf("p = %s is too small for Koblitz encoding", p)
+	}
+	return K, M, nil
+}
+
+// KoblitzEncode maps m (0 ≤ m < ⌊p / K⌋) to a point of the curve.
+func (c *Curve) KoblitzEncode(m *big.Int, K int64) (Point, error) {
+	base := new(big.Int).Mul(m, big.NewInt(K))
+	for j := int64(0); j < K; j++ {
+		x := new(big.Int).Add(base, big.NewInt(j))
+		if x.Cmp(c.P) >= 0 {
+			break
+		}
+		if y := new(big.Int).ModSqrt(c.rhs(x), c.P); y != nil {
+			return Point{x, y}, nil
+		}
+	}
+	return Infinity, fmt.Errorf("no point with x in [%s·%d, %s·%d + %d)", m, K, m, K, K)
+}
+
+// KoblitzDecode recovers m = ⌊x / K⌋.
+func KoblitzDecode(pt Point, K int64) *big.Int {
+	return new(big.Int).Div(pt.X, big.NewInt(K))
+}
@@ -0,0 +1,45 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestToyCurve checks the textbook curve y² = x³ + 2x + 2 mod 17 with G = (5, 1) of order 19.
+func TestToyCurve(t *testing.T) {
+	toy, err := LookupCurve("toy17")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g2 := toy.Double(toy.G); !g2.Equal(Point{X: big.NewInt(6), Y: big.NewInt(3)}) {
+		t.Errorf("2G = %s, expected (6, 3)", g2)
+	}
+	if pt := toy.ScalarMult(big.NewInt(19), toy.G); !pt.IsInfinity() {
+		t.Errorf("19·G = %s, expected O", pt)
+	}
+	sum := toy.Add(toy.ScalarMult(big.NewInt(7), toy.G), toy.ScalarMult(big.NewInt(5), toy.G))
+	if want := toy.ScalarMult(big.NewInt(12), toy.G); !sum.Equal(want) {
+		t.Errorf("7G + 5G = %s, expected 12G = %s", sum, want)
+	}
+}
+
+// TestKoblitzEncoding encodes the largest message M − 1 as a P-256 point and decodes it back.
+func TestKoblitzEncoding(t *testing.T) {
+	p256 := P256Curve()
+	K, M, err := KoblitzParams(p256.P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := new(big.Int).Sub(M, big.NewInt(1))
+	pt, err := p256.KoblitzEncode(m, K)
+	if err != nil {
+		t.Fatalf("encoding M − 1: %v", err)
+	}
+	if !p256.IsOnCurve(pt) {
+		t.Errorf("encoded point %s is not on P-256", pt)
+	}
+	if got := KoblitzDecode(pt, K); got.Cmp(m) != 0 {
+		t.Errorf("decoded %s, expected %s", got, m)
+	}
+}
@@ -0,0 +1,311 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+const (
+	// MaxBSGSTable limits the baby-step table (about 100 bytes per entry), so BSGS handles orders up to 2^44.
+	MaxBSGSTable = 1 << 22
+	// MaxRhoBits is the largest prime order handed to Pollard's rho (about 2^32 steps).
+	MaxRhoBits = 64
+	// rhoFactorSteps limits Brent's rho when factoring p-1.
+	rhoFactorSteps = 1 << 20
+)
+
+// PrimePower is one factor q^e of a factorization.
+type PrimePower struct {
+	Q *big.Int
+	E int
+}
+
+// Factorization part----------------------------------------------------------------------------------
+
+// Factor returns the prime factorization of n > 1 in increasing order: trial division by the
+// primes below 2000, then Brent's variant of Pollard's rho on the cofactor.
+func Factor(n *big.Int) ([]PrimePower, error) {
+	small, rest := TrialFactor(n)
+	primes := append([]*big.Int{}, small...)
+	if err := factorRho(rest, &primes); err != nil {
+		return nil, err
+	}
+	sort.Slice(primes, func(i, j int) bool { return primes[i].Cmp(primes[j]) < 0 })
+
+	var factors []PrimePower
+	for _, q := range primes {
+		if len(factors) > 0 && factors[len(factors)-1].Q.Cmp(q) == 0 {
+			factors[len(factors)-1].E++
+			continue
+		}
+		factors = append(factors, PrimePower{Q: q, E: 1})
+	}
+	return factors, nil
+}
+
+// factorRho splits n into primes and appends them to primes.
+func factorRho(n *big.Int, primes *[]*big.Int) error {
+	if n.Cmp(big.NewInt(1)) == 0 {
+		return nil
+	}
+	if n.ProbablyPrime(PrimeRounds) {
+		*primes = append(*primes, new(big.Int).Set(n))
+		return nil
+	}
+	d := brentRho(n)
+	if d == nil {
+		return fmt.Errorf("could not factor the %d-bit composite %s", n.BitLen(), n)
+	}
+	if err := factorRho(d, primes); err != nil {
+		return err
+	}
+	return factorRho(new(big.Int).Div(n, d), primes)
+}
+
+// brentRho finds a non-trivial factor of the composite n with f(x) = x² + c, or nil after rhoFactorSteps.
+func brentRho(n *big.Int) *big.Int {
+	one := big.NewInt(1)
+	for c := int64(1); c <= 3; c++ {
+		bc := big.NewInt(c)
+		f := func(x *big.Int) *big.Int {
+			x.Mul(x, x)
+			x.Add(x, bc)
+			return x.Mod(x, n)
+		}
+		y, x, ys := big.NewInt(2), new(big.Int), new(big.Int)
+		q, g, diff := big.NewInt(1), big.NewInt(1), new(big.Int)
+		const batch = 128
+		steps := 0
+		for r := 1; g.Cmp(one) == 0 && steps < rhoFactorSteps; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				f(y)
+			}
+			for k := 0; k < r && g.Cmp(one) == 0; k += batch {
+				ys.Set(y)
+				for i := 0; i < batch && i < r-k; i++ {
+					f(y)
+					q.Mul(q, diff.Sub(x, y).Abs(diff))
+					q.Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+				steps += batch
+			}
+		}
+		if g.Cmp(n) == 0 {
+			// The batch overshot: step again one by one from ys
+			for {
+				f(ys)
+				g.GCD(nil, nil, diff.Sub(x, ys).Abs(diff), n)
+				if g.Cmp(one) != 0 {
+					break
+				}
+			}
+		}
+		if g.Cmp(one) != 0 && g.Cmp(n) != 0 {
+			return g
+		}
+	}
+	return nil
+}
+
+// GroupOrder returns the order of g modulo p, given the factorization of p-1.
+func GroupOrder(g, p *big.Int, factors []PrimePower) *big.Int {
+	n := new(big.Int).Sub(p, big.NewInt(1))
+	one := big.NewInt(1)
+	m := new(big.Int)
+	for _, f := range factors {
+		for i := 0; i < f.E; i++ {
+			m.Div(n, f.Q)
+			if new(big.Int).Exp(g, m, p).Cmp(one) != 0 {
+				break
+			}
+			n.Set(m)
+		}
+	}
+	return n
+}
+
+// Discrete logarithm part-----------------------------------------------------------------------------
+// All solvers return x in [0, n) with g^x ≡ h (mod p), where n is the order of g.
+
+// BabyStepGiantStep stores the baby steps g^j, j < m = ⌈√n⌉, and walks the giant steps h·(g^(-m))^i
+// until one is in the table: then x = i·m + j. Time and memory O(√n).
+func BabyStepGiantStep(g, h, p, n *big.Int) (*big.Int, error) {
+	m := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(m, m).Cmp(n) < 0 {
+		m.Add(m, big.NewInt(1))
+	}
+	if m.Cmp(big.NewInt(MaxBSGSTable)) > 0 {
+		return nil, fmt.Errorf("baby-step table would need %s entries (limit %d)", m, MaxBSGSTable)
+	}
+	size := m.Int64()
+
+	table := make(map[string]int64, size)
+	e := big.NewInt(1)
+	for j := int64(0); j < size; j++ {
+		key := string(e.Bytes())
+		if _, ok := table[key]; !ok {
+			table[key] = j
+		}
+		e.Mul(e, g)
+		e.Mod(e, p)
+	}
+
+	// factor = g^(-m) mod p
+	gm := new(big.Int).Exp(g, m, p)
+	factor, err := ModInverse(gm, p)
+	if err != nil {
+		return nil, err
+	}
+	gamma := new(big.Int).Mod(h, p)
+	for i := int64(0); i < size; i++ {
+		if j, ok := table[string(gamma.Bytes())]; ok {
+			x := new(big.Int).Mul(big.NewInt(i), m)
+			x.Add(x, big.NewInt(j))
+			return x.Mod(x, n), nil
+		}
+		gamma.Mul(gamma, factor)
+		gamma.Mod(gamma, p)
+	}
+	return nil, fmt.Errorf("h is not a power of g")
+}
+
+// PollardRhoLog walks x_i = g^(a_i)·h^(b_i) through the three-way partition
+// (x·h, x², x·g) with Floyd's cycle detection. A collision x_i = x_2i gives
+// (b_i − b_2i)·x ≡ a_2i − a_i (mod n), whose solutions are checked against g^x = h.
+// Time O(√n), constant memory.
+func PollardRhoLog(g, h, p, n *big.Int) (*big.Int, error) {
+	if n.BitLen() > MaxRhoBits {
+		return nil, fmt.Errorf("the order has %d bits, more than the %d bits Pollard rho can handle here", n.BitLen(), MaxRhoBits)
+	}
+	h = new(big.Int).Mod(h, p)
+	if h.Cmp(big.NewInt(1)) == 0 {
+		return big.NewInt(0), nil
+	}
+
+	// step advances (x, a, b) by one position of the walk
+	step := func(x, a, b *big.Int) {
+		switch x.Bits()[0] % 3 {
+		case 0:
+			x.Mul(x, h)
+			b.Add(b, big.NewInt(1))
+		case 1:
+			x.Mul(x, x)
+			a.Lsh(a, 1)
+			b.Lsh(b, 1)
+		default:
+			x.Mul(x, g)
+			a.Add(a, big.NewInt(1))
+		}
+		x.Mod(x, p)
+		a.Mod(a, n)
+		b.Mod(b, n)
+	}
+
+	// Each √n-sized walk collides with high probability; on a useless collision start elsewhere
+	limit := new(big.Int).Sqrt(n)
+	limit.Lsh(limit, 3)
+	limit.Add(limit, big.NewInt(64))
+	for attempt := 0; attempt < 8; attempt++ {
+		a0, _ := RandomBigInt(n)
+		b0, _ := RandomBigInt(n)
+		x := new(big.Int).Exp(g, a0, p)
+		x.Mul(x, new(big.Int).Exp(h, b0, p))
+		x.Mod(x, p)
+		a, b := new(big.Int).Set(a0), new(big.Int).Set(b0)
+		X, A, B := new(big.Int).Set(x), new(big.Int).Set(a), new(big.Int).Set(b)
+
+		for i := big.NewInt(0); i.Cmp(limit) < 0; i.Add(i, big.NewInt(1)) {
+			step(x, a, b)
+			step(X, A, B)
+			step(X, A, B)
+			if x.Cmp(X) != 0 {
+				continue
+			}
+			candidates, err := SolveLinearCongruence(new(big.Int).Sub(b, B), new(big.Int).Sub(A, a), n)
+			if err == nil {
+				for _, c := range candidates {
+					if new(big.Int).Exp(g, c, p).Cmp(h) == 0 {
+						return c, nil
+					}
+				}
+			}
+			break
+		}
+	}
+	return nil, fmt.Errorf("no logarithm found (h may not be a power of g)")
+}
+
+// PohligHellman reduces the logarithm in the group of order n = Π q^e (factors may list p-1) to one logarithm of
+// order q per digit: x mod q^e is found digit by digit as x = d_0 + d_1·q + ..., where
+// d_k solves (g^(n/q))^(d_k) = (h·g^(−x_k))^(n/q^(k+1)). The residues are joined with the CRT.
+// The prime-order logarithms use BSGS, or Pollard rho when the table would be too large.
+func PohligHellman(g, h, p, n *big.Int, factors []PrimePower) (*big.Int, error) {
+	x := big.NewInt(0)
+	modulus := big.NewInt(1)
+	for _, f := range factors {
+		// e is the exponent of q in n, which may be lower than in p-1
+		e, rest := 0, new(big.Int).Set(n)
+		for e < f.E && new(big.Int).Mod(rest, f.Q).Sign() == 0 {
+			rest.Div(rest, f.Q)
+			e++
+		}
+		if e == 0 {
+			continue
+		}
+		f.E = e
+		qe := new(big.Int).Exp(f.Q, big.NewInt(int64(e)), nil)
+		cof := new(big.Int).Div(n, qe)
+		gi := new(big.Int).Exp(g, cof, p) // order q^e
+		hi := new(big.Int).Exp(h, cof, p)
+		gq := new(big.Int).Exp(gi, new(big.Int).Exp(f.Q, big.NewInt(int64(f.E-1)), nil), p) // order q
+
+		xi := big.NewInt(0)
+		qk := big.NewInt(1)
+		for k := 0; k < f.E; k++ {
+			// (h_i · g_i^(−x_i))^(q^(e−1−k))
+			t := new(big.Int).Exp(gi, xi, p)
+			tInv, err := ModInverse(t, p)
+			if err != nil {
+				return nil, err
+			}
+			t.Mul(hi, tInv)
+			t.Mod(t, p)
+			t.Exp(t, new(big.Int).Exp(f.Q, big.NewInt(int64(f.E-1-k)), nil), p)
+
+			d, err := PrimeOrderLog(gq, t, p, f.Q)
+			if err != nil {
+				return nil, fmt.Errorf("subgroup of order %s: %v", f.Q, err)
+			}
+			xi.Add(xi, new(big.Int).Mul(d, qk))
+			qk.Mul(qk, f.Q)
+		}
+
+		// CRT: x ≡ x (mod modulus), x ≡ xi (mod q^e)
+		inv, err := ModInverse(modulus, qe)
+		if err != nil {
+			return nil, err
+		}
+		t := new(big.Int).Sub(xi, x)
+		t.Mul(t, inv)
+		t.Mod(t, qe)
+		x.Add(x, t.Mul(t, modulus))
+		modulus.Mul(modulus, qe)
+		x.Mod(x, modulus)
+	}
+	if new(big.Int).Exp(g, x, p).Cmp(new(big.Int).Mod(h, p)) != 0 {
+		return nil, fmt.Errorf("h is not a power of g")
+	}
+	return x, nil
+}
+
+// PrimeOrderLog solves g^x = h in a group of prime order q with BSGS, or Pollard rho for larger q.
+func PrimeOrderLog(g, h, p, q *big.Int) (*big.Int, error) {
+	if new(big.Int).Sqrt(q).Cmp(big.NewInt(MaxBSGSTable)) < 0 {
+		return BabyStepGiantStep(g, h, p, q)
+	}
+	return PollardRhoLog(g, h, p, q)
+}
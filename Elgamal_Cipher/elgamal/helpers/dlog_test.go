@@ -0,0 +1,47 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestFactor factors 466 = 2·233 and the Fermat number 2^64 + 1 = 274177 · 67280421310721 (Brent rho).
+func TestFactor(t *testing.T) {
+	f64 := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+	tests := []struct {
+		n    *big.Int
+		want []int64
+	}{
+		{big.NewInt(466), []int64{2, 233}},
+		{f64, []int64{274177, 67280421310721}},
+	}
+	for _, tt := range tests {
+		factors, err := Factor(tt.n)
+		if err != nil {
+			t.Errorf("Factor(%s): %v", tt.n, err)
+			continue
+		}
+		if len(factors) != len(tt.want) {
+			t.Errorf("Factor(%s) = %v, expected %v", tt.n, factors, tt.want)
+			continue
+		}
+		for i, f := range factors {
+			if f.Q.Int64() != tt.want[i] || f.E != 1 {
+				t.Errorf("Factor(%s) = %v, expected %v", tt.n, factors, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// TestGroupOrder checks that g = 2 generates Z*_467 (order 466).
+func TestGroupOrder(t *testing.T) {
+	factors, err := Factor(big.NewInt(466))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := GroupOrder(big.NewInt(2), big.NewInt(467), factors); n.Int64() != 466 {
+		t.Errorf("order of 2 modulo 467 is %s, expected 466", n)
+	}
+}
@@ -4,8 +4,6 @@ package helpers
 import (
 	"bufio"
 	"crypto/rand"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -137,22 +135,3 @@ func IsCoprime(a, b *big.Int) bool {
 
 	return isOne
 }
-
-
-// Function calculates SHA256 hash of the input string, converts it to a big int, and saves it.
-func CreateShortcutSHA(text, filename string) error {
-	// Oblicz skrót SHA256
-	hash := sha256.Sum256([]byte(text))
-
-	// Zamień skrót (bytes) na BigInt
-	m := new(big.Int).SetBytes(hash[:])
-
-	// Zapisz big.Int do pliku
-	err := WriteBigIntsToFile(filename, []*big.Int{m})
-	if err != nil {
-		return fmt.Errorf("failed to write hashed message to file: %v", err)
-	}
-
-	log.Printf("[INFO] Hashed text \"%s\" → SHA256 = %s\nSaved to %s", text, hex.EncodeToString(hash[:]), filename)
-	return nil
-}
@@ -0,0 +1,46 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+// HKDF derives `length` bytes from the input key material with HKDF-SHA256 (RFC 5869).
+func HKDF(secret, salt, info []byte, length int) ([]byte, error) {
+	if length > 255*sha256.Size {
+		return nil, fmt.Errorf("hkdf: requested %d bytes, maximum is %d", length, 255*sha256.Size)
+	}
+	if len(salt) == 0 {
+		salt = make([]byte, sha256.Size)
+	}
+
+	// Extract: PRK = HMAC(salt, IKM)
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	// Expand: T(i) = HMAC(PRK, T(i-1) || info || i)
+	var out, prev []byte
+	for i := byte(1); len(out) < length; i++ {
+		expand := hmac.New(sha256.New, prk)
+		expand.Write(prev)
+		expand.Write(info)
+		expand.Write([]byte{i})
+		prev = expand.Sum(nil)
+		out = append(out, prev...)
+	}
+	return out[:length], nil
+}
+
+// FixedBytes returns the big-endian bytes of a value left-padded to `size` bytes,
+// so that numbers below p always encode with the same length.
+func FixedBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
@@ -0,0 +1,452 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Key file formats-----------------------------------------------------------------------------------
+// "pem"    typed PEM blocks (ELGAMAL PUBLIC KEY / ELGAMAL PRIVATE KEY) with a DER body
+// "json"   named fields p, g, beta and b
+// "legacy" the old bare numbers, one per line (p, g, β or p, g, b); read only when importing
+
+const (
+	KeyVersion = 1
+
+	FormatPEM    = "pem"
+	FormatJSON   = "json"
+	FormatLegacy = "legacy"
+
+	pemPublicType  = "ELGAMAL PUBLIC KEY"
+	pemPrivateType = "ELGAMAL PRIVATE KEY"
+
+	// PBKDF2Iterations is the work factor for new passphrase-protected keys
+	PBKDF2Iterations = 600000
+	// maxPBKDF2Iterations bounds the work factor read from a key file, so a crafted file
+	// cannot make opening it run for hours
+	maxPBKDF2Iterations = 10 * PBKDF2Iterations
+	keyKDF              = "PBKDF2-SHA256"
+	keyCipher           = "AES-256-GCM"
+)
+
+// ErrWrongPassphrase is returned when an encrypted private key cannot be opened.
+var ErrWrongPassphrase = errors.New("wrong passphrase or damaged key file")
+
+// ElGamalKey is a public key (B == nil) or a private key.
+type ElGamalKey struct {
+	P, G, Beta *big.Int
+	B          *big.Int
+}
+
+// IsPrivate reports whether the key holds the private exponent b.
+func (k *ElGamalKey) IsPrivate() bool { return k.B != nil }
+
+// Public returns the public part of the key.
+func (k *ElGamalKey) Public() *ElGamalKey {
+	return &ElGamalKey{P: k.P, G: k.G, Beta: k.Beta}
+}
+
+// Fingerprint is SHA-256 over the DER encoding of the public parameters (version, p, g, β),
+// so the public and the private key file of one pair have the same fingerprint.
+func (k *ElGamalKey) Fingerprint() string {
+	der, _ := asn1.Marshal(publicKeyASN1{KeyVersion, k.P, k.G, k.Beta})
+	sum := sha256.Sum256(der)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// DER bodies of the PEM blocks
+type publicKeyASN1 struct {
+	Version    int
+	P, G, Beta *big.Int
+}
+
+type privateKeyASN1 struct {
+	Version       int
+	P, G, Beta, B *big.Int
+}
+
+// JSON form, numbers as decimal strings
+type keyJSON struct {
+	Version     int            `json:"version"`
+	Type        string         `json:"type"`
+	Fingerprint string         `json:"fingerprint"`
+	P           string         `json:"p"`
+	G           string         `json:"g"`
+	Beta        string         `json:"beta"`
+	B           string         `json:"b,omitempty"`
+	Encryption  *keyEncryption `json:"encryption,omitempty"`
+}
+
+// keyEncryption describes how b is protected; Ciphertext replaces the "b" field.
+type keyEncryption struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	Cipher     string `json:"cipher"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// sealWithPassphrase encrypts plain with a key derived from the passphrase; the public
+// parameters are bound as additional data so they cannot be swapped.
+func sealWithPassphrase(plain []byte, passphrase string, aad []byte) (*keyEncryption, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := passphraseGCM(passphrase, salt, PBKDF2Iterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &keyEncryption{
+		KDF:        keyKDF,
+		Iterations: PBKDF2Iterations,
+		Salt:       hex.EncodeToString(salt),
+		Cipher:     keyCipher,
+		Nonce:      hex.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plain, aad)),
+	}, nil
+}
+
+func openWithPassphrase(enc *keyEncryption, passphrase string, aad []byte) ([]byte, error) {
+	if enc.KDF != keyKDF || enc.Cipher != keyCipher {
+		return nil, fmt.Errorf("unsupported key encryption %s / %s", enc.KDF, enc.Cipher)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("the private key is encrypted, a passphrase is required")
+	}
+	salt, err1 := hex.DecodeString(enc.Salt)
+	nonce, err2 := hex.DecodeString(enc.Nonce)
+	sealed, err3 := base64.StdEncoding.DecodeString(enc.Ciphertext)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("invalid encryption parameters in key file")
+	}
+	if err := checkIterations(enc.Iterations); err != nil {
+		return nil, err
+	}
+	gcm, err := passphraseGCM(passphrase, salt, enc.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce in key file")
+	}
+	plain, err := gcm.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func passphraseGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := pbkdf2.Key([]byte(passphrase), salt, iterations, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// MarshalKey encodes the key in the given format. A private key is encrypted when passphrase is not empty.
+func MarshalKey(k *ElGamalKey, format, passphrase string) ([]byte, error) {
+	switch format {
+	case FormatPEM:
+		return marshalKeyPEM(k, passphrase)
+	case FormatJSON:
+		return marshalKeyJSON(k, passphrase)
+	case FormatLegacy:
+		last := k.Beta
+		if k.IsPrivate() {
+			if passphrase != "" {
+				return nil, fmt.Errorf("the legacy format cannot be encrypted")
+			}
+			last = k.B
+		}
+		return []byte(fmt.Sprintf("%s\n%s\n%s\n", k.P, k.G, last)), nil
+	}
+	return nil, fmt.Errorf("unknown key format %q (pem, json or legacy)", format)
+}
+
+func marshalKeyPEM(k *ElGamalKey, passphrase string) ([]byte, error) {
+	headers := map[string]string{"Version": strconv.Itoa(KeyVersion), "Fingerprint": k.Fingerprint()}
+	if !k.IsPrivate() {
+		der, err := asn1.Marshal(publicKeyASN1{KeyVersion, k.P, k.G, k.Beta})
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pemPublicType, Headers: headers, Bytes: der}), nil
+	}
+
+	der, err := asn1.Marshal(privateKeyASN1{KeyVersion, k.P, k.G, k.Beta, k.B})
+	if err != nil {
+		return nil, err
+	}
+	if passphrase != "" {
+		// The whole DER body is encrypted, the fingerprint header is authenticated
+		enc, err := sealWithPassphrase(der, passphrase, []byte(k.Fingerprint()))
+		if err != nil {
+			return nil, err
+		}
+		headers["KDF"] = fmt.Sprintf("%s,%d,%s", enc.KDF, enc.Iterations, enc.Salt)
+		headers["Cipher"] = fmt.Sprintf("%s,%s", enc.Cipher, enc.Nonce)
+		der, _ = base64.StdEncoding.DecodeString(enc.Ciphertext)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPrivateType, Headers: headers, Bytes: der}), nil
+}
+
+func marshalKeyJSON(k *ElGamalKey, passphrase string) ([]byte, error) {
+	out := keyJSON{
+		Version:     KeyVersion,
+		Type:        "elgamal-public-key",
+		Fingerprint: k.Fingerprint(),
+		P:           k.P.String(),
+		G:           k.G.String(),
+		Beta:        k.Beta.String(),
+	}
+	if k.IsPrivate() {
+		out.Type = "elgamal-private-key"
+		if passphrase == "" {
+			out.B = k.B.String()
+		} else {
+			enc, err := sealWithPassphrase([]byte(k.B.String()), passphrase, []byte(out.Fingerprint))
+			if err != nil {
+				return nil, err
+			}
+			out.Encryption = enc
+		}
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// ParseKey detects the format (PEM, JSON or legacy numbers) and decodes the key.
+// With wantPrivate a file holding only the public key is rejected.
+func ParseKey(data []byte, wantPrivate bool, passphrase string) (*ElGamalKey, string, error) {
+	trimmed := bytes.TrimSpace(data)
+	var (
+		k      *ElGamalKey
+		format string
+		err    error
+	)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("-----BEGIN")):
+		format = FormatPEM
+		k, err = parseKeyPEM(trimmed, passphrase)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		format = FormatJSON
+		k, err = parseKeyJSON(trimmed, passphrase)
+	default:
+		format = FormatLegacy
+		k, err = parseKeyLegacy(trimmed, wantPrivate)
+	}
+	if err != nil {
+		return nil, format, err
+	}
+	if wantPrivate && !k.IsPrivate() {
+		return nil, format, fmt.Errorf("the file holds a public key, a private key is required")
+	}
+	if k.P.Sign() <= 0 || k.G.Sign() <= 0 || k.Beta.Sign() <= 0 {
+		return nil, format, fmt.Errorf("key parameters must be positive")
+	}
+	if k.IsPrivate() && new(big.Int).Exp(k.G, k.B, k.P).Cmp(k.Beta) != 0 {
+		return nil, format, fmt.Errorf("private exponent b does not match beta")
+	}
+	return k, format, nil
+}
+
+func parseKeyPEM(data []byte, passphrase string) (*ElGamalKey, error) {
+	k, fingerprint, err := decodeKeyPEM(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint != "" && fingerprint != k.Fingerprint() {
+		return nil, fmt.Errorf("fingerprint does not match the key parameters")
+	}
+	return k, nil
+}
+
+// decodeKeyPEM decodes the PEM block and returns the key with its Fingerprint header.
+func decodeKeyPEM(data []byte, passphrase string) (*ElGamalKey, string, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, "", fmt.Errorf("invalid PEM data")
+	}
+	if v := block.Headers["Version"]; v != strconv.Itoa(KeyVersion) {
+		return nil, "", fmt.Errorf("unsupported key version %q", v)
+	}
+
+	switch block.Type {
+	case pemPublicType:
+		var pub publicKeyASN1
+		if _, err := asn1.Unmarshal(block.Bytes, &pub); err != nil {
+			return nil, "", fmt.Errorf("invalid %s: %v", pemPublicType, err)
+		}
+		return &ElGamalKey{P: pub.P, G: pub.G, Beta: pub.Beta}, block.Headers["Fingerprint"], nil
+
+	case pemPrivateType:
+		der := block.Bytes
+		if kdf, ok := block.Headers["KDF"]; ok {
+			enc, err := pemEncryption(kdf, block.Headers["Cipher"])
+			if err != nil {
+				return nil, "", err
+			}
+			enc.Ciphertext = base64.StdEncoding.EncodeToString(block.Bytes)
+			der, err = openWithPassphrase(enc, passphrase, []byte(block.Headers["Fingerprint"]))
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		var priv privateKeyASN1
+		if _, err := asn1.Unmarshal(der, &priv); err != nil {
+			return nil, "", fmt.Errorf("invalid %s: %v", pemPrivateType, err)
+		}
+		return &ElGamalKey{P: priv.P, G: priv.G, Beta: priv.Beta, B: priv.B}, block.Headers["Fingerprint"], nil
+	}
+	return nil, "", fmt.Errorf("unexpected PEM block %q", block.Type)
+}
+
+// checkIterations accepts PBKDF2 iteration counts from 1 to maxPBKDF2Iterations.
+func checkIterations(n int) error {
+	if n < 1 || n > maxPBKDF2Iterations {
+		return fmt.Errorf("invalid PBKDF2 iteration count %d in key file (allowed 1 to %d)", n, maxPBKDF2Iterations)
+	}
+	return nil
+}
+
+// pemEncryption parses the headers "KDF: name,iterations,salt" and "Cipher: name,nonce".
+func pemEncryption(kdf, cipherHeader string) (*keyEncryption, error) {
+	k := strings.Split(kdf, ",")
+	c := strings.Split(cipherHeader, ",")
+	if len(k) != 3 || len(c) != 2 {
+		return nil, fmt.Errorf("invalid KDF/Cipher headers")
+	}
+	iterations, err := strconv.Atoi(k[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid iteration count %q", k[1])
+	}
+	if err := checkIterations(iterations); err != nil {
+		return nil, err
+	}
+	return &keyEncryption{KDF: k[0], Iterations: iterations, Salt: k[2], Cipher: c[0], Nonce: c[1]}, nil
+}
+
+func parseKeyJSON(data []byte, passphrase string) (*ElGamalKey, error) {
+	var in keyJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, fmt.Errorf("invalid JSON key: %v", err)
+	}
+	if in.Version != KeyVersion {
+		return nil, fmt.Errorf("unsupported key version %d", in.Version)
+	}
+	if in.Type != "elgamal-public-key" && in.Type != "elgamal-private-key" {
+		return nil, fmt.Errorf("unexpected key type %q", in.Type)
+	}
+
+	number := func(name, value string) (*big.Int, error) {
+		n, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid or missing field %q", name)
+		}
+		return n, nil
+	}
+	k := &ElGamalKey{}
+	var err error
+	if k.P, err = number("p", in.P); err != nil {
+		return nil, err
+	}
+	if k.G, err = number("g", in.G); err != nil {
+		return nil, err
+	}
+	if k.Beta, err = number("beta", in.Beta); err != nil {
+		return nil, err
+	}
+	if in.Type == "elgamal-private-key" {
+		b := in.B
+		if in.Encryption != nil {
+			plain, err := openWithPassphrase(in.Encryption, passphrase, []byte(in.Fingerprint))
+			if err != nil {
+				return nil, err
+			}
+			b = string(plain)
+		}
+		if k.B, err = number("b", b); err != nil {
+			return nil, err
+		}
+	}
+	if in.Fingerprint != "" && in.Fingerprint != k.Fingerprint() {
+		return nil, fmt.Errorf("fingerprint does not match the key parameters")
+	}
+	return k, nil
+}
+
+// parseKeyLegacy imports the bare numbers p, g, β (public) or p, g, b (private).
+func parseKeyLegacy(data []byte, private bool) (*ElGamalKey, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("legacy key must have exactly 3 numbers, found %d", len(fields))
+	}
+	values := make([]*big.Int, 3)
+	for i, f := range fields {
+		n, ok := new(big.Int).SetString(f, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid number on line %d", i+1)
+		}
+		values[i] = n
+	}
+	p, g := values[0], values[1]
+	if private {
+		b := values[2]
+		return &ElGamalKey{P: p, G: g, Beta: new(big.Int).Exp(g, b, p), B: b}, nil
+	}
+	return &ElGamalKey{P: p, G: g, Beta: values[2]}, nil
+}
+
+// ReadKeyFile reads a key in any supported format.
+func ReadKeyFile(path string, wantPrivate bool, passphrase string) (*ElGamalKey, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	k, format, err := ParseKey(data, wantPrivate, passphrase)
+	if err != nil {
+		return nil, format, fmt.Errorf("%s: %v", path, err)
+	}
+	return k, format, nil
+}
+
+// WriteKeyFile saves the key in the given format (private keys with mode 0600).
+func WriteKeyFile(path string, k *ElGamalKey, format, passphrase string) error {
+	data, err := MarshalKey(k, format, passphrase)
+	if err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if k.IsPrivate() {
+		mode = 0600
+	}
+	return os.WriteFile(path, data, mode)
+}
@@ -0,0 +1,93 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func testKey() *ElGamalKey {
+	k := &ElGamalKey{P: big.NewInt(467), G: big.NewInt(2), B: big.NewInt(127)}
+	k.Beta = new(big.Int).Exp(k.G, k.B, k.P)
+	return k
+}
+
+// TestKeyRoundTrip writes a private key in every format, plain and encrypted, and reads it back.
+func TestKeyRoundTrip(t *testing.T) {
+	key := testKey()
+	tests := []struct {
+		format, passphrase string
+	}{
+		{FormatPEM, ""},
+		{FormatPEM, "correct horse"},
+		{FormatJSON, ""},
+		{FormatJSON, "correct horse"},
+		{FormatLegacy, ""},
+	}
+	for _, tt := range tests {
+		data, err := MarshalKey(key, tt.format, tt.passphrase)
+		if err != nil {
+			t.Errorf("%s (passphrase %q): %v", tt.format, tt.passphrase, err)
+			continue
+		}
+		got, detected, err := ParseKey(data, true, tt.passphrase)
+		if err != nil {
+			t.Errorf("%s (passphrase %q): %v", tt.format, tt.passphrase, err)
+			continue
+		}
+		if detected != tt.format {
+			t.Errorf("%s (passphrase %q): detected format %s", tt.format, tt.passphrase, detected)
+		}
+		if got.B.Cmp(key.B) != 0 || got.Fingerprint() != key.Fingerprint() {
+			t.Errorf("%s (passphrase %q): read back a different key", tt.format, tt.passphrase)
+		}
+		if tt.passphrase != "" {
+			if _, _, err := ParseKey(data, true, "wrong"); err != ErrWrongPassphrase {
+				t.Errorf("%s: wrong passphrase gave %v, expected ErrWrongPassphrase", tt.format, err)
+			}
+		}
+	}
+}
+
+// TestPublicKeyIsNotPrivate checks that a public key file is refused where a private key is needed.
+// Legacy files are bare numbers and cannot tell the two apart.
+func TestPublicKeyIsNotPrivate(t *testing.T) {
+	for _, format := range []string{FormatPEM, FormatJSON} {
+		data, err := MarshalKey(testKey().Public(), format, "")
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if _, _, err := ParseKey(data, true, ""); err == nil {
+			t.Errorf("%s public key accepted as a private key", format)
+		}
+	}
+}
+
+// TestIterationLimit rejects key files asking for more than maxPBKDF2Iterations before any
+// key derivation is run.
+func TestIterationLimit(t *testing.T) {
+	tests := []struct {
+		format, old, new string
+	}{
+		{FormatPEM, fmt.Sprintf(",%d,", PBKDF2Iterations), fmt.Sprintf(",%d,", maxPBKDF2Iterations+1)},
+		{FormatJSON, fmt.Sprintf(`"iterations": %d`, PBKDF2Iterations), fmt.Sprintf(`"iterations": %d`, maxPBKDF2Iterations+1)},
+		{FormatPEM, fmt.Sprintf(",%d,", PBKDF2Iterations), ",0,"},
+		{FormatJSON, fmt.Sprintf(`"iterations": %d`, PBKDF2Iterations), `"iterations": 0`},
+	}
+	for _, tt := range tests {
+		data, err := MarshalKey(testKey(), tt.format, "correct horse")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if !strings.Contains(string(data), tt.old) {
+			t.Fatalf("%s: iteration count %q not found in the key file", tt.format, tt.old)
+		}
+		changed := strings.Replace(string(data), tt.old, tt.new, 1)
+		_, _, err = ParseKey([]byte(changed), true, "correct horse")
+		if err == nil || err == ErrWrongPassphrase || !strings.Contains(err.Error(), "iteration count") {
+			t.Errorf("%s with %s: got %v, expected an invalid iteration count", tt.format, tt.new, err)
+		}
+	}
+}
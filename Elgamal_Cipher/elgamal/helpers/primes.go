@@ -0,0 +1,175 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// PrimeRounds is the number of Miller-Rabin rounds passed to big.Int.ProbablyPrime
+// (ProbablyPrime also runs a Baillie-PSW test, so 20 rounds is plenty).
+const PrimeRounds = 20
+
+// smallPrimes holds the primes below 2000, used to sieve candidates and to trial-factor p-1.
+var smallPrimes = func() []int64 {
+	const limit = 2000
+	composite := make([]bool, limit)
+	var primes []int64
+	for i := 2; i < limit; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, int64(i))
+		for j := i * i; j < limit; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}()
+
+// hasSmallFactor reports whether n is divisible by a small prime other than n itself.
+func hasSmallFactor(n *big.Int) bool {
+	m := new(big.Int)
+	for _, sp := range smallPrimes {
+		bp := big.NewInt(sp)
+		if n.Cmp(bp) == 0 {
+			return false
+		}
+		if m.Mod(n, bp).Sign() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateSafePrime returns a safe prime p = 2q+1 of exactly `bits` bits together with the prime q.
+// Candidates for q and p are sieved with small primes before running ProbablyPrime.
+func GenerateSafePrime(bits int) (*big.Int, *big.Int, error) {
+	if bits < 8 {
+		return nil, nil, fmt.Errorf("safe prime needs at least 8 bits, got %d", bits)
+	}
+	one := big.NewInt(1)
+	for {
+		// q has bits-1 bits with the top bit set, so p = 2q+1 has exactly `bits` bits
+		q, err := rand.Prime(rand.Reader, bits-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		p := new(big.Int).Lsh(q, 1)
+		p.Add(p, one)
+		if p.BitLen() != bits || hasSmallFactor(p) {
+			continue
+		}
+		if p.ProbablyPrime(PrimeRounds) {
+			return p, q, nil
+		}
+	}
+}
+
+// SubgroupGenerator returns a generator of the subgroup of prime order q in Z*_p, where p = 2q+1.
+// Squaring a random h gives a quadratic residue; every residue other than 1 has order q.
+func SubgroupGenerator(p, q *big.Int) (*big.Int, error) {
+	one := big.NewInt(1)
+	pm3 := new(big.Int).Sub(p, big.NewInt(3))
+	for {
+		h, err := RandomBigInt(pm3)
+		if err != nil {
+			return nil, err
+		}
+		h.Add(h, big.NewInt(2)) // 2 <= h <= p-2
+		g := new(big.Int).Exp(h, big.NewInt(2), p)
+		if g.Cmp(one) == 0 {
+			continue
+		}
+		// Verify that the order of g is exactly q
+		if new(big.Int).Exp(g, q, p).Cmp(one) != 0 {
+			return nil, fmt.Errorf("generator check failed: g^q != 1 (p is not 2q+1?)")
+		}
+		return g, nil
+	}
+}
+
+// TrialFactor removes all prime factors below 2000 from n.
+// It returns the small prime factors (with repetition) and the remaining cofactor.
+func TrialFactor(n *big.Int) ([]*big.Int, *big.Int) {
+	rest := new(big.Int).Set(n)
+	var factors []*big.Int
+	q, m := new(big.Int), new(big.Int)
+	for _, sp := range smallPrimes {
+		bp := big.NewInt(sp)
+		for {
+			q.QuoRem(rest, bp, m)
+			if m.Sign() != 0 {
+				break
+			}
+			factors = append(factors, bp)
+			rest.Set(q)
+		}
+	}
+	return factors, rest
+}
+
+// GenerateDSAGroup returns a prime p of pbits bits with p-1 divisible by a prime q of qbits bits
+// (FIPS 186 style: p = X - (X mod 2q) + 1), and a generator g of the subgroup of order q.
+func GenerateDSAGroup(pbits, qbits int) (*big.Int, *big.Int, *big.Int, error) {
+	if qbits < 8 || pbits <= qbits {
+		return nil, nil, nil, fmt.Errorf("need 8 <= qbits < pbits, got pbits=%d qbits=%d", pbits, qbits)
+	}
+	one := big.NewInt(1)
+	q, err := rand.Prime(rand.Reader, qbits)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	twoQ := new(big.Int).Lsh(q, 1)
+
+	buf := make([]byte, (pbits+7)/8)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, nil, err
+		}
+		x := new(big.Int).SetBytes(buf)
+		x.SetBit(x, pbits-1, 1)
+		for i := pbits; i < len(buf)*8; i++ {
+			x.SetBit(x, i, 0)
+		}
+		// p = X - (X mod 2q) + 1, so p ≡ 1 (mod 2q)
+		p := new(big.Int).Sub(x, new(big.Int).Mod(x, twoQ))
+		p.Add(p, one)
+		if p.BitLen() != pbits || hasSmallFactor(p) || !p.ProbablyPrime(PrimeRounds) {
+			continue
+		}
+
+		// g = h^((p-1)/q) mod p for the first h giving g != 1
+		e := new(big.Int).Div(new(big.Int).Sub(p, one), q)
+		for h := big.NewInt(2); ; h.Add(h, one) {
+			g := new(big.Int).Exp(h, e, p)
+			if g.Cmp(one) != 0 {
+				return p, q, g, nil
+			}
+		}
+	}
+}
+
+// ReadAllBigIntsFromFile reads every number (one per line) from a file.
+func ReadAllBigIntsFromFile(path string) ([]*big.Int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values []*big.Int
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		n, ok := new(big.Int).SetString(line, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid number in %s", path)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
@@ -0,0 +1,70 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"bufio"
+	"crypto"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// Record is a text file of "name: value" metadata lines followed by numbers, one per line.
+// Signatures, EC keys, key shares and partial decryptions are stored this way.
+type Record struct {
+	Meta   map[string]string
+	Values []*big.Int
+}
+
+// Hash returns the hash function recorded in the "hash" metadata line.
+func (s *Record) Hash() (crypto.Hash, error) {
+	name, ok := s.Meta["hash"]
+	if !ok {
+		return 0, fmt.Errorf("record has no hash metadata")
+	}
+	return ParseHash(name)
+}
+
+// WriteRecord saves the metadata (in the given key order) and the values.
+func WriteRecord(path string, keys []string, meta map[string]string, values []*big.Int) error {
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k + ": " + meta[k] + "\n")
+	}
+	for _, v := range values {
+		sb.WriteString(v.String() + "\n")
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+// ReadRecord parses a file written by WriteRecord.
+func ReadRecord(path string) (*Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rec := &Record{Meta: map[string]string{}}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			rec.Meta[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			continue
+		}
+		n, ok := new(big.Int).SetString(line, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid number in %s", path)
+		}
+		rec.Values = append(rec.Values, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
@@ -0,0 +1,96 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"math/big"
+)
+
+// NonceGenerator produces the deterministic nonces of RFC 6979, section 3.2.
+// The same key and message hash always give the same sequence of k values,
+// so signing never depends on the quality of the random number generator.
+type NonceGenerator struct {
+	q    *big.Int
+	hash crypto.Hash
+	k, v []byte
+}
+
+// NewNonceGenerator initialises the HMAC-DRBG with the private key x and the message hash h1.
+func NewNonceGenerator(q, x *big.Int, h1 []byte, hash crypto.Hash) *NonceGenerator {
+	g := &NonceGenerator{q: q, hash: hash}
+	hlen := hash.Size()
+
+	// b. V = 0x01 0x01 ... ; c. K = 0x00 0x00 ...
+	g.v = make([]byte, hlen)
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+	g.k = make([]byte, hlen)
+
+	seed := append(g.int2octets(x), g.bits2octets(h1)...)
+	// d. K = HMAC_K(V || 0x00 || int2octets(x) || bits2octets(h1)); e. V = HMAC_K(V)
+	g.k = g.mac(g.k, g.v, []byte{0x00}, seed)
+	g.v = g.mac(g.k, g.v)
+	// f. K = HMAC_K(V || 0x01 || int2octets(x) || bits2octets(h1)); g. V = HMAC_K(V)
+	g.k = g.mac(g.k, g.v, []byte{0x01}, seed)
+	g.v = g.mac(g.k, g.v)
+	return g
+}
+
+// Next returns the next candidate k with 1 <= k < q (step h of the RFC).
+// Call it again when the signature turns out to be unusable (r = 0 or s = 0).
+func (g *NonceGenerator) Next() *big.Int {
+	qlen := g.q.BitLen()
+	for {
+		var t []byte
+		for len(t)*8 < qlen {
+			g.v = g.mac(g.k, g.v)
+			t = append(t, g.v...)
+		}
+		k := g.bits2int(t)
+		// Prepare the state for a possible next candidate
+		g.k = g.mac(g.k, g.v, []byte{0x00})
+		g.v = g.mac(g.k, g.v)
+		if k.Sign() > 0 && k.Cmp(g.q) < 0 {
+			return k
+		}
+	}
+}
+
+func (g *NonceGenerator) mac(key []byte, parts ...[]byte) []byte {
+	m := hmac.New(g.hash.New, key)
+	for _, p := range parts {
+		m.Write(p)
+	}
+	return m.Sum(nil)
+}
+
+// bits2int takes the leftmost qlen bits of b as an integer.
+func (g *NonceGenerator) bits2int(b []byte) *big.Int {
+	v := new(big.Int).SetBytes(b)
+	if excess := len(b)*8 - g.q.BitLen(); excess > 0 {
+		v.Rsh(v, uint(excess))
+	}
+	return v
+}
+
+// int2octets encodes x in exactly ceil(qlen/8) bytes.
+func (g *NonceGenerator) int2octets(x *big.Int) []byte {
+	return FixedBytes(x.Bytes(), (g.q.BitLen()+7)/8)
+}
+
+// bits2octets reduces the hash modulo q and encodes it like int2octets.
+func (g *NonceGenerator) bits2octets(b []byte) []byte {
+	z := g.bits2int(b)
+	if z.Cmp(g.q) >= 0 {
+		z.Sub(z, g.q)
+	}
+	return g.int2octets(z)
+}
+
+// Bits2Int is the RFC 6979 bits2int: the leftmost q.BitLen() bits of a hash as an integer.
+// DSA and Schnorr use it to turn the message hash into a number below 2^qlen.
+func Bits2Int(b []byte, q *big.Int) *big.Int {
+	return (&NonceGenerator{q: q}).bits2int(b)
+}
@@ -0,0 +1,31 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"crypto"
+	_ "crypto/sha1" // RFC 6979 test vector uses SHA-1
+	_ "crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// TestNonceGeneratorRFC6979 checks the nonces of RFC 6979 appendix A.1.2 (163-bit q, message "sample").
+func TestNonceGeneratorRFC6979(t *testing.T) {
+	q, _ := new(big.Int).SetString("4000000000000000000020108A2E0CC0D99F8A5EF", 16)
+	x, _ := new(big.Int).SetString("09A4D6792295A7F730FC3F2B49CBC0F62E862272F", 16)
+	tests := []struct {
+		hash crypto.Hash
+		k    string
+	}{
+		{crypto.SHA1, "09744429FA741D12DE2BE8316E35E84DB9E5DF1CD"},
+		{crypto.SHA256, "23AF4074C90A02B3FE61D286D5C87F425E6BDD81B"},
+	}
+	for _, tt := range tests {
+		hasher := tt.hash.New()
+		hasher.Write([]byte("sample"))
+		want, _ := new(big.Int).SetString(tt.k, 16)
+		if k := NewNonceGenerator(q, x, hasher.Sum(nil), tt.hash).Next(); k.Cmp(want) != 0 {
+			t.Errorf("%s: k = %X, expected %s", tt.hash, k, tt.k)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Shamir secret sharing over Z_q-----------------------------------------------------------------------
+// f(x) = a0 + a1·x + ... + a(t-1)·x^(t-1) mod q with a0 = secret; share i is f(i).
+// Any t shares give f(0) by Lagrange interpolation, fewer reveal nothing about it.
+
+// SplitSecret returns the shares f(1..n) and the polynomial coefficients a0..a(t-1).
+func SplitSecret(secret, q *big.Int, t, n int) ([]*big.Int, []*big.Int, error) {
+	if t < 1 || t > n {
+		return nil, nil, fmt.Errorf("threshold must satisfy 1 <= t <= n, got t = %d, n = %d", t, n)
+	}
+	if big.NewInt(int64(n)).Cmp(q) >= 0 {
+		return nil, nil, fmt.Errorf("n must be smaller than q")
+	}
+	coeffs := []*big.Int{new(big.Int).Mod(secret, q)}
+	for i := 1; i < t; i++ {
+		a, err := RandomBigInt(q)
+		if err != nil {
+			return nil, nil, err
+		}
+		coeffs = append(coeffs, a)
+	}
+
+	shares := make([]*big.Int, n)
+	for i := 1; i <= n; i++ {
+		shares[i-1] = evalPolynomial(coeffs, big.NewInt(int64(i)), q)
+	}
+	return shares, coeffs, nil
+}
+
+// evalPolynomial computes f(x) mod q with Horner's rule.
+func evalPolynomial(coeffs []*big.Int, x, q *big.Int) *big.Int {
+	y := new(big.Int)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y.Mul(y, x)
+		y.Add(y, coeffs[i])
+		y.Mod(y, q)
+	}
+	return y
+}
+
+// LagrangeAtZero returns λ_i = Π_{j≠i} j / (j − i) mod q for every index, so f(0) = Σ λ_i·f(i).
+func LagrangeAtZero(indices []int64, q *big.Int) (map[int64]*big.Int, error) {
+	seen := map[int64]bool{}
+	for _, i := range indices {
+		if i <= 0 || seen[i] {
+			return nil, fmt.Errorf("share indices must be distinct and positive, got %v", indices)
+		}
+		seen[i] = true
+	}
+
+	lambdas := map[int64]*big.Int{}
+	for _, i := range indices {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for _, j := range indices {
+			if j == i {
+				continue
+			}
+			num.Mul(num, big.NewInt(j))
+			num.Mod(num, q)
+			den.Mul(den, big.NewInt(j-i))
+			den.Mod(den, q)
+		}
+		denInv, err := ModInverse(den, q)
+		if err != nil {
+			return nil, fmt.Errorf("cannot invert the Lagrange denominator: %v", err)
+		}
+		lambdas[i] = num.Mul(num, denInv).Mod(num, q)
+	}
+	return lambdas, nil
+}
+
+// CombineShares reconstructs f(0) from shares keyed by their index.
+func CombineShares(shares map[int64]*big.Int, q *big.Int) (*big.Int, error) {
+	indices := make([]int64, 0, len(shares))
+	for i := range shares {
+		indices = append(indices, i)
+	}
+	lambdas, err := LagrangeAtZero(indices, q)
+	if err != nil {
+		return nil, err
+	}
+	secret := new(big.Int)
+	for i, s := range shares {
+		secret.Add(secret, new(big.Int).Mul(lambdas[i], s))
+	}
+	return secret.Mod(secret, q), nil
+}
+
+// Feldman verifiable secret sharing--------------------------------------------------------------------
+// The dealer publishes C_j = g^(a_j) mod p. Share i is correct when g^(f(i)) = Π C_j^(i^j),
+// and C_0 = g^b is the public key β, so the shares can be checked without revealing them.
+
+// FeldmanCommitments returns C_j = g^(a_j) mod p.
+func FeldmanCommitments(coeffs []*big.Int, g, p *big.Int) []*big.Int {
+	commits := make([]*big.Int, len(coeffs))
+	for j, a := range coeffs {
+		commits[j] = new(big.Int).Exp(g, a, p)
+	}
+	return commits
+}
+
+// FeldmanVerify checks g^share ≡ Π C_j^(i^j) (mod p); the exponents i^j are reduced mod q.
+func FeldmanVerify(i int64, share *big.Int, commits []*big.Int, g, p, q *big.Int) bool {
+	left := new(big.Int).Exp(g, share, p)
+	right := big.NewInt(1)
+	x := big.NewInt(i)
+	power := big.NewInt(1) // i^j mod q
+	for _, c := range commits {
+		right.Mul(right, new(big.Int).Exp(c, power, p))
+		right.Mod(right, p)
+		power.Mul(power, x)
+		power.Mod(power, q)
+	}
+	return left.Cmp(right) == 0
+}
@@ -0,0 +1,105 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestFeldmanVerify splits secrets in the subgroup of order q = 233 of Z*_467 (g = 4): every share
+// matches the commitments, C_0 is g^b, and a modified share does not match.
+func TestFeldmanVerify(t *testing.T) {
+	p, q, g := big.NewInt(467), big.NewInt(233), big.NewInt(4)
+	tests := []struct {
+		b            int64
+		threshold, n int
+	}{
+		{101, 3, 5},
+		{0, 2, 2},
+		{232, 5, 7},
+	}
+	for _, tt := range tests {
+		b := big.NewInt(tt.b)
+		shares, coeffs, err := SplitSecret(b, q, tt.threshold, tt.n)
+		if err != nil {
+			t.Fatalf("%d-of-%d split of %d: %v", tt.threshold, tt.n, tt.b, err)
+		}
+		commits := FeldmanCommitments(coeffs, g, p)
+		if beta := new(big.Int).Exp(g, b, p); commits[0].Cmp(beta) != 0 {
+			t.Errorf("b = %d: C_0 = %s, expected beta = %s", tt.b, commits[0], beta)
+		}
+		for i, s := range shares {
+			if !FeldmanVerify(int64(i+1), s, commits, g, p, q) {
+				t.Errorf("b = %d: share %d does not match the commitments", tt.b, i+1)
+			}
+		}
+		bad := new(big.Int).Add(shares[0], big.NewInt(1))
+		if FeldmanVerify(1, bad, commits, g, p, q) {
+			t.Errorf("b = %d: modified share 1 matches the commitments", tt.b)
+		}
+	}
+}
+
+// TestCombineShares splits b = 101 into 3-of-5 shares and reconstructs it from several subsets.
+func TestCombineShares(t *testing.T) {
+	q, b := big.NewInt(233), big.NewInt(101)
+	shares, _, err := SplitSecret(b, q, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, indices := range [][]int64{{1, 3, 5}, {2, 4, 5}, {5, 1, 2}, {1, 2, 3, 4, 5}} {
+		subset := map[int64]*big.Int{}
+		for _, i := range indices {
+			subset[i] = shares[i-1]
+		}
+		got, err := CombineShares(subset, q)
+		if err != nil {
+			t.Errorf("shares %v: %v", indices, err)
+			continue
+		}
+		if got.Cmp(b) != 0 {
+			t.Errorf("shares %v reconstruct b = %s, expected 101", indices, got)
+		}
+	}
+}
+
+// TestThresholdDecrypt decrypts with the partials c1^(b_i) of three shares: c1 = g^k is in the
+// subgroup, so c1^b = Π (c1^(b_i))^(λ_i) without b.
+func TestThresholdDecrypt(t *testing.T) {
+	p, q, g, b := big.NewInt(467), big.NewInt(233), big.NewInt(4), big.NewInt(101)
+	beta := new(big.Int).Exp(g, b, p)
+	shares, _, err := SplitSecret(b, q, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		indices []int64
+		k, m    int64
+	}{
+		{[]int64{2, 4, 5}, 77, 300},
+		{[]int64{1, 2, 3}, 1, 2},
+		{[]int64{5, 3, 1}, 232, 466},
+	}
+	for _, tt := range tests {
+		k, m := big.NewInt(tt.k), big.NewInt(tt.m)
+		c1 := new(big.Int).Exp(g, k, p)
+		c2 := new(big.Int).Mul(m, new(big.Int).Exp(beta, k, p))
+		c2.Mod(c2, p)
+
+		lambdas, err := LagrangeAtZero(tt.indices, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c1b := big.NewInt(1)
+		for _, i := range tt.indices {
+			d := new(big.Int).Exp(c1, shares[i-1], p)
+			c1b.Mul(c1b, new(big.Int).Exp(d, lambdas[i], p))
+			c1b.Mod(c1b, p)
+		}
+		got := new(big.Int).Mul(c2, new(big.Int).ModInverse(c1b, p))
+		got.Mod(got, p)
+		if got.Cmp(m) != 0 {
+			t.Errorf("shares %v: threshold decryption gives m = %s, expected %d", tt.indices, got, tt.m)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"crypto"
+	_ "crypto/sha256" // register SHA-224/256
+	_ "crypto/sha512" // register SHA-384/512
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// hashNames maps the accepted -hash values to hash functions.
+var hashNames = map[string]crypto.Hash{
+	"sha256":  crypto.SHA256,
+	"sha-256": crypto.SHA256,
+	"sha384":  crypto.SHA384,
+	"sha-384": crypto.SHA384,
+	"sha512":  crypto.SHA512,
+	"sha-512": crypto.SHA512,
+}
+
+// ParseHash returns the hash function for a name like "sha256" or "SHA-256".
+func ParseHash(name string) (crypto.Hash, error) {
+	h, ok := hashNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unsupported hash %q (use sha256, sha384 or sha512)", name)
+	}
+	return h, nil
+}
+
+// HashFile hashes the whole content of a file.
+func HashFile(path string, h crypto.Hash) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hasher := h.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, err
+	}
+	return hasher.Sum(nil), nil
+}
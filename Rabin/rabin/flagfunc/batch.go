@@ -0,0 +1,232 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+const (
+	BatchFile       = "files/batch.txt"
+	BatchReportFile = "files/batch_report.txt"
+	// MaxRangeSize limits how many numbers one range line may expand to.
+	MaxRangeSize = 1 << 20
+)
+
+var (
+	// BatchInput is the file read by -batch (set from the -in flag)
+	BatchInput = BatchFile
+	// Workers is the size of the worker pool (set from the -workers flag)
+	Workers = runtime.NumCPU()
+)
+
+// errBelowTwo marks the numbers below 2 of a batch, which are neither prime nor composite.
+var errBelowTwo = fmt.Errorf("n < 2 is neither prime nor composite")
+
+// batchJob is one number to test, with the input line it came from.
+type batchJob struct {
+	index int
+	line  int
+	text  string // the number as written, or the malformed line
+	n     *big.Int
+	err   error
+}
+
+// batchResult is one row of the report.
+type batchResult struct {
+	job batchJob
+	res MillerResult
+}
+
+// Result returns the verdict in the words used by wyjscie.txt.
+func (r batchResult) Result() string {
+	switch {
+	case r.job.err != nil:
+		return "błąd"
+	case r.res.Factor != nil:
+		return "znaleziono dzielnik"
+	case r.res.Composite:
+		return "na pewno złożona"
+	case r.res.Proven:
+		return "na pewno pierwsza"
+	default:
+		return "prawdopodobnie pierwsza"
+	}
+}
+
+// parseBatchFile reads one number or one range "a..b" (also "a-b") per line.
+// Empty lines and "#" comments are skipped; malformed lines and numbers below 2 (neither
+// prime nor composite) become jobs with err set, so they are reported without stopping the
+// rest of the batch.
+func parseBatchFile(path string) ([]batchJob, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %v", err)
+	}
+	defer file.Close()
+
+	var jobs []batchJob
+	add := func(line int, text string, n *big.Int, err error) {
+		if err == nil && n.Cmp(big.NewInt(2)) < 0 {
+			n, err = nil, errBelowTwo
+		}
+		jobs = append(jobs, batchJob{index: len(jobs), line: line, text: text, n: n, err: err})
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		lo, hi, err := parseRange(text)
+		switch {
+		case err != nil:
+			add(lineNo, text, nil, err)
+		case lo == hi:
+			add(lineNo, text, lo, nil)
+		default:
+			for n := lo; n.Cmp(hi) <= 0; n = new(big.Int).Add(n, big.NewInt(1)) {
+				add(lineNo, n.String(), n, nil)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	return jobs, nil
+}
+
+// parseRange reads one number (then lo == hi, the same pointer) or a range "a..b" (also "a-b")
+// of at most MaxRangeSize numbers.
+func parseRange(text string) (*big.Int, *big.Int, error) {
+	sep := ".."
+	if !strings.Contains(text, sep) {
+		sep = "-"
+	}
+	parts := strings.SplitN(text, sep, 2)
+	if len(parts) == 1 || parts[0] == "" {
+		// single number (a leading "-" is a negative number, not a range)
+		n, ok := new(big.Int).SetString(text, 10)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid number")
+		}
+		return n, n, nil
+	}
+
+	lo, ok1 := new(big.Int).SetString(strings.TrimSpace(parts[0]), 10)
+	hi, ok2 := new(big.Int).SetString(strings.TrimSpace(parts[1]), 10)
+	switch {
+	case !ok1 || !ok2:
+		return nil, nil, fmt.Errorf("invalid range")
+	case lo.Cmp(hi) > 0:
+		return nil, nil, fmt.Errorf("empty range: start > end")
+	case new(big.Int).Sub(hi, lo).Cmp(big.NewInt(MaxRangeSize)) >= 0:
+		return nil, nil, fmt.Errorf("range has more than %d numbers", MaxRangeSize)
+	}
+	return lo, hi, nil
+}
+
+// BatchTest tests every number of BatchInput with the test chosen by Method, using a pool of
+// Workers goroutines, and writes one report row per number in input order.
+func BatchTest(path string) error {
+	log.Println("Rabin-Miller batch start")
+
+	jobs, err := parseBatchFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read batch file: %v", err)
+	}
+	if len(jobs) == 0 {
+		return fmt.Errorf("no numbers in %s", path)
+	}
+
+	if _, err := PrimalityTest(big.NewInt(3), nil); err != nil {
+		return err
+	}
+
+	workers := Workers
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]batchResult, len(jobs))
+	queue := make(chan batchJob)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				r := batchResult{job: job}
+				if job.err == nil {
+					r.res, _ = PrimalityTest(job.n, nil) // Method is checked before the pool starts
+				}
+				results[job.index] = r // each index is written by exactly one worker
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := writeBatchReport(BatchReportFile, path, results, workers); err != nil {
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+	return nil
+}
+
+// writeBatchReport writes the table and a summary with the count of each verdict.
+func writeBatchReport(reportPath, inputPath string, results []batchResult, workers int) error {
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Fprintf(file, "# Rabin-Miller batch: %s, %d numbers, %d workers, method %s\n", inputPath, len(results), workers, Method)
+	tw := tabwriter.NewWriter(file, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "line\tn\twynik\tdzielnik\tświadek\titeracje\tmetoda")
+	counts := map[string]int{}
+	for _, r := range results {
+		verdict := r.Result()
+		counts[verdict]++
+		if r.job.err != nil {
+			fmt.Fprintf(tw, "%d\t%s\t%s: %v\t-\t-\t-\t-\n", r.job.line, r.job.text, verdict, r.job.err)
+			log.Printf("[WARN] line %d: %v: %q", r.job.line, r.job.err, r.job.text)
+			continue
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%s\n", r.job.line, r.job.text, verdict,
+			orDash(r.res.Factor), orDash(r.res.Witness), r.res.Iterations, r.res.Method)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(file)
+	for _, verdict := range []string{"na pewno pierwsza", "prawdopodobnie pierwsza", "na pewno złożona", "znaleziono dzielnik", "błąd"} {
+		fmt.Fprintf(file, "# %s: %d\n", verdict, counts[verdict])
+		log.Printf("[INFO] %s: %d", verdict, counts[verdict])
+	}
+	return nil
+}
+
+func orDash(v *big.Int) string {
+	if v == nil {
+		return "-"
+	}
+	return v.String()
+}
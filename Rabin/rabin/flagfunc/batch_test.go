@@ -0,0 +1,88 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestParseRange covers single numbers, ranges written with ".." and "-", negative numbers and the
+// MaxRangeSize limit.
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		text   string
+		lo, hi string // empty: an error is expected
+	}{
+		{"97", "97", "97"},
+		{"-7", "-7", "-7"},
+		{"10..20", "10", "20"},
+		{"10-20", "10", "20"},
+		{" 10 .. 20 ", "10", "20"},
+		{"-5..3", "-5", "3"},
+		{"5..5", "5", "5"},
+		{"20..10", "", ""},
+		{"20-10", "", ""},
+		{"abc", "", ""},
+		{"1..x", "", ""},
+		{"10-", "", ""},
+		{fmt.Sprintf("1..%d", MaxRangeSize), "1", fmt.Sprint(MaxRangeSize)},
+		{fmt.Sprintf("0..%d", MaxRangeSize), "", ""},
+	}
+	for _, tt := range tests {
+		lo, hi, err := parseRange(tt.text)
+		if tt.lo == "" {
+			if err == nil {
+				t.Errorf("%q: parsed as %s..%s, expected an error", tt.text, lo, hi)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.text, err)
+			continue
+		}
+		if lo.String() != tt.lo || hi.String() != tt.hi {
+			t.Errorf("%q: %s..%s, expected %s..%s", tt.text, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+// TestParseBatchFile checks that numbers below 2 and malformed lines become error rows,
+// while the other numbers of the same range are kept.
+func TestParseBatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.txt")
+	input := "# comment\n97\n\n-7\nabc\n0..3   # 0 and 1 are errors\n"
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := parseBatchFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		line int
+		text string
+		err  bool
+	}{
+		{2, "97", false},
+		{4, "-7", true},
+		{5, "abc", true},
+		{6, "0", true},
+		{6, "1", true},
+		{6, "2", false},
+		{6, "3", false},
+	}
+	if len(jobs) != len(want) {
+		t.Fatalf("%d jobs, expected %d", len(jobs), len(want))
+	}
+	for i, w := range want {
+		j := jobs[i]
+		if j.index != i || j.line != w.line || j.text != w.text || (j.err != nil) != w.err {
+			t.Errorf("job %d: line %d %q error %v, expected line %d %q error = %t", i, j.line, j.text, j.err, w.line, w.text, w.err)
+		}
+		if j.err == nil && j.n == nil {
+			t.Errorf("job %d: no number", i)
+		}
+	}
+}
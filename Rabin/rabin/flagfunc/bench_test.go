@@ -0,0 +1,58 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"rabin/helpers"
+)
+
+// benchPrimes are the largest primes below 2^bits: 2^bits − k. A prime makes both tests run
+// all Iterations rounds.
+var benchPrimes = []struct {
+	bits int
+	k    int64
+}{{512, 569}, {1024, 105}, {2048, 1557}, {4096, 2549}, {8192, 2439}}
+
+func benchPrime(bits int, k int64) *big.Int {
+	p := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	return p.Sub(p, big.NewInt(k))
+}
+
+// BenchmarkRabinMiller times the Rabin-Miller test with Iterations random bases.
+func BenchmarkRabinMiller(b *testing.B) {
+	two := big.NewInt(2)
+	for _, bp := range benchPrimes {
+		n := benchPrime(bp.bits, bp.k)
+		nMinusTwo := new(big.Int).Sub(n, two)
+		randomBase := func(int) (*big.Int, error) {
+			return helpers.CryptoRandBigIntBetween(two, nMinusTwo)
+		}
+		b.Run(fmt.Sprintf("bits=%d", bp.bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if res := rabinMillerBases(n, nil, randomBase, Iterations); res.Composite {
+					b.Fatalf("2^%d - %d reported composite", bp.bits, bp.k)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkProbablyPrime times big.Int.ProbablyPrime (Iterations Miller-Rabin rounds and a
+// Lucas test) on the same numbers.
+func BenchmarkProbablyPrime(b *testing.B) {
+	for _, bp := range benchPrimes {
+		n := benchPrime(bp.bits, bp.k)
+		b.Run(fmt.Sprintf("bits=%d", bp.bits), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if !n.ProbablyPrime(Iterations) {
+					b.Fatalf("2^%d - %d reported composite", bp.bits, bp.k)
+				}
+			}
+		})
+	}
+}
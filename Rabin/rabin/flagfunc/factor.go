@@ -0,0 +1,246 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"rabin/helpers"
+)
+
+const (
+	FactorsFile = "files/factors.txt"
+
+	// Limits of the factoring stages, tried in this order on every composite part
+	factorRhoSteps = 1 << 18
+	pm1B1          = 100000
+	pm1B2          = 1 << 22
+	pp1B1          = 50000
+	ecmCurves      = 200
+)
+
+// ecmSchedule raises B1 as more curves fail (GMP-ECM's bounds for 20, 25 and 30 digit
+// factors), stage 2 runs up to B2 = 100·B1.
+var ecmSchedule = []struct {
+	B1     int64
+	curves int
+}{{11000, 20}, {50000, 60}, {250000, ecmCurves - 80}}
+
+// Factor methods
+const (
+	ByTrialDivision = "trial division"
+	ByPerfectPower  = "perfect power"
+	ByRabinMiller   = "Rabin-Miller (r)"
+	ByRho           = "Pollard rho (Brent)"
+	ByPM1           = "Pollard p-1"
+	ByPP1           = "Williams p+1"
+	ByECM           = "ECM"
+)
+
+// PrimeFactor is one prime of the factorization with the method that split it off
+// and the test that certified it.
+type PrimeFactor struct {
+	P         *big.Int
+	Method    string
+	Certified string
+}
+
+// Factorization is the result of Factorize. Unfactored holds composite parts no method could split.
+type Factorization struct {
+	N          *big.Int
+	Factors    []PrimeFactor
+	Unfactored []*big.Int
+}
+
+// Factorize splits n into primes: trial division by the primes below 2^16, then on every
+// composite part the Rabin-Miller test with the universal exponent r (when given), Brent's rho,
+// Pollard's p−1, Williams' p+1 and ECM, recursing on both parts of every split.
+func Factorize(n, r *big.Int) (*Factorization, error) {
+	if n.Cmp(big.NewInt(2)) < 0 {
+		return nil, fmt.Errorf("n must be at least 2")
+	}
+	res := &Factorization{N: n}
+	primes := helpers.SmallPrimes(pm1B2 + 1)
+
+	small, rest := helpers.TrialDivide(n, smallPrimes)
+	for _, p := range small {
+		res.Factors = append(res.Factors, PrimeFactor{P: p, Method: ByTrialDivision})
+	}
+
+	var split func(c *big.Int, by string)
+	split = func(c *big.Int, by string) {
+		if c.Cmp(big.NewInt(1)) == 0 {
+			return
+		}
+		if !BailliePSW(c).Composite {
+			res.Factors = append(res.Factors, PrimeFactor{P: c, Method: by})
+			return
+		}
+		d, method := findFactor(c, r, primes)
+		if d == nil {
+			log.Printf("[WARN] no method split the %d-bit composite %s", c.BitLen(), c)
+			res.Unfactored = append(res.Unfactored, c)
+			return
+		}
+		log.Printf("%s: %s = %s · %s", method, c, d, new(big.Int).Div(c, d))
+		split(d, method)
+		split(new(big.Int).Div(c, d), method)
+	}
+	split(rest, ByTrialDivision)
+
+	sort.Slice(res.Factors, func(i, j int) bool { return res.Factors[i].P.Cmp(res.Factors[j].P) < 0 })
+	for i := range res.Factors {
+		res.Factors[i].Certified = certify(res.Factors[i].P)
+	}
+	return res, nil
+}
+
+// findFactor tries the methods from the cheapest to the most general.
+func findFactor(c, r *big.Int, primes []int64) (*big.Int, string) {
+	if r != nil {
+		if d, _ := RabinMillerFunc(c, r); d != nil && d.Cmp(c) != 0 {
+			return d, ByRabinMiller
+		}
+	}
+	// a perfect power p^k is split by its root
+	for k := uint(2); k < uint(c.BitLen()); k++ {
+		root := integerRoot(c, k)
+		if new(big.Int).Exp(root, big.NewInt(int64(k)), nil).Cmp(c) == 0 {
+			return root, ByPerfectPower
+		}
+		if root.Cmp(big.NewInt(1<<16)) < 0 {
+			break // the trial division already removed such small roots
+		}
+	}
+	if d := helpers.BrentRho(c, factorRhoSteps); d != nil {
+		return d, ByRho
+	}
+	if d := helpers.PollardPM1(c, primes, pm1B1, pm1B2); d != nil {
+		return d, ByPM1
+	}
+	if d := helpers.WilliamsPP1(c, primes, pp1B1); d != nil {
+		return d, ByPP1
+	}
+	for _, stage := range ecmSchedule {
+		if d := helpers.ECM(c, primes, stage.B1, 100*stage.B1, stage.curves); d != nil {
+			return d, ByECM
+		}
+	}
+	return nil, ""
+}
+
+// integerRoot returns ⌊c^(1/k)⌋ by Newton's iteration.
+func integerRoot(c *big.Int, k uint) *big.Int {
+	x := new(big.Int).Lsh(big.NewInt(1), uint(c.BitLen())/k+1) // x ≥ root
+	km1 := big.NewInt(int64(k - 1))
+	bk := big.NewInt(int64(k))
+	for {
+		// y = ((k−1)·x + c / x^(k−1)) / k
+		y := new(big.Int).Exp(x, km1, nil)
+		y.Quo(c, y)
+		y.Add(y, new(big.Int).Mul(km1, x))
+		y.Quo(y, bk)
+		if y.Cmp(x) >= 0 {
+			return x
+		}
+		x = y
+	}
+}
+
+// certify names the test that proves p prime, or BPSW when no proof could be built.
+func certify(p *big.Int) string {
+	if p.Cmp(two64) < 0 {
+		if DeterministicMillerRabin(p, nil).Proven {
+			return "deterministic Rabin-Miller"
+		}
+		return "BPSW"
+	}
+	if _, err := Pocklington(p); err == nil {
+		return "Pocklington"
+	}
+	return "BPSW (probable)"
+}
+
+// Text formats the factorization as n = p1^e1 · p2 · ... and a table of the distinct primes.
+func (f *Factorization) Text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "n = %s\n", f.N)
+
+	var parts []string
+	groups := f.grouped()
+	for _, g := range groups {
+		part := g.P.String()
+		if g.e > 1 {
+			part += fmt.Sprintf("^%d", g.e)
+		}
+		parts = append(parts, part)
+	}
+	for _, c := range f.Unfactored {
+		parts = append(parts, "["+c.String()+"]")
+	}
+	fmt.Fprintf(&sb, "n = %s\n\n", strings.Join(parts, " · "))
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "dzielnik\twykładnik\tbity\tmetoda\tcertyfikat")
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", g.P, g.e, g.P.BitLen(), g.Method, g.Certified)
+	}
+	for _, c := range f.Unfactored {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", c, "-", c.BitLen(), "-", "złożona, nie rozłożona")
+	}
+	tw.Flush()
+	return sb.String()
+}
+
+// primePower is a distinct prime of the factorization with its exponent.
+type primePower struct {
+	PrimeFactor
+	e int
+}
+
+// grouped merges the repeated primes of the sorted Factors.
+func (f *Factorization) grouped() []primePower {
+	var groups []primePower
+	for _, pf := range f.Factors {
+		if n := len(groups); n > 0 && groups[n-1].P.Cmp(pf.P) == 0 {
+			groups[n-1].e++
+			continue
+		}
+		groups = append(groups, primePower{pf, 1})
+	}
+	return groups
+}
+
+// FactorTest reads n (and the optional universal exponent r) from the input file,
+// factors n completely and writes the factorization to FactorsFile.
+func FactorTest(EntryFile string) error {
+	log.Println("Factorization start")
+
+	lines, err := helpers.ReadData(EntryFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %v", err)
+	}
+	n, r, err := helpers.ParseInput(lines)
+	if err != nil {
+		return fmt.Errorf("failed to parse input: %v", err)
+	}
+	log.Println("n =", n)
+
+	f, err := Factorize(n, r)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(FactorsFile, []byte(f.Text()), 0644); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	if len(f.Unfactored) > 0 {
+		return fmt.Errorf("%d composite part(s) could not be factored, see %s", len(f.Unfactored), FactorsFile)
+	}
+	log.Printf("[INFO] %d prime factors written to %s", len(f.Factors), FactorsFile)
+	return nil
+}
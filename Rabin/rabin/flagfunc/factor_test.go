@@ -0,0 +1,78 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestFactorize factors numbers built for each stage and checks that the product of the prime
+// factors is n and that the expected method split off the largest prime.
+func TestFactorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		n, r   string
+		method string
+	}{
+		{"small", "38645974241574912", "", ByTrialDivision},                                               // 2^16 · 3^2 · 65521 · 1000003
+		{"perfect power", "12259964326927110850916040267783483001021757281745764351", "", ByPerfectPower}, // (2^61 − 1)^3
+		{"universal exponent", "170284021068950348759853766512050919011", "170284021068950348732600983390735768512", ByRabinMiller},
+		{"p-1 smooth", "20852234015841605521989518121128741849", "", ByPM1},
+		{"2^128 + 1", "340282366920938463463374607431768211457", "", ByECM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, _ := new(big.Int).SetString(tt.n, 10)
+			var r *big.Int
+			if tt.r != "" {
+				r, _ = new(big.Int).SetString(tt.r, 10)
+			}
+			f, err := Factorize(n, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(f.Unfactored) > 0 {
+				t.Fatalf("unfactored parts %v", f.Unfactored)
+			}
+			product := big.NewInt(1)
+			for _, pf := range f.Factors {
+				product.Mul(product, pf.P)
+				if BailliePSW(pf.P).Composite {
+					t.Errorf("factor %s is composite", pf.P)
+				}
+			}
+			if product.Cmp(n) != 0 {
+				t.Errorf("product of the factors is %s", product)
+			}
+			if last := f.Factors[len(f.Factors)-1]; last.Method != tt.method {
+				t.Errorf("%s split off by %s, expected %s", last.P, last.Method, tt.method)
+			}
+		})
+	}
+	if _, err := Factorize(big.NewInt(1), nil); err == nil {
+		t.Errorf("n = 1 accepted")
+	}
+}
+
+// TestIntegerRoot checks ⌊c^(1/k)⌋ at and around exact powers.
+func TestIntegerRoot(t *testing.T) {
+	m61 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+	cube := new(big.Int).Exp(m61, big.NewInt(3), nil)
+	tests := []struct {
+		c    *big.Int
+		k    uint
+		want *big.Int
+	}{
+		{big.NewInt(1000000), 2, big.NewInt(1000)},
+		{big.NewInt(999999), 2, big.NewInt(999)},
+		{big.NewInt(1 << 40), 5, big.NewInt(256)},
+		{cube, 3, m61},
+		{new(big.Int).Sub(cube, big.NewInt(1)), 3, new(big.Int).Sub(m61, big.NewInt(1))},
+		{new(big.Int).Add(cube, big.NewInt(1)), 3, m61},
+	}
+	for _, tt := range tests {
+		if got := integerRoot(tt.c, tt.k); got.Cmp(tt.want) != 0 {
+			t.Errorf("integerRoot(%s, %d) = %s, expected %s", tt.c, tt.k, got, tt.want)
+		}
+	}
+}
@@ -37,6 +37,114 @@ func ExecuteProgram(operation string) error {
 		}
 		log.Println("[INFO] Rabin-Miller test executed")
 		return nil
+
+	case "fac":
+		// Full factorization
+		err := FactorTest(EntryFile)
+		if err != nil {
+			return fmt.Errorf("failed during factorization: %v", err)
+		}
+		log.Println("[INFO] Factorization executed")
+		return nil
+
+	case "rsa":
+		// RSA key recovery from (n, e, d)
+		err := RSATest(EntryFile)
+		if err != nil {
+			return fmt.Errorf("failed during RSA key recovery: %v", err)
+		}
+		log.Println("[INFO] RSA key recovery executed")
+		return nil
+
+	case "gen":
+		// Prime generation
+		err := GenerateTest()
+		if err != nil {
+			return fmt.Errorf("failed during prime generation: %v", err)
+		}
+		log.Println("[INFO] Prime generation executed")
+		return nil
+
+	case "liars":
+		// Fermat and strong liars of n or of a range
+		err := LiarsTest(EntryFile)
+		if err != nil {
+			return fmt.Errorf("failed during liar exploration: %v", err)
+		}
+		log.Println("[INFO] Liar exploration executed")
+		return nil
+
+	case "carm":
+		// Carmichael numbers up to a bound
+		err := CarmichaelTest()
+		if err != nil {
+			return fmt.Errorf("failed during Carmichael enumeration: %v", err)
+		}
+		log.Println("[INFO] Carmichael enumeration executed")
+		return nil
+
+	case "rk":
+		// Rabin key generation
+		err := RabinKeysTest()
+		if err != nil {
+			return fmt.Errorf("failed during Rabin key generation: %v", err)
+		}
+		log.Println("[INFO] Rabin keys generated")
+		return nil
+
+	case "re":
+		// Rabin encryption
+		err := RabinEncryptTest()
+		if err != nil {
+			return fmt.Errorf("failed during Rabin encryption: %v", err)
+		}
+		log.Println("[INFO] Rabin encryption executed")
+		return nil
+
+	case "rd":
+		// Rabin decryption
+		err := RabinDecryptTest()
+		if err != nil {
+			return fmt.Errorf("failed during Rabin decryption: %v", err)
+		}
+		log.Println("[INFO] Rabin decryption executed")
+		return nil
+
+	case "rs":
+		// Rabin signature
+		err := RabinSignTest()
+		if err != nil {
+			return fmt.Errorf("failed during Rabin signature: %v", err)
+		}
+		log.Println("[INFO] Rabin signature executed")
+		return nil
+
+	case "rv":
+		// Rabin signature verification
+		err := RabinVerifyTest()
+		if err != nil {
+			return fmt.Errorf("failed during Rabin verification: %v", err)
+		}
+		log.Println("[INFO] Rabin verification executed")
+		return nil
+
+	case "cca":
+		// Chosen-ciphertext attack on the Rabin decryption
+		err := RabinAttackTest()
+		if err != nil {
+			return fmt.Errorf("failed during chosen-ciphertext attack: %v", err)
+		}
+		log.Println("[INFO] Chosen-ciphertext attack executed")
+		return nil
+
+	case "b":
+		// Batch Rabin-Miller test
+		err := BatchTest(BatchInput)
+		if err != nil {
+			return fmt.Errorf("failed during batch test: %v", err)
+		}
+		log.Println("[INFO] Rabin-Miller batch executed")
+		return nil
 	default:
 		return fmt.Errorf("unsupported operation: %s", operation)
 	}
@@ -48,13 +156,13 @@ func FermatTest(EntryFile string) error {
 	// Read data
 	lines, err := helpers.ReadData(EntryFile)
 	if err != nil {
-		log.Fatalf("Failed to read input file: %v", err)
+		return fmt.Errorf("failed to read input file: %v", err)
 	}
 
 	// Parse only 'n' from the input
 	n, _, err := helpers.ParseInput(lines)
 	if err != nil {
-		log.Fatalf("Failed to parse input: %v", err)
+		return fmt.Errorf("failed to parse input: %v", err)
 	}
 
 	log.Println("n =", n)
@@ -71,7 +179,7 @@ func FermatTest(EntryFile string) error {
 	// Save result to output file
 	err = os.WriteFile(OutputFile, []byte(result), 0644)
 	if err != nil {
-		log.Fatalf("Failed to write output: %v", err)
+		return fmt.Errorf("failed to write output: %v", err)
 	}
 
 	return nil
@@ -116,12 +224,12 @@ func RabinMillerTest(EntryFile string) error {
 	//Read data
 	lines, err := helpers.ReadData(EntryFile)
 	if err != nil {
-		log.Fatalf("Failed to read input file: %v", err)
+		return fmt.Errorf("failed to read input file: %v", err)
 	}
 
 	n, r, err := helpers.ParseInput(lines)
 	if err != nil {
-		log.Fatalf("Failed to parse input: %v", err)
+		return fmt.Errorf("failed to parse input: %v", err)
 	}
 
 	log.Println("n =", n)
@@ -131,8 +239,13 @@ func RabinMillerTest(EntryFile string) error {
 		log.Println("No exponent r provided.")
 	}
 
-	// Perform Rabin-Miller test
-	factor, composite := RabinMillerFunc(n, r)
+	// Perform Rabin-Miller test (or the test chosen with -method)
+	res, err := PrimalityTest(n, r)
+	if err != nil {
+		return err
+	}
+	log.Printf("Metoda: %s, sprawdzone podstawy: %d", res.Method, res.Iterations)
+	factor, composite := res.Factor, res.Composite
 	var result string
 	if factor != nil {
 		result = factor.String()
@@ -140,11 +253,21 @@ func RabinMillerTest(EntryFile string) error {
 	} else if composite {
 		log.Printf("Brak dzielnika, ale liczba jest na pewno złożona: composite = %v\n", composite)
 		result = "na pewno złożona"
+	} else if res.Proven {
+		log.Printf("Pierwszość udowodniona metodą %s\n", res.Method)
+		result = "na pewno pierwsza"
 	} else {
 		log.Printf("Liczba przeszła test: prawdopodobnie pierwsza (composite = %v)\n", composite)
 		result = "prawdopodobnie pierwsza"
 	}
 
+	if res.Certificate != nil {
+		if err := os.WriteFile(CertificateFile, []byte(res.Certificate.Text()), 0644); err != nil {
+			return fmt.Errorf("failed to write certificate: %v", err)
+		}
+		log.Printf("[INFO] Pocklington certificate saved to %s", CertificateFile)
+	}
+
 	// Write to file
 	err = os.WriteFile(OutputFile, []byte(result), 0644)
 	if err != nil {
@@ -155,6 +278,17 @@ func RabinMillerTest(EntryFile string) error {
 }
 
 
+// MillerResult opisuje wynik testu Rabina-Millera dla jednej liczby n.
+type MillerResult struct {
+	Factor     *big.Int // nietrywialny dzielnik n (nil, jeśli nie znaleziono)
+	Composite  bool     // n jest na pewno złożona
+	Witness    *big.Int // podstawa a, która wykazała złożoność (nil dla liczby pierwszej)
+	Iterations int      // liczba sprawdzonych podstaw
+	Method     string   // użyta metoda (-method)
+	Proven     bool     // pierwszość udowodniona, a nie tylko prawdopodobna
+	Certificate *Certificate // certyfikat Pocklingtona (tylko metoda pocklington)
+}
+
 // RabinMillerFunc wykonuje probabilistyczny test Rabina-Millera dla liczby n.
 // Jeśli podany jest wykładnik uniwersalny r, używa go zamiast n-1.
 // Zwraca:
@@ -162,103 +296,163 @@ func RabinMillerTest(EntryFile string) error {
 //   • composite == true  →  n jest na pewno złożona (bez ujawnionego dzielnika),
 //   • composite == false →  n jest prawdopodobnie pierwsza.
 func RabinMillerFunc(n, r *big.Int) (*big.Int, bool) {
-	one := big.NewInt(1)
+	res := RabinMillerDetailed(n, r)
+	return res.Factor, res.Composite
+}
+
+// RabinMillerDetailed działa jak RabinMillerFunc, ale zwraca też podstawę-świadka
+// i liczbę wykonanych iteracji (używane w trybie wsadowym).
+func RabinMillerDetailed(n, r *big.Int) MillerResult {
+	two := big.NewInt(2)
+	nMinusTwo := new(big.Int).Sub(n, two) // stała dla danego n – liczona raz
+	// losowe  a  z przedziału [2, n−2]
+	randomBase := func(int) (*big.Int, error) {
+		return helpers.CryptoRandBigIntBetween(two, nMinusTwo)
+	}
+	return rabinMillerBases(n, r, randomBase, Iterations)
+}
+
+// millerState przechowuje stałe dla danego n (n−1, rozkład wykładnika) oraz bufory
+// wielokrotnego użytku, tak aby pętla po podstawach nie alokowała nowych big.Int.
+type millerState struct {
+	n, nMinusOne *big.Int
+	m            *big.Int // nieparzysta część wykładnika
+	k            uint     // wykładnik = m · 2^k
+	b, prev      *big.Int // kolejne wyrazy b_j
+	sq, q, g     *big.Int // kwadrat przed redukcją, iloraz, gcd
+}
+
+func newMillerState(n, exponent *big.Int) *millerState {
+	// Rozkład: exponent = m · 2^k  (m nieparzyste, k ≥ 0) – jednym przesunięciem
+	k := exponent.TrailingZeroBits()
+	return &millerState{
+		n:         n,
+		nMinusOne: new(big.Int).Sub(n, big.NewInt(1)),
+		m:         new(big.Int).Rsh(exponent, k),
+		k:         k,
+		b:         new(big.Int),
+		prev:      new(big.Int),
+		sq:        new(big.Int),
+		q:         new(big.Int),
+		g:         new(big.Int),
+	}
+}
+
+// square ustawia b = prev² mod n bez alokacji (Mul + QuoRem na buforach).
+func (st *millerState) square() {
+	st.sq.Mul(st.prev, st.prev)
+	st.q.QuoRem(st.sq, st.n, st.b)
+}
+
+// factorFrom zwraca gcd(x ± 1, n), jeśli jest nietrywialnym dzielnikiem n.
+func (st *millerState) factorFrom(x *big.Int, delta int64) *big.Int {
+	st.sq.Add(x, big.NewInt(delta))
+	st.g.GCD(nil, nil, st.sq, st.n)
+	if st.g.Cmp(bigOne) != 0 && st.g.Cmp(st.n) != 0 {
+		return new(big.Int).Set(st.g)
+	}
+	return nil
+}
+
+var bigOne = big.NewInt(1)
+
+// rabinMillerBases wykonuje test Rabina-Millera z podstawami zwracanymi przez nextBase(i), i < count.
+// Podstawa nil kończy listę wcześniej (np. stały zbiór świadków większy niż n).
+// Stałe dla n są liczone raz, a potęgowanie a^m korzysta z big.Int.Exp, który dla
+// nieparzystego modułu używa mnożenia Montgomery'ego.
+func rabinMillerBases(n, r *big.Int, nextBase func(i int) (*big.Int, error), count int) MillerResult {
 	two := big.NewInt(2)
 
 	//------------------------------------------------------------
 	// 1. Szybkie przypadki brzegowe
 	//------------------------------------------------------------
-	if n.Cmp(two) == 0 {                 // n = 2  → liczba pierwsza
-		return nil, false
+	if n.Cmp(two) < 0 { // n < 2  → ani pierwsza, ani złożona; traktujemy jak złożoną
+		return MillerResult{Composite: true}
 	}
-	if new(big.Int).Mod(n, two).Sign() == 0 { // n parzysta > 2  → złożona, dzielnik 2
-		return big.NewInt(2), true
+	if n.Cmp(two) == 0 || n.Cmp(big.NewInt(3)) == 0 { // n = 2, 3  → liczba pierwsza
+		return MillerResult{}
+	}
+	if n.Bit(0) == 0 { // n parzysta > 2  → złożona, dzielnik 2
+		return MillerResult{Factor: big.NewInt(2), Composite: true}
 	}
 
 	//------------------------------------------------------------
 	// 2. Wyznacz wykładnik: r (lub n−1)  i rozłóż go na m·2^k
 	//------------------------------------------------------------
-	exponent := new(big.Int)
-	if r != nil {
-		exponent.Set(r)                  // używamy uniwersalnego r
-	} else {
-		exponent.Sub(n, one)             // klasycznie: n-1
-	}
-
-	// Rozkład: exponent = m · 2^k  (m nieparzyste, k ≥ 0)
-	m := new(big.Int).Set(exponent)
-	k := 0
-	for new(big.Int).Mod(m, two).Sign() == 0 { // dopóki m jest parzyste
-		m.Rsh(m, 1)                            // m /= 2
-		k++
+	exponent := r // używamy uniwersalnego r
+	if exponent == nil {
+		exponent = new(big.Int).Sub(n, bigOne) // klasycznie: n-1
 	}
+	st := newMillerState(n, exponent)
 
 	//------------------------------------------------------------
-	// 3. Iteracyjne testy z losowymi podstawami a
+	// 3. Iteracyjne testy z kolejnymi podstawami a
 	//------------------------------------------------------------
-	for i := 0; i < Iterations; i++ {
-		// losowe  a  z przedziału [2, n−2]
-		a, err := helpers.CryptoRandBigIntBetween(two, new(big.Int).Sub(n, two))
+	res := MillerResult{}
+	for i := 0; i < count; i++ {
+		a, err := nextBase(i)
 		if err != nil {
 			log.Printf("Błąd losowania a: %v", err)
-			return nil, true
+			res.Composite = true
+			return res
 		}
+		if a == nil {
+			break
+		}
+		res.Iterations = i + 1
 
 		// --- (a) Szybki test Euklidesa: gcd(a, n) > 1 → dzielnik ---
-		if d := new(big.Int).GCD(nil, nil, a, n); d.Cmp(one) != 0 {
-			if new(big.Int).Mod(n, d).Sign() == 0 { // upewnij się, że faktycznie dzieli n
-				return d, true
-			}
-			continue // jeśli to nie dzielnik – przejdź do kolejnej iteracji
+		if st.g.GCD(nil, nil, a, n); st.g.Cmp(bigOne) != 0 {
+			// gcd zawsze dzieli n; a ∈ [2, n−2], więc gcd < n
+			res.Factor, res.Composite, res.Witness = new(big.Int).Set(st.g), true, a
+			return res
 		}
 
 		// --- (b) Oblicz b0 = a^m  (mod n) ---
-		b := new(big.Int).Exp(a, m, n)
-		if b.Cmp(one) == 0 || b.Cmp(new(big.Int).Sub(n, one)) == 0 {
+		st.b.Exp(a, st.m, n)
+		if st.b.Cmp(bigOne) == 0 || st.b.Cmp(st.nMinusOne) == 0 {
 			continue // silny pseudopierwszy w tej iteracji → przechodzimy dalej
 		}
 
 		//--------------------------------------------------------
 		// (c) Powtarzaj: bj+1 = bj² (mod n)  i szukaj rozkładu
 		//--------------------------------------------------------
-		prev := new(big.Int).Set(b) // prev = bj
 		strong := false
-		for j := 0; j < k-1; j++ {
-			b.Exp(prev, two, n)     // b = prev²  →  bj+1
+		for j := uint(1); j < st.k; j++ {
+			st.b, st.prev = st.prev, st.b // prev = bj
+			st.square()                   // b = prev²  →  bj+1
 
 			// jeśli bj+1 = n−1  → iteracja zaliczona, przechodzimy do kolejnej podstawy
-			if b.Cmp(new(big.Int).Sub(n, one)) == 0 {
+			if st.b.Cmp(st.nMinusOne) == 0 {
 				strong = true
 				break
 			}
 
 			// jeśli bj+1 = 1  i  bj ≠ ±1  → mamy nietrywialny pierwiastek z 1
-			if b.Cmp(one) == 0 {
-				// gcd(bj−1, n)
-				if d := new(big.Int).GCD(nil, nil,
-					new(big.Int).Sub(prev, one), n); d.Cmp(one) != 0 && d.Cmp(n) != 0 &&
-					new(big.Int).Mod(n, d).Sign() == 0 {
-					return d, true
+			if st.b.Cmp(bigOne) == 0 {
+				// gcd(bj−1, n), potem gcd(bj+1, n)
+				if d := st.factorFrom(st.prev, -1); d != nil {
+					res.Factor, res.Composite, res.Witness = d, true, a
+					return res
 				}
-				// gcd(bj+1, n)
-				if d := new(big.Int).GCD(nil, nil,
-					new(big.Int).Add(prev, one), n); d.Cmp(one) != 0 && d.Cmp(n) != 0 &&
-					new(big.Int).Mod(n, d).Sign() == 0 {
-					return d, true
+				if d := st.factorFrom(st.prev, 1); d != nil {
+					res.Factor, res.Composite, res.Witness = d, true, a
+					return res
 				}
-				return nil, true // złożona, ale dzielnika nie udało się wyliczyć
+				res.Composite, res.Witness = true, a
+				return res // złożona, ale dzielnika nie udało się wyliczyć
 			}
-			prev.Set(b) // przechodzimy dalej: bj ← bj+1
 		}
 
 		// jeśli w ogóle nie napotkaliśmy wartości n−1  →  liczba złożona
 		if !strong {
-			return nil, true
+			res.Composite, res.Witness = true, a
+			return res
 		}
 	}
 
 	//------------------------------------------------------------
 	// 4. Po wszystkich iteracjach: brak świadków złożoności
 	//------------------------------------------------------------
-	return nil, false // liczba prawdopodobnie pierwsza
+	return res // liczba prawdopodobnie pierwsza
 }
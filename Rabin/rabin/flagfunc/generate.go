@@ -0,0 +1,496 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"rabin/helpers"
+)
+
+const (
+	PrimesFile           = "files/primes.txt"
+	PrimeCertificateFile = "files/prime_certificate.txt"
+	// ElgamalGroupFile holds p, g, q of a safe prime in the layout of the ElGamal module's elgamal.txt
+	ElgamalGroupFile = "files/elgamal_group.txt"
+
+	// sieveWindow is the number of terms of a progression sieved at once
+	sieveWindow = 1 << 12
+	// MinGenBits is the smallest size accepted by -gen (strong primes need MinStrongBits)
+	MinGenBits    = 16
+	MinStrongBits = 64
+	// provableBaseBits is the size below which a provable prime is proven by the deterministic test
+	provableBaseBits = 64
+	// countableBits is the largest size whose primes GeneratePrimes counts before searching:
+	// there are only 3030 primes of 16 bits, and a random search cannot collect all of them
+	countableBits = 24
+	// maxDuplicateRun is the number of repeated primes in a row after which GeneratePrimes gives up
+	maxDuplicateRun = 1000
+)
+
+// Kinds of generated primes (set from the -type flag)
+const (
+	KindRandom   = "random"   // random prime of exactly the given bit length
+	KindSafe     = "safe"     // p = 2q + 1 with q prime
+	KindStrong   = "strong"   // Gordon: p−1 has a large prime r, p+1 a large prime s, r−1 a large prime t
+	KindProvable = "provable" // Maurer / Shawe-Taylor: p = 2kq + 1 with a certificate chain
+)
+
+var (
+	// GenBits, GenKind and GenCount are set from the -bits, -type and -count flags
+	GenBits  = 512
+	GenKind  = KindRandom
+	GenCount = 1
+)
+
+// GeneratedPrime is one prime from GeneratePrimes with the parts that give it its structure.
+type GeneratedPrime struct {
+	P           *big.Int
+	Q           *big.Int     // safe: q = (p−1)/2
+	R, S, T     *big.Int     // strong: r | p−1, s | p+1, t | r−1
+	Certificate *Certificate // provable
+	Candidates  int          // candidates that survived the sieve and were tested
+}
+
+// errStopped is returned by the searches of workers that are no longer needed.
+var errStopped = fmt.Errorf("search stopped")
+
+// GeneratePrimes finds count primes of the given kind and exact bit length. The workers search
+// independently from random starting points; the first count distinct results are kept. Up to
+// countableBits, count may be at most half of the primes of the kind and size; the search fails
+// after maxDuplicateRun repeated primes in a row.
+func GeneratePrimes(kind string, bits, count, workers int) ([]*GeneratedPrime, error) {
+	switch kind {
+	case KindRandom, KindSafe, KindProvable:
+		if bits < MinGenBits {
+			return nil, fmt.Errorf("%s primes need at least %d bits", kind, MinGenBits)
+		}
+	case KindStrong:
+		if bits < MinStrongBits {
+			return nil, fmt.Errorf("strong primes need at least %d bits", MinStrongBits)
+		}
+	default:
+		return nil, fmt.Errorf("unknown prime type %q (use %s, %s, %s or %s)", kind, KindRandom, KindSafe, KindStrong, KindProvable)
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("count must be at least 1")
+	}
+	if bits <= countableBits && kind != KindStrong {
+		if available := countPrimes(kind, bits); int64(count) > available/2 {
+			return nil, fmt.Errorf("there are only %d %s primes of %d bits: a random search can find at most %d of them",
+				available, kind, bits, available/2)
+		}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	type result struct {
+		prime *GeneratedPrime
+		err   error
+	}
+	results := make(chan result)
+	stop := make(chan struct{})
+	for w := 0; w < workers; w++ {
+		go func() {
+			for {
+				g, err := generatePrime(kind, bits, stop)
+				if err == errStopped {
+					return
+				}
+				select {
+				case results <- result{g, err}:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	var primes []*GeneratedPrime
+	seen := map[string]bool{}
+	duplicates := 0
+	defer close(stop)
+	for len(primes) < count {
+		r := <-results
+		if r.err != nil {
+			return nil, r.err
+		}
+		key := r.prime.P.String()
+		if seen[key] {
+			if duplicates++; duplicates == maxDuplicateRun {
+				return nil, fmt.Errorf("only %d distinct %s primes of %d bits found before %d repeats in a row, %d requested",
+					len(primes), kind, bits, maxDuplicateRun, count)
+			}
+			continue
+		}
+		duplicates = 0
+		seen[key] = true
+		primes = append(primes, r.prime)
+	}
+	return primes, nil
+}
+
+// countPrimes returns the number of primes of the given kind and exact bit length, sieving the
+// odd numbers (for safe primes the pairs q, 2q + 1) with the small primes. Below 2^32 every
+// survivor is prime.
+func countPrimes(kind string, bits int) int64 {
+	one := big.NewInt(1)
+	start := new(big.Int).Lsh(one, uint(bits-1))
+	progs := []helpers.Progression{{Start: start.Add(start, one), Step: big.NewInt(2)}}
+	terms := int64(1) << (bits - 2)
+	if kind == KindSafe {
+		// q odd of bits−1 bits and p = 2q + 1
+		q0 := new(big.Int).Lsh(one, uint(bits-2))
+		q0.Add(q0, one)
+		progs = []helpers.Progression{
+			{Start: q0, Step: big.NewInt(2)},
+			{Start: new(big.Int).Add(new(big.Int).Lsh(q0, 1), one), Step: big.NewInt(4)},
+		}
+		terms >>= 1
+	}
+	sieve := helpers.NewSieve(smallPrimes, progs...)
+	var n int64
+	for offset := int64(0); offset < terms; offset += sieveWindow {
+		for i, ok := range sieve.Window(offset, sieveWindow) {
+			if ok && offset+int64(i) < terms {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// generatePrime runs one search of the given kind.
+func generatePrime(kind string, bits int, stop <-chan struct{}) (*GeneratedPrime, error) {
+	switch kind {
+	case KindSafe:
+		return safePrime(bits, stop)
+	case KindStrong:
+		return strongPrime(bits, stop)
+	case KindProvable:
+		return provablePrime(bits, stop)
+	default:
+		g := &GeneratedPrime{}
+		var err error
+		g.P, err = randomPrime(bits, stop, &g.Candidates)
+		return g, err
+	}
+}
+
+// searchProgression sieves the terms of the progressions window by window and calls accept
+// for every survivor below limit, in order. It returns the index of the first accepted term,
+// or −1 when the first progression reaches limit.
+func searchProgression(progs []helpers.Progression, limit *big.Int, stop <-chan struct{},
+	candidates *int, accept func(j int64) bool) (int64, error) {
+	// terms j < end are below limit
+	end := new(big.Int).Sub(limit, progs[0].Start)
+	end.Add(end, progs[0].Step).Sub(end, big.NewInt(1)).Div(end, progs[0].Step)
+	if !end.IsInt64() {
+		end.SetInt64(1 << 62)
+	}
+	sieve := helpers.NewSieve(smallPrimes, progs...)
+	for offset := int64(0); ; offset += sieveWindow {
+		select {
+		case <-stop:
+			return -1, errStopped
+		default:
+		}
+		for i, ok := range sieve.Window(offset, sieveWindow) {
+			j := offset + int64(i)
+			if j >= end.Int64() {
+				return -1, nil
+			}
+			if !ok {
+				continue
+			}
+			*candidates++
+			if accept(j) {
+				return j, nil
+			}
+		}
+	}
+}
+
+// isProbablePrime is the Rabin-Miller test used for the sieve survivors.
+func isProbablePrime(n *big.Int) bool {
+	_, composite := RabinMillerFunc(n, nil)
+	return !composite
+}
+
+// randomBits returns a random number of exactly the given bit length.
+func randomBits(bits int) (*big.Int, error) {
+	lo := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	return helpers.CryptoRandBigIntBetween(lo, new(big.Int).Sub(new(big.Int).Lsh(lo, 1), big.NewInt(1)))
+}
+
+// randomPrime returns a random prime of exactly the given bit length: odd numbers from a random
+// start are sieved, and the survivors tested with Rabin-Miller.
+func randomPrime(bits int, stop <-chan struct{}, candidates *int) (*big.Int, error) {
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	for {
+		start, err := randomBits(bits)
+		if err != nil {
+			return nil, err
+		}
+		start.SetBit(start, 0, 1)
+		prog := helpers.Progression{Start: start, Step: big.NewInt(2)}
+		j, err := searchProgression([]helpers.Progression{prog}, limit, stop, candidates, func(j int64) bool {
+			return isProbablePrime(prog.Term(j))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if j >= 0 {
+			return prog.Term(j), nil
+		}
+	}
+}
+
+// safePrime searches q of bits−1 bits such that q and p = 2q + 1 are prime. Both progressions
+// q = q0 + 2j and p = 2q0 + 1 + 4j are sieved; a base-2 Fermat test on p comes before Rabin-Miller.
+func safePrime(bits int, stop <-chan struct{}) (*GeneratedPrime, error) {
+	g := &GeneratedPrime{}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits-1)) // q < 2^(bits−1), so p has bits bits
+	two := big.NewInt(2)
+	for {
+		q0, err := randomBits(bits - 1)
+		if err != nil {
+			return nil, err
+		}
+		q0.SetBit(q0, 0, 1)
+		qs := helpers.Progression{Start: q0, Step: two}
+		ps := helpers.Progression{Start: new(big.Int).Add(new(big.Int).Lsh(q0, 1), big.NewInt(1)), Step: big.NewInt(4)}
+		j, err := searchProgression([]helpers.Progression{qs, ps}, limit, stop, &g.Candidates, func(j int64) bool {
+			p := ps.Term(j)
+			if new(big.Int).Exp(two, new(big.Int).Sub(p, big.NewInt(1)), p).Cmp(big.NewInt(1)) != 0 {
+				return false
+			}
+			return isProbablePrime(qs.Term(j)) && isProbablePrime(p)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if j >= 0 {
+			g.Q, g.P = qs.Term(j), ps.Term(j)
+			return g, nil
+		}
+	}
+}
+
+// strongPrime is Gordon's algorithm: random primes s and t, the first prime r = 2it + 1, then
+// p0 = 2(s^(r−2) mod r)·s − 1 (so p0 ≡ 1 mod r and p0 ≡ −1 mod s) and the first prime
+// p = p0 + 2jrs of the wanted length. r and s have about bits/2 − 8 bits, t 8 bits less.
+func strongPrime(bits int, stop <-chan struct{}) (*GeneratedPrime, error) {
+	half := bits/2 - 8
+	one := big.NewInt(1)
+	for {
+		g := &GeneratedPrime{}
+		var err error
+		if g.S, err = randomPrime(half, stop, &g.Candidates); err != nil {
+			return nil, err
+		}
+		if g.T, err = randomPrime(half-8, stop, &g.Candidates); err != nil {
+			return nil, err
+		}
+
+		// r = 2it + 1 from i = 2^7
+		step := new(big.Int).Lsh(g.T, 1)
+		rs := helpers.Progression{Start: new(big.Int).Add(new(big.Int).Lsh(step, 7), one), Step: step}
+		j, err := searchProgression([]helpers.Progression{rs}, new(big.Int).Lsh(one, uint(bits)), stop, &g.Candidates, func(j int64) bool {
+			return isProbablePrime(rs.Term(j))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if j < 0 {
+			continue
+		}
+		g.R = rs.Term(j)
+
+		p0 := new(big.Int).Exp(g.S, new(big.Int).Sub(g.R, big.NewInt(2)), g.R)
+		p0.Mul(p0, g.S).Lsh(p0, 1).Sub(p0, one)
+		rs2 := new(big.Int).Mul(g.R, g.S)
+		rs2.Lsh(rs2, 1)
+		// first term ≥ 2^(bits−1)
+		lo := new(big.Int).Lsh(one, uint(bits-1))
+		if p0.Cmp(lo) < 0 {
+			k := new(big.Int).Sub(lo, p0)
+			k.Add(k, rs2).Sub(k, one).Div(k, rs2)
+			p0.Add(p0, k.Mul(k, rs2))
+		}
+		ps := helpers.Progression{Start: p0, Step: rs2}
+		j, err = searchProgression([]helpers.Progression{ps}, new(big.Int).Lsh(one, uint(bits)), stop, &g.Candidates, func(j int64) bool {
+			return isProbablePrime(ps.Term(j))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if j >= 0 {
+			g.P = ps.Term(j)
+			return g, nil
+		}
+		// no prime of this length in the progression: start again with new s and t
+	}
+}
+
+// provablePrime is Maurer's recursive construction: a provable prime q of a random size between
+// ⌈bits/2⌉ + 1 and 3·bits/4, then p = 2kq + 1 for random k. Since q > √p, one base a with
+// a^(p−1) ≡ 1 and gcd(a^(2k) − 1, p) = 1 proves p prime (Pocklington). Below 64 bits the
+// deterministic Rabin-Miller test is the proof, as in the certificates of -method pocklington.
+func provablePrime(bits int, stop <-chan struct{}) (*GeneratedPrime, error) {
+	g := &GeneratedPrime{}
+	var err error
+	g.P, g.Certificate, err = maurer(bits, stop, &g.Candidates)
+	return g, err
+}
+
+func maurer(bits int, stop <-chan struct{}, candidates *int) (*big.Int, *Certificate, error) {
+	one := big.NewInt(1)
+	if bits <= provableBaseBits {
+		for {
+			p, err := randomPrime(bits, stop, candidates)
+			if err != nil {
+				return nil, nil, err
+			}
+			if DeterministicMillerRabin(p, nil).Proven {
+				return p, &Certificate{N: p}, nil
+			}
+		}
+	}
+
+	// q ≥ 2^⌈bits/2⌉, so q² > p
+	extra, err := helpers.CryptoRandBigIntBetween(big.NewInt(0), big.NewInt(int64(bits/4-2)))
+	if err != nil {
+		return nil, nil, err
+	}
+	q, qcert, err := maurer((bits+1)/2+1+int(extra.Int64()), stop, candidates)
+	if err != nil {
+		return nil, nil, err
+	}
+	if q.Cmp(two64) < 0 {
+		qcert = nil // proven by the deterministic test, as in Pocklington
+	}
+
+	// p = 2kq + 1 in [2^(bits−1), 2^bits): k from [2^(bits−2)/q, 2^(bits−1)/q)
+	limit := new(big.Int).Lsh(one, uint(bits))
+	kmin := new(big.Int).Lsh(one, uint(bits-2))
+	kmin.Div(kmin, q).Add(kmin, one)
+	kmax := new(big.Int).Lsh(one, uint(bits-1))
+	kmax.Div(kmax, q)
+	step := new(big.Int).Lsh(q, 1)
+	for {
+		k0, err := helpers.CryptoRandBigIntBetween(kmin, kmax)
+		if err != nil {
+			return nil, nil, err
+		}
+		ps := helpers.Progression{Start: new(big.Int).Add(new(big.Int).Mul(k0, step), one), Step: step}
+		var base int64
+		j, err := searchProgression([]helpers.Progression{ps}, limit, stop, candidates, func(j int64) bool {
+			p := ps.Term(j)
+			if !isProbablePrime(p) {
+				return false
+			}
+			base = pocklingtonBase(p, new(big.Int).Lsh(new(big.Int).Add(k0, big.NewInt(j)), 1))
+			return base > 0
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if j >= 0 {
+			p := ps.Term(j)
+			R := new(big.Int).Lsh(new(big.Int).Add(k0, big.NewInt(j)), 1)
+			cert := &Certificate{N: p, F: q, R: R, Steps: []CertificateStep{{Q: q, E: 1, A: base, Proof: qcert}}}
+			return p, cert, nil
+		}
+	}
+}
+
+// pocklingtonBase returns the first a ≤ maxPocklingtonBase with a^(p−1) ≡ 1 and
+// gcd(a^R − 1, p) = 1 for p − 1 = F·R, or 0 when there is none.
+func pocklingtonBase(p, R *big.Int) int64 {
+	one := big.NewInt(1)
+	pm1 := new(big.Int).Sub(p, one)
+	for a := int64(2); a <= maxPocklingtonBase; a++ {
+		ba := big.NewInt(a)
+		if new(big.Int).Exp(ba, pm1, p).Cmp(one) != 0 {
+			return 0
+		}
+		t := new(big.Int).Exp(ba, R, p)
+		g := new(big.Int).GCD(nil, nil, t.Sub(t, one), p)
+		if g.Cmp(one) == 0 {
+			return a
+		}
+		if g.Cmp(p) != 0 {
+			return 0
+		}
+	}
+	return 0
+}
+
+// Text formats one generated prime with the parts of its structure.
+func (g *GeneratedPrime) Text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "p = %s\n", g.P)
+	if g.Q != nil {
+		fmt.Fprintf(&sb, "q = (p-1)/2 = %s\n", g.Q)
+	}
+	if g.R != nil {
+		fmt.Fprintf(&sb, "r = %s (r | p-1)\n", g.R)
+		fmt.Fprintf(&sb, "s = %s (s | p+1)\n", g.S)
+		fmt.Fprintf(&sb, "t = %s (t | r-1)\n", g.T)
+	}
+	return sb.String()
+}
+
+// GenerateTest generates GenCount primes of type GenKind and writes them to PrimesFile, the
+// certificates of provable primes to PrimeCertificateFile and, for a safe prime, the group
+// p, g = 4, q to ElgamalGroupFile (4 = 2² is a square ≠ 1, so its order is q).
+func GenerateTest() error {
+	log.Printf("Prime generation start: %d %s prime(s) of %d bits, %d workers", GenCount, GenKind, GenBits, Workers)
+
+	primes, err := GeneratePrimes(GenKind, GenBits, GenCount, Workers)
+	if err != nil {
+		return err
+	}
+
+	var sb, certs strings.Builder
+	fmt.Fprintf(&sb, "# %d %s prime(s) of %d bits\n", len(primes), GenKind, GenBits)
+	for _, g := range primes {
+		if g.P.BitLen() != GenBits {
+			return fmt.Errorf("generated prime has %d bits instead of %d", g.P.BitLen(), GenBits)
+		}
+		sb.WriteString("\n" + g.Text())
+		log.Printf("[INFO] p = %s (%d candidates after the sieve)", g.P, g.Candidates)
+		if g.Certificate != nil {
+			if err := g.Certificate.Verify(); err != nil {
+				return fmt.Errorf("certificate of %s does not verify: %v", g.P, err)
+			}
+			certs.WriteString(g.Certificate.Text() + "\n")
+		}
+	}
+
+	if err := os.WriteFile(PrimesFile, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	log.Printf("[INFO] primes saved to %s", PrimesFile)
+
+	if certs.Len() > 0 {
+		if err := os.WriteFile(PrimeCertificateFile, []byte(certs.String()), 0644); err != nil {
+			return fmt.Errorf("failed to write certificates: %v", err)
+		}
+		log.Printf("[INFO] certificates verified and saved to %s", PrimeCertificateFile)
+	}
+
+	if GenKind == KindSafe {
+		group := fmt.Sprintf("%s\n4\n%s\n", primes[0].P, primes[0].Q)
+		if err := os.WriteFile(ElgamalGroupFile, []byte(group), 0644); err != nil {
+			return fmt.Errorf("failed to write ElGamal group: %v", err)
+		}
+		log.Printf("[INFO] p, g, q saved to %s (copy to elgamal.txt of the ElGamal module)", ElgamalGroupFile)
+	}
+	return nil
+}
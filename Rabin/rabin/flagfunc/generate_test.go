@@ -0,0 +1,90 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// bpsw is an independent check of the generated primes.
+func bpsw(n *big.Int) bool { return !BailliePSW(n).Composite }
+
+// TestGeneratePrimes checks every type with BPSW and the relations that define it:
+// q = (p−1)/2 for safe primes, r | p−1, s | p+1 and t | r−1 for strong primes, and a
+// certificate that verifies for provable primes.
+func TestGeneratePrimes(t *testing.T) {
+	one := big.NewInt(1)
+	tests := []struct {
+		kind        string
+		bits, count int
+		check       func(g *GeneratedPrime) bool
+	}{
+		{KindRandom, 256, 3, func(g *GeneratedPrime) bool { return true }},
+		{KindSafe, 256, 1, func(g *GeneratedPrime) bool {
+			q := new(big.Int).Rsh(g.P, 1)
+			return q.Cmp(g.Q) == 0 && bpsw(q)
+		}},
+		{KindStrong, 256, 1, func(g *GeneratedPrime) bool {
+			return new(big.Int).Mod(g.P, g.R).Cmp(one) == 0 &&
+				new(big.Int).Mod(new(big.Int).Add(g.P, one), g.S).Sign() == 0 &&
+				new(big.Int).Mod(g.R, g.T).Cmp(one) == 0 &&
+				bpsw(g.R) && bpsw(g.S) && bpsw(g.T)
+		}},
+		{KindProvable, 512, 1, func(g *GeneratedPrime) bool { return g.Certificate.Verify() == nil }},
+	}
+	for _, tt := range tests {
+		primes, err := GeneratePrimes(tt.kind, tt.bits, tt.count, Workers)
+		if err != nil {
+			t.Errorf("%s primes of %d bits: %v", tt.kind, tt.bits, err)
+			continue
+		}
+		if len(primes) != tt.count {
+			t.Errorf("%s: %d primes, expected %d", tt.kind, len(primes), tt.count)
+		}
+		for _, g := range primes {
+			if g.P.BitLen() != tt.bits || !bpsw(g.P) || !tt.check(g) {
+				t.Errorf("%s: %d-bit p = %s does not have the structure of its type", tt.kind, g.P.BitLen(), g.P)
+			}
+		}
+	}
+}
+
+// TestGenerateLimits rejects sizes below the minimum of each type, unknown types, and more
+// 16-bit primes than a random search can find (there are 3030).
+func TestGenerateLimits(t *testing.T) {
+	tests := []struct {
+		kind        string
+		bits, count int
+	}{
+		{KindRandom, MinGenBits - 1, 1},
+		{KindStrong, MinStrongBits - 1, 1},
+		{"weak", 512, 1},
+		{KindRandom, 256, 0},
+		{KindRandom, 16, 5000},
+		{KindRandom, 16, 1516},
+	}
+	for _, tt := range tests {
+		if _, err := GeneratePrimes(tt.kind, tt.bits, tt.count, Workers); err == nil {
+			t.Errorf("%d %s primes of %d bits accepted", tt.count, tt.kind, tt.bits)
+		}
+	}
+}
+
+// TestCountPrimes compares the sieve counts with π(2^b) − π(2^(b−1)) and the number of safe primes.
+func TestCountPrimes(t *testing.T) {
+	tests := []struct {
+		kind string
+		bits int
+		want int64
+	}{
+		{KindRandom, 16, 3030},
+		{KindRandom, 20, 38635},
+		{KindProvable, 16, 3030},
+		{KindSafe, 16, 193},
+	}
+	for _, tt := range tests {
+		if got := countPrimes(tt.kind, tt.bits); got != tt.want {
+			t.Errorf("countPrimes(%s, %d) = %d, expected %d", tt.kind, tt.bits, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"math/bits"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"rabin/helpers"
+)
+
+const (
+	LiarsFile      = "files/liars.txt"
+	CarmichaelFile = "files/carmichael.txt"
+	// bruteForceLimit is the largest n whose liars are also counted base by base, as a check of
+	// Monier's formulas: counting costs n exponentiations, so a whole -range of such n grows quadratically
+	bruteForceLimit = 1 << 12
+	// MaxCarmichaelLimit bounds -limit (the sieve keeps the smallest prime factor of every n)
+	MaxCarmichaelLimit = 1 << 24
+)
+
+var (
+	// LiarRange is the number or range a..b explored by -liars; empty means n from wejscie.txt
+	LiarRange = ""
+	// CarmichaelLimit is the bound of -carmichael (set from the -limit flag)
+	CarmichaelLimit = 100000
+)
+
+// liarStats counts the bases a in [1, n−1] that do not reveal the odd composite n:
+// Fermat liars (a^(n−1) ≡ 1) and strong liars (a^d ≡ 1 or a^(d·2^i) ≡ −1 for n−1 = d·2^s).
+type liarStats struct {
+	n          *big.Int
+	primes     []*big.Int // distinct prime factors
+	exps       []int
+	fermat     *big.Int
+	strong     *big.Int
+	counted    bool // counted base by base, not from the formulas
+	carmichael bool
+}
+
+// newLiarStats computes the counts from the factorization with Monier's formulas
+// F(n) = Π gcd(n−1, p−1) and S(n) = (1 + (2^(νk) − 1)/(2^k − 1))·Π gcd(d, p−1),
+// where k is the number of distinct primes p | n and ν the smallest power of 2 in the p−1.
+// For n ≤ bruteForceLimit every base is also tested and the counts must agree.
+func newLiarStats(n *big.Int, primes []*big.Int, exps []int) (*liarStats, error) {
+	one := big.NewInt(1)
+	nm1 := new(big.Int).Sub(n, one)
+	s := nm1.TrailingZeroBits()
+	d := new(big.Int).Rsh(nm1, s)
+
+	st := &liarStats{n: n, primes: primes, exps: exps, fermat: big.NewInt(1), strong: big.NewInt(1)}
+	nu := ^uint(0)
+	st.carmichael = len(primes) > 1
+	for i, p := range primes {
+		pm1 := new(big.Int).Sub(p, one)
+		st.fermat.Mul(st.fermat, new(big.Int).GCD(nil, nil, nm1, pm1))
+		st.strong.Mul(st.strong, new(big.Int).GCD(nil, nil, d, pm1))
+		if v := pm1.TrailingZeroBits(); v < nu {
+			nu = v
+		}
+		// Korselt: n squarefree and p−1 | n−1 for every p | n
+		if exps[i] > 1 || new(big.Int).Mod(nm1, pm1).Sign() != 0 {
+			st.carmichael = false
+		}
+	}
+	k := uint(len(primes))
+	// 1 + Σ_{i<ν} 2^(ik)
+	sum := big.NewInt(1)
+	for i := uint(0); i < nu; i++ {
+		sum.Add(sum, new(big.Int).Lsh(one, i*k))
+	}
+	st.strong.Mul(st.strong, sum)
+
+	if n.IsUint64() && n.Uint64() <= bruteForceLimit {
+		f, sl := countLiars(n.Uint64())
+		if f != st.fermat.Uint64() || sl != st.strong.Uint64() {
+			return nil, fmt.Errorf("n = %s: counted %d Fermat and %d strong liars, the formulas give %s and %s",
+				n, f, sl, st.fermat, st.strong)
+		}
+		st.counted = true
+	}
+	return st, nil
+}
+
+// countLiars tests every base a in [1, n−1] of the odd n.
+func countLiars(n uint64) (fermat, strong uint64) {
+	s := uint(bits.TrailingZeros64(n - 1))
+	d := (n - 1) >> s
+	for a := uint64(1); a < n; a++ {
+		x := powMod(a, d, n)
+		isStrong := x == 1 || x == n-1
+		for i := uint(0); i < s; i++ {
+			x = x * x % n
+			if x == n-1 && i+1 < s {
+				isStrong = true
+			}
+		}
+		if x == 1 {
+			fermat++
+			if isStrong {
+				strong++
+			}
+		}
+	}
+	return fermat, strong
+}
+
+// powMod returns a^e mod n for n < 2^32.
+func powMod(a, e, n uint64) uint64 {
+	r, a := uint64(1), a%n
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = r * a % n
+		}
+		a = a * a % n
+	}
+	return r
+}
+
+// phi returns Euler's φ(n) from the factorization.
+func (st *liarStats) phi() *big.Int {
+	phi := new(big.Int).Set(st.n)
+	for _, p := range st.primes {
+		phi.Div(phi, p).Mul(phi, new(big.Int).Sub(p, big.NewInt(1)))
+	}
+	return phi
+}
+
+// fermatFailure is the chance that one round of FermatFunc (a coprime base from [2, n−2])
+// passes the composite n: (F(n) − 2)/(φ(n) − 2), since 1 and n−1 are always liars.
+func (st *liarStats) fermatFailure() float64 {
+	return ratio(new(big.Int).Sub(st.fermat, big.NewInt(2)), new(big.Int).Sub(st.phi(), big.NewInt(2)))
+}
+
+// millerFailure is the chance that one round of RabinMillerFunc (any base from [2, n−2])
+// passes the composite n: (S(n) − 2)/(n − 3). Monier and Rabin show it is at most 1/4.
+func (st *liarStats) millerFailure() float64 {
+	return ratio(new(big.Int).Sub(st.strong, big.NewInt(2)), new(big.Int).Sub(st.n, big.NewInt(3)))
+}
+
+func ratio(a, b *big.Int) float64 {
+	if b.Sign() <= 0 {
+		return 0
+	}
+	f, _ := new(big.Rat).SetFrac(a, b).Float64()
+	return f
+}
+
+// factorText writes the factorization as p1^e1 · p2 · ...
+func (st *liarStats) factorText() string {
+	parts := make([]string, len(st.primes))
+	for i, p := range st.primes {
+		parts[i] = p.String()
+		if st.exps[i] > 1 {
+			parts[i] += fmt.Sprintf("^%d", st.exps[i])
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// distinctPrimes groups a sorted list of primes (with repetition).
+func distinctPrimes(factors []*big.Int) ([]*big.Int, []int) {
+	var primes []*big.Int
+	var exps []int
+	for _, p := range factors {
+		if len(primes) > 0 && primes[len(primes)-1].Cmp(p) == 0 {
+			exps[len(exps)-1]++
+			continue
+		}
+		primes = append(primes, p)
+		exps = append(exps, 1)
+	}
+	return primes, exps
+}
+
+// LiarsTest explores n from the input file, or every odd composite of LiarRange, and writes a
+// table of Fermat and strong liars with the failure chance of one round of each test.
+func LiarsTest(EntryFile string) error {
+	log.Println("Liar exploration start")
+
+	var stats []*liarStats
+	if LiarRange == "" {
+		lines, err := helpers.ReadData(EntryFile)
+		if err != nil {
+			return fmt.Errorf("failed to read input file: %v", err)
+		}
+		n, _, err := helpers.ParseInput(lines)
+		if err != nil {
+			return fmt.Errorf("failed to parse input: %v", err)
+		}
+		if n.Bit(0) == 0 || !BailliePSW(n).Composite {
+			return fmt.Errorf("n = %s must be an odd composite (every base is a liar of a prime)", n)
+		}
+		f, err := Factorize(n, nil)
+		if err != nil {
+			return err
+		}
+		if len(f.Unfactored) > 0 {
+			return fmt.Errorf("n = %s could not be factored, the liars cannot be counted", n)
+		}
+		factors := make([]*big.Int, len(f.Factors))
+		for i, pf := range f.Factors {
+			factors[i] = pf.P
+		}
+		primes, exps := distinctPrimes(factors)
+		st, err := newLiarStats(n, primes, exps)
+		if err != nil {
+			return err
+		}
+		stats = append(stats, st)
+	} else {
+		lo, hi, err := parseRange(LiarRange)
+		if err != nil {
+			return fmt.Errorf("invalid -range %q: %v", LiarRange, err)
+		}
+		if hi.BitLen() > 32 {
+			return fmt.Errorf("-range must end below 2^32")
+		}
+		for v := lo.Uint64() | 1; v <= hi.Uint64(); v += 2 {
+			n := new(big.Int).SetUint64(v)
+			factors, _ := helpers.TrialDivide(n, smallPrimes) // complete below 2^32
+			if v < 9 || len(factors) < 2 {
+				continue // 1 and primes
+			}
+			primes, exps := distinctPrimes(factors)
+			st, err := newLiarStats(n, primes, exps)
+			if err != nil {
+				return err
+			}
+			stats = append(stats, st)
+		}
+		if len(stats) == 0 {
+			return fmt.Errorf("no odd composite numbers in %s", LiarRange)
+		}
+	}
+
+	if err := writeLiarsReport(LiarsFile, stats); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	log.Printf("[INFO] %d number(s) written to %s", len(stats), LiarsFile)
+	return nil
+}
+
+// writeLiarsReport writes one row per number and a summary.
+func writeLiarsReport(path string, stats []*liarStats) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tw := tabwriter.NewWriter(file, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "n\trozkład\tcarmichael\tkłamcy Fermata\tkłamcy silni\tP(Fermat)\tP(Rabin-Miller)\tzliczanie")
+	var worst *liarStats
+	carmichael := 0
+	for _, st := range stats {
+		how := "wzór Moniera"
+		if st.counted {
+			how = "wszystkie podstawy"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%.4g\t%.4g\t%s\n", st.n, st.factorText(), yesNo(st.carmichael),
+			st.fermat, st.strong, st.fermatFailure(), st.millerFailure(), how)
+		if st.carmichael {
+			carmichael++
+		}
+		if worst == nil || st.millerFailure() > worst.millerFailure() {
+			worst = st
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(file)
+	fmt.Fprintf(file, "# odd composites: %d, Carmichael numbers: %d\n", len(stats), carmichael)
+	fmt.Fprintf(file, "# largest P(Rabin-Miller): %.4g for n = %s (the bound is 1/4)\n", worst.millerFailure(), worst.n)
+	log.Printf("[INFO] largest P(Rabin-Miller) = %.4g for n = %s", worst.millerFailure(), worst.n)
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "tak"
+	}
+	return "nie"
+}
+
+// CarmichaelNumbers returns the Carmichael numbers ≤ limit. A sieve keeps the smallest prime
+// factor of every n, and Korselt's criterion is checked on the factorization: n is composite,
+// squarefree and p−1 | n−1 for every prime p | n.
+func CarmichaelNumbers(limit int) ([]*liarStats, error) {
+	if limit < 1 || limit > MaxCarmichaelLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxCarmichaelLimit)
+	}
+	spf := make([]uint32, limit+1)
+	for i := 2; i <= limit; i++ {
+		if spf[i] == 0 {
+			for j := i; j <= limit; j += i {
+				if spf[j] == 0 {
+					spf[j] = uint32(i)
+				}
+			}
+		}
+	}
+
+	var found []*liarStats
+	for n := 3; n <= limit; n += 2 {
+		if spf[n] == uint32(n) {
+			continue // prime
+		}
+		korselt := true
+		var primes []*big.Int
+		var exps []int
+		for m := n; m > 1; {
+			p := int(spf[m])
+			if m/p%p == 0 || (n-1)%(p-1) != 0 {
+				korselt = false
+				break
+			}
+			primes = append(primes, big.NewInt(int64(p)))
+			exps = append(exps, 1)
+			m /= p
+		}
+		if !korselt {
+			continue
+		}
+		st, err := newLiarStats(big.NewInt(int64(n)), primes, exps)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, st)
+	}
+	return found, nil
+}
+
+// CarmichaelTest enumerates the Carmichael numbers up to CarmichaelLimit, runs FermatFunc and
+// RabinMillerFunc on each of them and writes how often each test is fooled.
+func CarmichaelTest() error {
+	log.Printf("Carmichael enumeration start: n <= %d", CarmichaelLimit)
+
+	found, err := CarmichaelNumbers(CarmichaelLimit)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(CarmichaelFile)
+	if err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	defer file.Close()
+
+	tw := tabwriter.NewWriter(file, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "n\trozkład\tkłamcy silni\tP(Fermat)\tP(Rabin-Miller)\tFermatFunc\tRabinMillerFunc")
+	fooledFermat, fooledMiller := 0, 0
+	for _, st := range found {
+		fermat := "na pewno złożona"
+		if FermatFunc(st.n) {
+			fermat = "prawdopodobnie pierwsza"
+			fooledFermat++
+		}
+		miller := "na pewno złożona"
+		if factor, composite := RabinMillerFunc(st.n, nil); factor != nil {
+			miller = factor.String()
+		} else if !composite {
+			miller = "prawdopodobnie pierwsza"
+			fooledMiller++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.4g\t%.4g\t%s\t%s\n", st.n, st.factorText(), st.strong,
+			st.fermatFailure(), st.millerFailure(), fermat, miller)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(file)
+	fmt.Fprintf(file, "# Carmichael numbers <= %d: %d\n", CarmichaelLimit, len(found))
+	fmt.Fprintf(file, "# FermatFunc (%d rounds) said prawdopodobnie pierwsza: %d/%d\n", Iterations, fooledFermat, len(found))
+	fmt.Fprintf(file, "# RabinMillerFunc (%d rounds) said prawdopodobnie pierwsza: %d/%d\n", Iterations, fooledMiller, len(found))
+	log.Printf("[INFO] %d Carmichael numbers <= %d saved to %s", len(found), CarmichaelLimit, CarmichaelFile)
+	log.Printf("[INFO] fooled: FermatFunc %d, RabinMillerFunc %d", fooledFermat, fooledMiller)
+	return nil
+}
@@ -0,0 +1,71 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"math/big"
+	"testing"
+
+	"rabin/helpers"
+)
+
+func liarStatsOf(t *testing.T, n int64) *liarStats {
+	t.Helper()
+	factors, _ := helpers.TrialDivide(big.NewInt(n), smallPrimes)
+	primes, exps := distinctPrimes(factors)
+	st, err := newLiarStats(big.NewInt(n), primes, exps)
+	if err != nil {
+		t.Fatalf("n = %d: %v", n, err)
+	}
+	return st
+}
+
+// TestLiarStats compares the liar counts with known values; 561 has the strong liars
+// 1, 50, 101, 103, 256, 305, 458, 460, 511, 560.
+func TestLiarStats(t *testing.T) {
+	tests := []struct {
+		n, fermat, strong int64
+	}{
+		{561, 320, 10},
+		{1729, 1296, 162},
+		{91, 36, 18},
+	}
+	for _, tt := range tests {
+		st := liarStatsOf(t, tt.n)
+		if st.fermat.Int64() != tt.fermat || st.strong.Int64() != tt.strong {
+			t.Errorf("n = %d: %s Fermat and %s strong liars, expected %d and %d", tt.n, st.fermat, st.strong, tt.fermat, tt.strong)
+		}
+	}
+}
+
+// TestMillerFailureBound checks (S(n) − 2)/(n − 3) ≤ 1/4 for every odd composite n < 5000.
+func TestMillerFailureBound(t *testing.T) {
+	for n := int64(9); n < 5000; n += 2 {
+		factors, _ := helpers.TrialDivide(big.NewInt(n), smallPrimes)
+		if len(factors) < 2 {
+			continue
+		}
+		if f := liarStatsOf(t, n).millerFailure(); f > 0.25 {
+			t.Errorf("n = %d: one Rabin-Miller round passes with chance %.4g > 1/4", n, f)
+		}
+	}
+}
+
+// TestCarmichaelNumbers checks the 16 Carmichael numbers up to 10^5, all of which fool FermatFunc.
+func TestCarmichaelNumbers(t *testing.T) {
+	want := []int64{561, 1105, 1729, 2465, 2821, 6601, 8911, 10585, 15841, 29341, 41041, 46657, 52633, 62745, 63973, 75361}
+	found, err := CarmichaelNumbers(100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != len(want) {
+		t.Fatalf("%d Carmichael numbers up to 100000, expected %d", len(found), len(want))
+	}
+	for i, st := range found {
+		if st.n.Int64() != want[i] {
+			t.Errorf("Carmichael number %d is %s, expected %d", i+1, st.n, want[i])
+		}
+		if !FermatFunc(st.n) {
+			t.Errorf("FermatFunc does not call %s prawdopodobnie pierwsza", st.n)
+		}
+	}
+}
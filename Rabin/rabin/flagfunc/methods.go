@@ -0,0 +1,178 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+)
+
+// Primality test methods (set from the -method flag)
+const (
+	MethodRandom        = "random"        // Rabin-Miller, Iterations random bases (default)
+	MethodDeterministic = "deterministic" // Rabin-Miller, fixed witness set
+	MethodBPSW          = "bpsw"          // Baillie-PSW: strong base 2 + strong Lucas
+	MethodPocklington   = "pocklington"   // BPSW, then a Pocklington certificate
+)
+
+var (
+	// Method is the primality test used by the Rabin-Miller and batch operations
+	Method = MethodRandom
+
+	// The first 12 primes are a witness set for every n < 2^64, the first 13 for every
+	// n < 3 317 044 064 679 887 385 961 981 (Sorenson, Webster 2015)
+	witnessBases    = []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41}
+	two64           = new(big.Int).Lsh(big.NewInt(1), 64)
+	witnessBound, _ = new(big.Int).SetString("3317044064679887385961981", 10)
+)
+
+// PrimalityTest runs the test selected by Method. The universal exponent r is only used by
+// the Rabin-Miller methods; the result records the method and whether primality is proven.
+func PrimalityTest(n, r *big.Int) (MillerResult, error) {
+	switch Method {
+	case MethodRandom:
+		res := RabinMillerDetailed(n, r)
+		res.Method = MethodRandom
+		return res, nil
+	case MethodDeterministic:
+		return DeterministicMillerRabin(n, r), nil
+	case MethodBPSW:
+		return BailliePSW(n), nil
+	case MethodPocklington:
+		res := BailliePSW(n)
+		if res.Composite || res.Proven {
+			return res, nil
+		}
+		cert, err := Pocklington(n)
+		if err != nil {
+			log.Printf("[WARN] n = %s: no Pocklington certificate (%v), result of BPSW", n, err)
+			return res, nil
+		}
+		res.Method, res.Proven, res.Certificate = MethodPocklington, true, cert
+		return res, nil
+	default:
+		return MillerResult{}, fmt.Errorf("unknown method %q (use %s, %s, %s or %s)",
+			Method, MethodRandom, MethodDeterministic, MethodBPSW, MethodPocklington)
+	}
+}
+
+// DeterministicMillerRabin tests n with the fixed witness set 2, 3, ..., 41, so the result is
+// reproducible and proven for n < 3.3·10^24 (in particular for every n < 2^64).
+func DeterministicMillerRabin(n, r *big.Int) MillerResult {
+	bases := witnessBases
+	if n.Cmp(two64) < 0 {
+		bases = bases[:12]
+	}
+	limit := new(big.Int).Sub(n, big.NewInt(1))
+	nextBase := func(i int) (*big.Int, error) {
+		a := big.NewInt(bases[i])
+		if a.Cmp(limit) >= 0 {
+			return nil, nil // the remaining bases are not in [2, n−2]
+		}
+		return a, nil
+	}
+	res := rabinMillerBases(n, r, nextBase, len(bases))
+	res.Method = MethodDeterministic
+	res.Proven = !res.Composite && r == nil && n.Cmp(witnessBound) < 0
+	return res
+}
+
+// BailliePSW is a strong Rabin-Miller test to base 2 followed by a strong Lucas test with
+// Selfridge's parameters. No composite passing both is known and none exists below 2^64.
+func BailliePSW(n *big.Int) MillerResult {
+	res := rabinMillerBases(n, nil, func(int) (*big.Int, error) {
+		if n.Cmp(big.NewInt(4)) <= 0 {
+			return nil, nil
+		}
+		return big.NewInt(2), nil
+	}, 1)
+	res.Method = MethodBPSW
+	if res.Composite {
+		return res
+	}
+	if n.Cmp(big.NewInt(4)) > 0 {
+		res.Iterations++
+		prime, factor := strongLucasTest(n)
+		if !prime {
+			res.Composite, res.Factor = true, factor
+			return res
+		}
+	}
+	res.Proven = n.Cmp(two64) < 0
+	return res
+}
+
+// strongLucasTest is the strong Lucas probable prime test for odd n > 4 with Selfridge's
+// method A: D is the first of 5, −7, 9, −11, ... with (D/n) = −1, P = 1, Q = (1 − D)/4.
+// With n + 1 = d·2^s, n passes when U_d ≡ 0 or V_(d·2^r) ≡ 0 (mod n) for some 0 ≤ r < s.
+// A factor of n is returned when the search for D meets gcd(D, n) > 1.
+func strongLucasTest(n *big.Int) (bool, *big.Int) {
+	// For a perfect square (D/n) is never −1
+	root := new(big.Int).Sqrt(n)
+	if new(big.Int).Mul(root, root).Cmp(n) == 0 {
+		return false, root
+	}
+
+	D := int64(5)
+	for {
+		j := big.Jacobi(big.NewInt(D), n)
+		if j == -1 {
+			break
+		}
+		if j == 0 {
+			g := new(big.Int).GCD(nil, nil, new(big.Int).Abs(big.NewInt(D)), n)
+			if g.Cmp(n) != 0 {
+				return false, g
+			}
+		}
+		if D > 0 {
+			D = -(D + 2)
+		} else {
+			D = -D + 2
+		}
+	}
+	bD := new(big.Int).Mod(big.NewInt(D), n)
+	Q := new(big.Int).Mod(big.NewInt((1-D)/4), n)
+
+	d := new(big.Int).Add(n, big.NewInt(1))
+	s := d.TrailingZeroBits()
+	d.Rsh(d, s)
+
+	// half returns x/2 mod n for odd n
+	half := func(x *big.Int) *big.Int {
+		if x.Bit(0) == 1 {
+			x.Add(x, n)
+		}
+		return x.Rsh(x, 1)
+	}
+
+	// Binary ladder from the top bit of d: U_1 = 1, V_1 = P = 1, Qk = Q^1
+	U, V, Qk := big.NewInt(1), big.NewInt(1), new(big.Int).Set(Q)
+	t := new(big.Int)
+	for i := d.BitLen() - 2; i >= 0; i-- {
+		// U_2k = U_k·V_k, V_2k = V_k² − 2Q^k
+		U.Mul(U, V).Mod(U, n)
+		V.Mul(V, V).Sub(V, t.Lsh(Qk, 1)).Mod(V, n)
+		Qk.Mul(Qk, Qk).Mod(Qk, n)
+		if d.Bit(i) == 1 {
+			// U_(k+1) = (P·U_k + V_k)/2, V_(k+1) = (D·U_k + P·V_k)/2
+			u := new(big.Int).Add(U, V)
+			v := new(big.Int).Mul(bD, U)
+			v.Add(v, V)
+			U, V = half(u.Mod(u, n)), half(v.Mod(v, n))
+			Qk.Mul(Qk, Q).Mod(Qk, n)
+		}
+	}
+
+	if U.Sign() == 0 || V.Sign() == 0 {
+		return true, nil
+	}
+	for r := uint(1); r < s; r++ {
+		V.Mul(V, V).Sub(V, t.Lsh(Qk, 1)).Mod(V, n)
+		if V.Sign() == 0 {
+			return true, nil
+		}
+		Qk.Mul(Qk, Qk).Mod(Qk, n)
+	}
+	return false, nil
+}
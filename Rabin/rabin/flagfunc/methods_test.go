@@ -0,0 +1,61 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestStrongLucasPseudoprimes runs the first strong Lucas pseudoprimes with Selfridge's parameters
+// through strongLucasTest: they pass it, and the base-2 step of BailliePSW rejects them.
+func TestStrongLucasPseudoprimes(t *testing.T) {
+	for _, n := range []int64{5459, 5777, 10877} {
+		if prime, factor := strongLucasTest(big.NewInt(n)); !prime {
+			t.Errorf("%d: strong Lucas test failed (factor %v), expected a pseudoprime", n, factor)
+		}
+		if !BailliePSW(big.NewInt(n)).Composite {
+			t.Errorf("%d: BailliePSW said prime", n)
+		}
+	}
+	for _, n := range []int64{5, 7, 5449, 10883} {
+		if prime, _ := strongLucasTest(big.NewInt(n)); !prime {
+			t.Errorf("prime %d fails the strong Lucas test", n)
+		}
+	}
+}
+
+// TestStrongPseudoprimesBase2 checks that strong pseudoprimes to base 2 are rejected: 2047 = 23·89,
+// 3215031751 fools the bases 2, 3, 5 and 7, and 3825123056546413051 every prime base up to 23.
+func TestStrongPseudoprimesBase2(t *testing.T) {
+	for _, text := range []string{"2047", "3215031751", "3825123056546413051"} {
+		n, _ := new(big.Int).SetString(text, 10)
+		if res := BailliePSW(n); !res.Composite || res.Proven {
+			t.Errorf("%s: BailliePSW gave composite = %t, proven = %t", n, res.Composite, res.Proven)
+		}
+		if res := DeterministicMillerRabin(n, nil); !res.Composite || res.Proven {
+			t.Errorf("%s: DeterministicMillerRabin gave composite = %t, proven = %t", n, res.Composite, res.Proven)
+		}
+	}
+}
+
+// TestProvenPrimes checks that both tests prove primes below 2^64 and only call larger ones probable.
+func TestProvenPrimes(t *testing.T) {
+	one := big.NewInt(1)
+	tests := []struct {
+		n      *big.Int
+		proven bool
+	}{
+		{big.NewInt(2), true},
+		{big.NewInt(3), true},
+		{new(big.Int).Sub(new(big.Int).Lsh(one, 61), one), true},            // 2^61 − 1
+		{new(big.Int).Sub(new(big.Int).Lsh(one, 64), big.NewInt(59)), true}, // largest prime below 2^64
+		{new(big.Int).Sub(new(big.Int).Lsh(one, 127), one), false},          // 2^127 − 1
+	}
+	for _, tt := range tests {
+		for _, res := range []MillerResult{BailliePSW(tt.n), DeterministicMillerRabin(tt.n, nil)} {
+			if res.Composite || res.Proven != tt.proven {
+				t.Errorf("%s (%s): composite = %t, proven = %t, expected proven = %t", tt.n, res.Method, res.Composite, res.Proven, tt.proven)
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"rabin/helpers"
+)
+
+const (
+	CertificateFile = "files/certificate.txt"
+	// pocklingtonRhoSteps limits Brent's rho while factoring n−1
+	pocklingtonRhoSteps = 1 << 18
+	// maxPocklingtonBase is the largest base a tried for one prime factor q
+	maxPocklingtonBase = 1000
+)
+
+// smallPrimes are used for trial division of n−1.
+var smallPrimes = helpers.SmallPrimes(1 << 16)
+
+// Certificate is a Pocklington primality certificate: n−1 = F·R with F > √n
+// fully factored, and for every prime q | F a base a with a^(n−1) ≡ 1 and gcd(a^((n−1)/q) − 1, n) = 1.
+// Then every prime factor of n is ≡ 1 (mod F), so n has none below √n and is prime.
+type Certificate struct {
+	N     *big.Int
+	F, R  *big.Int
+	Steps []CertificateStep // empty when n < 2^64 is proven by the deterministic Rabin-Miller test
+}
+
+// CertificateStep is the base for one prime factor q^e of F and, for q ≥ 2^64, the certificate of q.
+type CertificateStep struct {
+	Q     *big.Int
+	E     int
+	A     int64
+	Proof *Certificate
+}
+
+// Pocklington builds a certificate for n (which should already pass BPSW).
+// Prime factors of n−1 below 2^64 are proven with the deterministic witness set,
+// larger ones get their own certificate.
+func Pocklington(n *big.Int) (*Certificate, error) {
+	one := big.NewInt(1)
+	if n.Cmp(two64) < 0 {
+		if DeterministicMillerRabin(n, nil).Composite {
+			return nil, fmt.Errorf("%s is composite", n)
+		}
+		return &Certificate{N: n}, nil
+	}
+
+	nm1 := new(big.Int).Sub(n, one)
+	factors, R, err := factorHalf(nm1, n)
+	if err != nil {
+		return nil, err
+	}
+	F := new(big.Int).Div(nm1, R)
+	cert := &Certificate{N: n, F: F, R: R}
+
+	for _, q := range sortedPrimes(factors) {
+		step := CertificateStep{Q: q, E: factors[q.String()]}
+		exp := new(big.Int).Div(nm1, q)
+		for a := int64(2); ; a++ {
+			if a > maxPocklingtonBase {
+				return nil, fmt.Errorf("no base a <= %d for q = %s", maxPocklingtonBase, q)
+			}
+			ba := big.NewInt(a)
+			if new(big.Int).Exp(ba, nm1, n).Cmp(one) != 0 {
+				return nil, fmt.Errorf("%s is composite (Fermat witness %d)", n, a)
+			}
+			t := new(big.Int).Exp(ba, exp, n)
+			g := new(big.Int).GCD(nil, nil, t.Sub(t, one), n)
+			if g.Cmp(one) == 0 {
+				step.A = a
+				break
+			}
+			if g.Cmp(n) != 0 {
+				return nil, fmt.Errorf("%s is composite (factor %s)", n, g)
+			}
+		}
+		if q.Cmp(two64) >= 0 {
+			if step.Proof, err = Pocklington(q); err != nil {
+				return nil, fmt.Errorf("factor %s of n-1: %v", q, err)
+			}
+		}
+		cert.Steps = append(cert.Steps, step)
+	}
+	return cert, nil
+}
+
+// factorHalf splits m = n−1 into fully factored F and an unfactored rest R until F² > n.
+// It returns the primes of F with their exponents (keyed by the decimal prime) and R.
+func factorHalf(m, n *big.Int) (map[string]int, *big.Int, error) {
+	factors := map[string]int{}
+	small, rest := helpers.TrialDivide(m, smallPrimes)
+	for _, q := range small {
+		factors[q.String()]++
+	}
+	F := new(big.Int).Div(m, rest)
+
+	// Composite parts still to be split; a prime part is removed from rest with its full power
+	pending := []*big.Int{new(big.Int).Set(rest)}
+	for len(pending) > 0 && new(big.Int).Mul(F, F).Cmp(n) <= 0 {
+		c := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if c.Cmp(big.NewInt(1)) == 0 {
+			continue
+		}
+		if !BailliePSW(c).Composite {
+			for new(big.Int).Mod(rest, c).Sign() == 0 {
+				rest.Div(rest, c)
+				F.Mul(F, c)
+				factors[c.String()]++
+			}
+			continue
+		}
+		d := helpers.BrentRho(c, pocklingtonRhoSteps)
+		if d == nil {
+			continue // this part stays in R
+		}
+		pending = append(pending, d, new(big.Int).Div(c, d))
+	}
+	if new(big.Int).Mul(F, F).Cmp(n) <= 0 {
+		return nil, nil, fmt.Errorf("n-1 could not be factored far enough: F has %d bits, needs more than %d",
+			F.BitLen(), n.BitLen()/2)
+	}
+	return factors, rest, nil
+}
+
+func sortedPrimes(factors map[string]int) []*big.Int {
+	primes := make([]*big.Int, 0, len(factors))
+	for s := range factors {
+		q, _ := new(big.Int).SetString(s, 10)
+		primes = append(primes, q)
+	}
+	sort.Slice(primes, func(i, j int) bool { return primes[i].Cmp(primes[j]) < 0 })
+	return primes
+}
+
+// Verify checks the certificate: n−1 = F·R with F² > n, F the product of the steps' q^e, and for
+// every step a^(n−1) ≡ 1 and gcd(a^((n−1)/q) − 1, n) = 1, with q itself proven (recursively).
+func (c *Certificate) Verify() error {
+	one := big.NewInt(1)
+	if len(c.Steps) == 0 {
+		if c.N.Cmp(two64) < 0 && DeterministicMillerRabin(c.N, nil).Proven {
+			return nil
+		}
+		return fmt.Errorf("%s: no steps and not a prime below 2^64", c.N)
+	}
+	nm1 := new(big.Int).Sub(c.N, one)
+	if new(big.Int).Mul(c.F, c.R).Cmp(nm1) != 0 {
+		return fmt.Errorf("%s: F·R != n-1", c.N)
+	}
+	if new(big.Int).Mul(c.F, c.F).Cmp(c.N) <= 0 {
+		return fmt.Errorf("%s: F is not above sqrt(n)", c.N)
+	}
+	F := big.NewInt(1)
+	for _, s := range c.Steps {
+		F.Mul(F, new(big.Int).Exp(s.Q, big.NewInt(int64(s.E)), nil))
+		ba := big.NewInt(s.A)
+		if new(big.Int).Exp(ba, nm1, c.N).Cmp(one) != 0 {
+			return fmt.Errorf("%s: a^(n-1) != 1 for a = %d", c.N, s.A)
+		}
+		t := new(big.Int).Exp(ba, new(big.Int).Div(nm1, s.Q), c.N)
+		if new(big.Int).GCD(nil, nil, t.Sub(t, one), c.N).Cmp(one) != 0 {
+			return fmt.Errorf("%s: gcd(a^((n-1)/q) - 1, n) != 1 for q = %s", c.N, s.Q)
+		}
+		if s.Proof != nil {
+			if s.Proof.N.Cmp(s.Q) != 0 {
+				return fmt.Errorf("%s: the certificate of q = %s is for %s", c.N, s.Q, s.Proof.N)
+			}
+			if err := s.Proof.Verify(); err != nil {
+				return err
+			}
+		} else if !(s.Q.Cmp(two64) < 0 && DeterministicMillerRabin(s.Q, nil).Proven) {
+			return fmt.Errorf("%s: q = %s has no certificate", c.N, s.Q)
+		}
+	}
+	if F.Cmp(c.F) != 0 {
+		return fmt.Errorf("%s: F is not the product of the q^e", c.N)
+	}
+	return nil
+}
+
+// Text formats the certificate; certificates of large factors follow their parent.
+func (c *Certificate) Text() string {
+	var sb strings.Builder
+	c.write(&sb, "")
+	return sb.String()
+}
+
+func (c *Certificate) write(sb *strings.Builder, indent string) {
+	if len(c.Steps) == 0 {
+		fmt.Fprintf(sb, "%sn = %s < 2^64: deterministic Rabin-Miller, bases 2..37\n", indent, c.N)
+		return
+	}
+	fmt.Fprintf(sb, "%sn = %s\n", indent, c.N)
+	fmt.Fprintf(sb, "%sn-1 = F·R, F = %s (%d bits), R = %s\n", indent, c.F, c.F.BitLen(), c.R)
+	for _, s := range c.Steps {
+		proof := "prime < 2^64 (deterministic Rabin-Miller)"
+		if s.Proof != nil {
+			proof = "certificate below"
+		}
+		fmt.Fprintf(sb, "%s  q = %s^%d, a = %d: a^(n-1) = 1, gcd(a^((n-1)/q) - 1, n) = 1; q %s\n", indent, s.Q, s.E, s.A, proof)
+	}
+	for _, s := range c.Steps {
+		if s.Proof != nil {
+			s.Proof.write(sb, indent+"    ")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestPocklingtonCertificate builds the certificate of 2^127 − 1, verifies it, and checks that
+// Verify rejects it once a base or F is changed.
+func TestPocklingtonCertificate(t *testing.T) {
+	one := big.NewInt(1)
+	n := new(big.Int).Sub(new(big.Int).Lsh(one, 127), one)
+	cert, err := Pocklington(n)
+	if err != nil {
+		t.Fatalf("no certificate for 2^127 - 1: %v", err)
+	}
+	if cert.N.Cmp(n) != 0 || len(cert.Steps) == 0 {
+		t.Fatalf("certificate of %s with %d steps", cert.N, len(cert.Steps))
+	}
+	if err := cert.Verify(); err != nil {
+		t.Errorf("certificate of 2^127 - 1 does not verify: %v", err)
+	}
+
+	a := cert.Steps[0].A
+	cert.Steps[0].A = 1
+	if cert.Verify() == nil {
+		t.Errorf("certificate with base 1 verified")
+	}
+	cert.Steps[0].A = a
+	cert.F = new(big.Int).Add(cert.F, one)
+	if cert.Verify() == nil {
+		t.Errorf("certificate with a wrong F verified")
+	}
+}
+
+// TestPocklingtonComposite checks that composites get no certificate: 2^128 + 1 and a
+// Carmichael number below 2^64.
+func TestPocklingtonComposite(t *testing.T) {
+	one := big.NewInt(1)
+	for _, n := range []*big.Int{new(big.Int).Add(new(big.Int).Lsh(one, 128), one), big.NewInt(561)} {
+		if _, err := Pocklington(n); err == nil {
+			t.Errorf("certificate built for the composite %s", n)
+		}
+	}
+	if (&Certificate{N: big.NewInt(561)}).Verify() == nil {
+		t.Errorf("empty certificate of 561 verified")
+	}
+}
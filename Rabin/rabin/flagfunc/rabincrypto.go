@@ -0,0 +1,567 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"rabin/helpers"
+)
+
+const (
+	RabinPublicKeyFile  = "files/rabin_public.txt"
+	RabinPrivateKeyFile = "files/rabin_private.txt"
+	RabinPlainFile      = "files/rabin_plain.txt"
+	RabinCryptoFile     = "files/rabin_crypto.txt"
+	RabinDecryptedFile  = "files/rabin_decrypt.txt"
+	RabinSignatureFile  = "files/rabin_signature.txt"
+	RabinVerifyFile     = "files/rabin_verify.txt"
+	RabinAttackFile     = "files/rabin_cca.txt"
+
+	// MinRabinBits is the smallest n accepted by -rabin-k (a block must hold the redundancy)
+	MinRabinBits = 128
+	// redundancyBits low bits of every block are repeated at its end
+	redundancyBits = 64
+	// rabinSaltBytes is the size of the random salt of a signature
+	rabinSaltBytes = 16
+	// maxOracleQueries bounds the chosen-ciphertext attack. Each query splits n with
+	// probability 1/2, so all of them fail with probability 2^-64.
+	maxOracleQueries = 64
+)
+
+// RabinKey is a Rabin key n = p·q with p ≡ q ≡ 3 (mod 4). P, Q, Yp and Yq (yp·p + yq·q = 1)
+// are nil in a public key.
+type RabinKey struct {
+	N      *big.Int
+	P, Q   *big.Int
+	Yp, Yq *big.Int
+}
+
+// newRabinKey checks the primes and computes n and the CRT coefficients.
+func newRabinKey(p, q *big.Int) (*RabinKey, error) {
+	three := big.NewInt(3)
+	for _, x := range []*big.Int{p, q} {
+		if new(big.Int).And(x, three).Cmp(three) != 0 {
+			return nil, fmt.Errorf("%s is not ≡ 3 (mod 4)", x)
+		}
+	}
+	if p.Cmp(q) == 0 {
+		return nil, fmt.Errorf("p and q must be different")
+	}
+	k := &RabinKey{N: new(big.Int).Mul(p, q), P: p, Q: q, Yp: new(big.Int), Yq: new(big.Int)}
+	new(big.Int).GCD(k.Yp, k.Yq, p, q)
+	return k, nil
+}
+
+// blumPrime returns a random prime p ≡ 3 (mod 4) of exactly the given bit length. The two top
+// bits are set, so the product of two such primes has exactly the sum of their lengths.
+func blumPrime(bits int, candidates *int) (*big.Int, error) {
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	for {
+		start, err := randomBits(bits)
+		if err != nil {
+			return nil, err
+		}
+		start.SetBit(start, bits-2, 1).SetBit(start, 1, 1).SetBit(start, 0, 1)
+		prog := helpers.Progression{Start: start, Step: big.NewInt(4)}
+		j, err := searchProgression([]helpers.Progression{prog}, limit, nil, candidates, func(j int64) bool {
+			return isProbablePrime(prog.Term(j))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if j >= 0 {
+			return prog.Term(j), nil
+		}
+	}
+}
+
+// GenerateRabinKey returns a key with n of exactly the given bit length.
+func GenerateRabinKey(bits int) (*RabinKey, error) {
+	if bits < MinRabinBits {
+		return nil, fmt.Errorf("n must have at least %d bits", MinRabinBits)
+	}
+	var candidates int
+	p, err := blumPrime(bits/2, &candidates)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		q, err := blumPrime(bits-bits/2, &candidates)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		if p.Cmp(q) < 0 {
+			p, q = q, p
+		}
+		return newRabinKey(p, q)
+	}
+}
+
+// SquareRoots returns the four square roots of c modulo n. Since p ≡ 3 (mod 4), the roots
+// modulo p are ±c^((p+1)/4); they are combined with r = yp·p·mq ± yq·q·mp (mod n).
+func (k *RabinKey) SquareRoots(c *big.Int) ([4]*big.Int, error) {
+	var roots [4]*big.Int
+	mp, err := sqrtBlum(c, k.P)
+	if err != nil {
+		return roots, err
+	}
+	mq, err := sqrtBlum(c, k.Q)
+	if err != nil {
+		return roots, err
+	}
+	a := new(big.Int).Mul(k.Yp, k.P)
+	a.Mul(a, mq)
+	b := new(big.Int).Mul(k.Yq, k.Q)
+	b.Mul(b, mp)
+	r := new(big.Int).Add(a, b)
+	r.Mod(r, k.N)
+	s := new(big.Int).Sub(a, b)
+	s.Mod(s, k.N)
+	neg := func(x *big.Int) *big.Int {
+		y := new(big.Int).Sub(k.N, x)
+		return y.Mod(y, k.N)
+	}
+	roots[0], roots[1], roots[2], roots[3] = r, neg(r), s, neg(s)
+	return roots, nil
+}
+
+// sqrtBlum returns c^((p+1)/4) mod p, a square root of c modulo p ≡ 3 (mod 4).
+func sqrtBlum(c, p *big.Int) (*big.Int, error) {
+	e := new(big.Int).Add(p, big.NewInt(1))
+	e.Rsh(e, 2)
+	m := new(big.Int).Exp(c, e, p)
+	if new(big.Int).Exp(m, big.NewInt(2), p).Cmp(new(big.Int).Mod(c, p)) != 0 {
+		return nil, fmt.Errorf("c is not a square modulo p")
+	}
+	return m, nil
+}
+
+// blockBytes is the number of message bytes in one block. The block 0x01 ‖ chunk followed by
+// a copy of its low redundancyBits bits has at most (bitlen(n) − 1) bits, so it is below n.
+func (k *RabinKey) blockBytes() int {
+	return (k.N.BitLen()-1)/8 - redundancyBits/8 - 1
+}
+
+// encodeBlock returns x = m·2^64 + (m mod 2^64) with m = 0x01 ‖ chunk. The leading 0x01 keeps
+// the zero bytes at the start of the chunk.
+func encodeBlock(chunk []byte) *big.Int {
+	m := new(big.Int).SetBytes(append([]byte{1}, chunk...))
+	low := new(big.Int).SetBit(new(big.Int), redundancyBits, 1)
+	low.Sub(low, big.NewInt(1)).And(low, m)
+	return m.Lsh(m, redundancyBits).Or(m, low)
+}
+
+// decodeBlock returns the chunk of x when its last redundancyBits bits repeat the bits above them.
+func decodeBlock(x *big.Int, size int) ([]byte, bool) {
+	mask := new(big.Int).SetBit(new(big.Int), redundancyBits, 1)
+	mask.Sub(mask, big.NewInt(1))
+	m := new(big.Int).Rsh(x, redundancyBits)
+	if new(big.Int).And(x, mask).Cmp(new(big.Int).And(m, mask)) != 0 {
+		return nil, false
+	}
+	b := m.Bytes()
+	if len(b) == 0 || b[0] != 1 || len(b)-1 > size {
+		return nil, false
+	}
+	return b[1:], true
+}
+
+// Encrypt splits the message into blocks and squares every encoded block modulo n.
+func (k *RabinKey) Encrypt(msg []byte) []*big.Int {
+	size := k.blockBytes()
+	var blocks []*big.Int
+	for i := 0; i == 0 || i < len(msg); i += size {
+		x := encodeBlock(msg[i:min(i+size, len(msg))])
+		blocks = append(blocks, x.Exp(x, big.NewInt(2), k.N))
+	}
+	return blocks
+}
+
+// decryptBlock returns the only square root of c that carries the redundancy.
+func (k *RabinKey) decryptBlock(c *big.Int) (*big.Int, []byte, error) {
+	if c.Sign() < 0 || c.Cmp(k.N) >= 0 {
+		return nil, nil, fmt.Errorf("ciphertext is not in the range 0 <= c < n")
+	}
+	roots, err := k.SquareRoots(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	var x *big.Int
+	var chunk []byte
+	found := 0
+	for i, r := range roots {
+		if duplicateRoot(roots[:i], r) {
+			continue // c shares a prime with n, so some roots coincide
+		}
+		if b, ok := decodeBlock(r, k.blockBytes()); ok {
+			x, chunk = r, b
+			found++
+		}
+	}
+	if found != 1 {
+		return nil, nil, fmt.Errorf("%d of the square roots carry the redundancy", found)
+	}
+	return x, chunk, nil
+}
+
+// duplicateRoot reports whether r is one of the roots before it.
+func duplicateRoot(before []*big.Int, r *big.Int) bool {
+	for _, x := range before {
+		if x.Cmp(r) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Decrypt computes the four square roots of every block and keeps the one with the redundancy.
+func (k *RabinKey) Decrypt(blocks []*big.Int) ([]byte, error) {
+	var msg []byte
+	for i, c := range blocks {
+		_, chunk, err := k.decryptBlock(c)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %v", i+1, err)
+		}
+		msg = append(msg, chunk...)
+	}
+	return msg, nil
+}
+
+// rabinHash maps the salted message to a number below n: SHA-256(salt ‖ i ‖ msg) for
+// i = 0, 1, ... concatenated to the byte length of n and reduced modulo n.
+func rabinHash(n *big.Int, msg, salt []byte) *big.Int {
+	var out []byte
+	var counter [4]byte
+	for i := uint32(0); len(out) < (n.BitLen()+7)/8; i++ {
+		h := sha256.New()
+		h.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], i)
+		h.Write(counter[:])
+		h.Write(msg)
+		out = h.Sum(out)
+	}
+	x := new(big.Int).SetBytes(out[:(n.BitLen()+7)/8])
+	return x.Mod(x, n)
+}
+
+// Sign draws salts until H(salt, msg) is a square modulo p and q (a quarter of them are), and
+// returns the salt with the smallest square root s of the hash. tries is the number of salts.
+func (k *RabinKey) Sign(msg []byte) (salt []byte, s *big.Int, tries int, err error) {
+	salt = make([]byte, rabinSaltBytes)
+	for {
+		tries++
+		if _, err := rand.Read(salt); err != nil {
+			return nil, nil, tries, fmt.Errorf("crypto/rand failed: %v", err)
+		}
+		h := rabinHash(k.N, msg, salt)
+		if big.Jacobi(h, k.P) != 1 || big.Jacobi(h, k.Q) != 1 {
+			continue
+		}
+		roots, err := k.SquareRoots(h)
+		if err != nil {
+			return nil, nil, tries, err
+		}
+		s = roots[0]
+		for _, r := range roots[1:] {
+			if r.Cmp(s) < 0 {
+				s = r
+			}
+		}
+		return salt, s, tries, nil
+	}
+}
+
+// VerifyRabin checks s² ≡ H(salt, msg) (mod n); only n is needed.
+func VerifyRabin(n *big.Int, msg, salt []byte, s *big.Int) bool {
+	if s.Sign() <= 0 || s.Cmp(n) >= 0 {
+		return false
+	}
+	return new(big.Int).Exp(s, big.NewInt(2), n).Cmp(rabinHash(n, msg, salt)) == 0
+}
+
+// ChosenCiphertextAttack factors n with a decryption oracle. The attacker squares a random m and
+// asks for a square root of c = m²; the oracle cannot know which of ±m, ±m' was squared, so with
+// probability 1/2 it answers ±m' and gcd(m − m', n) is a prime. queries counts all questions,
+// refused counts the answers that were errors.
+func ChosenCiphertextAttack(n *big.Int, oracle func(c *big.Int) (*big.Int, error)) (p *big.Int, queries, refused int, err error) {
+	one := big.NewInt(1)
+	for queries < maxOracleQueries {
+		m, err := helpers.CryptoRandBigIntBetween(big.NewInt(2), new(big.Int).Sub(n, big.NewInt(2)))
+		if err != nil {
+			return nil, queries, refused, err
+		}
+		if g := new(big.Int).GCD(nil, nil, m, n); g.Cmp(one) != 0 {
+			return g, queries, refused, nil // m shares a prime with n
+		}
+		queries++
+		r, err := oracle(new(big.Int).Exp(m, big.NewInt(2), n))
+		if err != nil {
+			refused++
+			continue
+		}
+		if g := new(big.Int).GCD(nil, nil, new(big.Int).Sub(m, r), n); g.Cmp(one) != 0 && g.Cmp(n) != 0 {
+			return g, queries, refused, nil
+		}
+	}
+	return nil, queries, refused, fmt.Errorf("no answer out of %d queries split n", queries)
+}
+
+// rawOracle answers with the smallest square root of c, as a decryption without redundancy would.
+func (k *RabinKey) rawOracle(c *big.Int) (*big.Int, error) {
+	roots, err := k.SquareRoots(c)
+	if err != nil {
+		return nil, err
+	}
+	s := roots[0]
+	for _, r := range roots[1:] {
+		if r.Cmp(s) < 0 {
+			s = r
+		}
+	}
+	return s, nil
+}
+
+// redundancyOracle answers only when exactly one root carries the redundancy. A random m
+// has none, and an m with redundancy is the root that comes back, so n is not split.
+func (k *RabinKey) redundancyOracle(c *big.Int) (*big.Int, error) {
+	x, _, err := k.decryptBlock(c)
+	return x, err
+}
+
+// writeNumbers writes one number per line.
+func writeNumbers(path string, values ...*big.Int) error {
+	var sb strings.Builder
+	for _, v := range values {
+		sb.WriteString(v.String() + "\n")
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+// readNumbers reads exactly len(names) numbers from path, one per line.
+func readNumbers(path string, names ...string) ([]*big.Int, error) {
+	lines, err := helpers.ReadData(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > 0 && len(lines) != len(names) {
+		return nil, fmt.Errorf("expected %d lines (%s) in %s, got %d", len(names), strings.Join(names, ", "), path, len(lines))
+	}
+	values := make([]*big.Int, len(lines))
+	for i, line := range lines {
+		v, ok := new(big.Int).SetString(line, 10)
+		if !ok || v.Sign() < 0 {
+			if len(names) > 0 {
+				return nil, fmt.Errorf("invalid %s in %s: %s", names[i], path, line)
+			}
+			return nil, fmt.Errorf("invalid number in %s: %s", path, line)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// readRabinPrivateKey reads p and q from RabinPrivateKeyFile.
+func readRabinPrivateKey() (*RabinKey, error) {
+	v, err := readNumbers(RabinPrivateKeyFile, "p", "q")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key (run -rabin-k first): %v", err)
+	}
+	return newRabinKey(v[0], v[1])
+}
+
+// readRabinPublicKey reads n from RabinPublicKeyFile.
+func readRabinPublicKey() (*RabinKey, error) {
+	v, err := readNumbers(RabinPublicKeyFile, "n")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key (run -rabin-k first): %v", err)
+	}
+	if v[0].BitLen() < MinRabinBits {
+		return nil, fmt.Errorf("n has %d bits, at least %d are needed", v[0].BitLen(), MinRabinBits)
+	}
+	return &RabinKey{N: v[0]}, nil
+}
+
+// RabinKeysTest generates a key with n of GenBits bits and writes n and p, q.
+func RabinKeysTest() error {
+	log.Printf("Rabin key generation start: n of %d bits", GenBits)
+	key, err := GenerateRabinKey(GenBits)
+	if err != nil {
+		return err
+	}
+	if err := writeNumbers(RabinPublicKeyFile, key.N); err != nil {
+		return fmt.Errorf("failed to write public key: %v", err)
+	}
+	if err := writeNumbers(RabinPrivateKeyFile, key.P, key.Q); err != nil {
+		return fmt.Errorf("failed to write private key: %v", err)
+	}
+	log.Printf("[INFO] n = %s saved to %s, p and q saved to %s", key.N, RabinPublicKeyFile, RabinPrivateKeyFile)
+	return nil
+}
+
+// RabinEncryptTest encrypts RabinPlainFile with the public key into RabinCryptoFile.
+func RabinEncryptTest() error {
+	log.Println("Rabin encryption start")
+	key, err := readRabinPublicKey()
+	if err != nil {
+		return err
+	}
+	msg, err := os.ReadFile(RabinPlainFile)
+	if err != nil {
+		return fmt.Errorf("failed to read plaintext: %v", err)
+	}
+	blocks := key.Encrypt(msg)
+	if err := writeNumbers(RabinCryptoFile, blocks...); err != nil {
+		return fmt.Errorf("failed to write ciphertext: %v", err)
+	}
+	log.Printf("[INFO] %d bytes in %d block(s) of %d bytes saved to %s", len(msg), len(blocks), key.blockBytes(), RabinCryptoFile)
+	return nil
+}
+
+// RabinDecryptTest decrypts RabinCryptoFile with the private key into RabinDecryptedFile.
+func RabinDecryptTest() error {
+	log.Println("Rabin decryption start")
+	key, err := readRabinPrivateKey()
+	if err != nil {
+		return err
+	}
+	blocks, err := readNumbers(RabinCryptoFile)
+	if err != nil {
+		return fmt.Errorf("failed to read ciphertext: %v", err)
+	}
+	msg, err := key.Decrypt(blocks)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(RabinDecryptedFile, msg, 0644); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	log.Printf("[INFO] %d block(s) decrypted to %s", len(blocks), RabinDecryptedFile)
+	return nil
+}
+
+// RabinSignTest signs RabinPlainFile and writes the salt and s to RabinSignatureFile.
+func RabinSignTest() error {
+	log.Println("Rabin signature start")
+	key, err := readRabinPrivateKey()
+	if err != nil {
+		return err
+	}
+	msg, err := os.ReadFile(RabinPlainFile)
+	if err != nil {
+		return fmt.Errorf("failed to read message: %v", err)
+	}
+	salt, s, tries, err := key.Sign(msg)
+	if err != nil {
+		return err
+	}
+	sig := fmt.Sprintf("algorithm: Rabin\nhash: SHA-256\nsalt: %s\n%s\n", hex.EncodeToString(salt), s)
+	if err := os.WriteFile(RabinSignatureFile, []byte(sig), 0644); err != nil {
+		return fmt.Errorf("failed to write signature: %v", err)
+	}
+	log.Printf("[INFO] signature saved to %s (%d salt(s) drawn)", RabinSignatureFile, tries)
+	return nil
+}
+
+// RabinVerifyTest checks RabinSignatureFile against RabinPlainFile and writes T or N to RabinVerifyFile.
+func RabinVerifyTest() error {
+	log.Println("Rabin verification start")
+	key, err := readRabinPublicKey()
+	if err != nil {
+		return err
+	}
+	msg, err := os.ReadFile(RabinPlainFile)
+	if err != nil {
+		return fmt.Errorf("failed to read message: %v", err)
+	}
+	lines, err := helpers.ReadData(RabinSignatureFile)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %v", err)
+	}
+	meta := map[string]string{}
+	var values []string
+	for _, line := range lines {
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			meta[name] = strings.TrimSpace(value)
+		} else {
+			values = append(values, line)
+		}
+	}
+	if meta["algorithm"] != "Rabin" || meta["hash"] != "SHA-256" {
+		return fmt.Errorf("%s is not a Rabin SHA-256 signature", RabinSignatureFile)
+	}
+	salt, err := hex.DecodeString(meta["salt"])
+	if err != nil {
+		return fmt.Errorf("invalid salt: %v", err)
+	}
+	if len(values) != 1 {
+		return fmt.Errorf("expected 1 number (s) in %s, got %d", RabinSignatureFile, len(values))
+	}
+	s, ok := new(big.Int).SetString(values[0], 10)
+	if !ok {
+		return fmt.Errorf("invalid s: %s", values[0])
+	}
+
+	result := "T"
+	valid := VerifyRabin(key.N, msg, salt, s)
+	if !valid {
+		result = "N"
+	}
+	if err := os.WriteFile(RabinVerifyFile, []byte(result), 0644); err != nil {
+		return fmt.Errorf("failed to write verification result: %v", err)
+	}
+	if !valid {
+		return fmt.Errorf("invalid signature: s^2 != H(salt, m) (mod n)")
+	}
+	return nil
+}
+
+// RabinAttackTest runs the chosen-ciphertext attack on the key from RabinPrivateKeyFile, once
+// against a decryption without redundancy and once against the decryption of -rabin-d.
+func RabinAttackTest() error {
+	log.Println("Rabin chosen-ciphertext attack start")
+	key, err := readRabinPrivateKey()
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# chosen-ciphertext attack on n = %s (%d bits)\n\n", key.N, key.N.BitLen())
+
+	p, queries, _, err := ChosenCiphertextAttack(key.N, key.rawOracle)
+	if err != nil {
+		return fmt.Errorf("attack on the decryption without redundancy failed: %v", err)
+	}
+	q := new(big.Int).Div(key.N, p)
+	if p.Cmp(q) < 0 {
+		p, q = q, p
+	}
+	if p.Cmp(key.P) != 0 || q.Cmp(key.Q) != 0 {
+		return fmt.Errorf("attack found %s, which is not a prime of the key", p)
+	}
+	fmt.Fprintf(&sb, "bez redundancji: n rozłożona po %d zapytaniach\np = %s\nq = %s\n\n", queries, p, q)
+	log.Printf("[INFO] decryption without redundancy: n factored after %d queries", queries)
+
+	_, queries, refused, err := ChosenCiphertextAttack(key.N, key.redundancyOracle)
+	if err == nil {
+		return fmt.Errorf("the decryption with redundancy leaked a factor of n")
+	}
+	fmt.Fprintf(&sb, "z redundancją: %d zapytań, %d odrzuconych, n nie została rozłożona\n", queries, refused)
+	log.Printf("[INFO] decryption with redundancy: %d of %d queries refused, n not factored", refused, queries)
+
+	if err := os.WriteFile(RabinAttackFile, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	log.Printf("[INFO] attack report saved to %s", RabinAttackFile)
+	return nil
+}
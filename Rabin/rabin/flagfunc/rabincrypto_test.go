@@ -0,0 +1,130 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// TestSquareRoots checks the four square roots of 23 modulo 77 = 7·11: 10, 32, 45 and 67.
+func TestSquareRoots(t *testing.T) {
+	key, err := newRabinKey(big.NewInt(7), big.NewInt(11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots, err := key.SquareRoots(big.NewInt(23))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[int64]bool{}
+	for _, r := range roots {
+		if r != nil {
+			found[r.Int64()] = true
+		}
+	}
+	if len(found) != 4 || !found[10] || !found[32] || !found[45] || !found[67] {
+		t.Errorf("square roots of 23 mod 77 are %v, expected 10, 32, 45, 67", roots)
+	}
+}
+
+// TestGenerateRabinKey checks the size of n and that p and q are Blum primes (≡ 3 mod 4).
+func TestGenerateRabinKey(t *testing.T) {
+	three := big.NewInt(3)
+	for _, bits := range []int{256, 512} {
+		key, err := GenerateRabinKey(bits)
+		if err != nil {
+			t.Fatalf("%d bits: %v", bits, err)
+		}
+		if key.N.BitLen() != bits || new(big.Int).Mul(key.P, key.Q).Cmp(key.N) != 0 {
+			t.Errorf("n = %s has %d bits, expected %d = p·q", key.N, key.N.BitLen(), bits)
+		}
+		if new(big.Int).And(key.P, three).Cmp(three) != 0 || new(big.Int).And(key.Q, three).Cmp(three) != 0 {
+			t.Errorf("p = %s, q = %s are not both ≡ 3 (mod 4)", key.P, key.Q)
+		}
+	}
+}
+
+// TestRabinEncryptDecrypt round-trips messages of several lengths; leading zero bytes must survive,
+// and a block without the redundancy must be refused.
+func TestRabinEncryptDecrypt(t *testing.T) {
+	key, err := GenerateRabinKey(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		msg  []byte
+	}{
+		{"empty", nil},
+		{"one byte", []byte{0x42}},
+		{"leading zeros", append([]byte{0, 0}, bytes.Repeat([]byte("rabin"), 40)...)},
+		{"all 0xFF", bytes.Repeat([]byte{0xFF}, 123)},
+	}
+	for _, tt := range tests {
+		plain, err := key.Decrypt(key.Encrypt(tt.msg))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !bytes.Equal(plain, tt.msg) {
+			t.Errorf("%s: decrypted %x, expected %x", tt.name, plain, tt.msg)
+		}
+	}
+
+	tampered := key.Encrypt([]byte("rabin"))
+	tampered[0] = new(big.Int).Exp(big.NewInt(12345), big.NewInt(2), key.N)
+	if _, err := key.Decrypt(tampered); err == nil {
+		t.Errorf("a block without redundancy was accepted")
+	}
+}
+
+// TestRabinSignature signs messages and checks that a changed message does not verify.
+func TestRabinSignature(t *testing.T) {
+	key, err := GenerateRabinKey(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range [][]byte{{}, []byte("Bla"), bytes.Repeat([]byte{0xA5}, 200)} {
+		salt, s, _, err := key.Sign(msg)
+		if err != nil {
+			t.Fatalf("Sign(%q): %v", msg, err)
+		}
+		if !VerifyRabin(key.N, msg, salt, s) {
+			t.Errorf("signature of %q does not verify", msg)
+		}
+		if changed := append([]byte{1}, msg...); VerifyRabin(key.N, changed, salt, s) {
+			t.Errorf("signature of %q verifies for %q", msg, changed)
+		}
+	}
+}
+
+// TestChosenCiphertextAttack factors n with an oracle returning any root and checks that the
+// oracle with redundancy refuses every query.
+func TestChosenCiphertextAttack(t *testing.T) {
+	key, err := GenerateRabinKey(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		oracle func(c *big.Int) (*big.Int, error)
+		breaks bool
+	}{
+		{"without redundancy", key.rawOracle, true},
+		{"with redundancy", key.redundancyOracle, false},
+	}
+	for _, tt := range tests {
+		p, queries, refused, err := ChosenCiphertextAttack(key.N, tt.oracle)
+		switch {
+		case tt.breaks && err != nil:
+			t.Errorf("%s: attack failed after %d queries: %v", tt.name, queries, err)
+		case tt.breaks && p.Cmp(key.P) != 0 && p.Cmp(key.Q) != 0:
+			t.Errorf("%s: attack returned %s, not a factor of n", tt.name, p)
+		case !tt.breaks && err == nil:
+			t.Errorf("%s: attack succeeded", tt.name)
+		case !tt.breaks && refused != queries:
+			t.Errorf("%s: %d of %d queries refused, expected all", tt.name, refused, queries)
+		}
+	}
+}
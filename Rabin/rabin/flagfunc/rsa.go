@@ -0,0 +1,173 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"rabin/helpers"
+)
+
+const (
+	RSAKeyFile = "files/rsa_key.txt"
+	// maxRSAIterations bounds the number of random bases. Each base splits n with probability
+	// at least 1/2, so 2 are needed on average and all of them fail with probability ≤ 2^-64.
+	maxRSAIterations = 64
+)
+
+// RSAKey is an RSA key recovered from (n, e, d): the primes p > q, φ(n), λ(n) and the
+// CRT parameters dP = d mod (p−1), dQ = d mod (q−1), qInv = q^(−1) mod p.
+type RSAKey struct {
+	N, E, D      *big.Int
+	P, Q         *big.Int
+	Phi, Lambda  *big.Int
+	Dp, Dq, Qinv *big.Int
+	Iterations   int // random bases used to split n
+}
+
+// RecoverRSAKey factors n from a matching exponent pair. With r = e·d − 1 = t·2^s, r is a
+// multiple of λ(n), so a^r ≡ 1 for every a coprime to n. In the chain a^t, a^2t, ..., a^r
+// the last value before 1 is a square root of 1; when it is not ±1, gcd(b − 1, n) = p.
+func RecoverRSAKey(n, e, d *big.Int) (*RSAKey, error) {
+	one := big.NewInt(1)
+	if n.Bit(0) == 0 || n.Cmp(big.NewInt(15)) < 0 {
+		return nil, fmt.Errorf("n must be an odd product of two primes")
+	}
+	r := new(big.Int).Mul(e, d)
+	r.Sub(r, one)
+	s := r.TrailingZeroBits()
+	if s == 0 {
+		return nil, fmt.Errorf("e·d − 1 is odd, so it is not a multiple of λ(n)")
+	}
+	t := new(big.Int).Rsh(r, s)
+	nm1 := new(big.Int).Sub(n, one)
+
+	var p *big.Int
+	iterations := 0
+	for p == nil {
+		if iterations == maxRSAIterations {
+			return nil, fmt.Errorf("no base out of %d split n", maxRSAIterations)
+		}
+		iterations++
+		a, err := helpers.CryptoRandBigIntBetween(big.NewInt(2), new(big.Int).Sub(n, big.NewInt(2)))
+		if err != nil {
+			return nil, err
+		}
+		if g := new(big.Int).GCD(nil, nil, a, n); g.Cmp(one) != 0 {
+			p = g // a shares a prime with n
+			break
+		}
+
+		b := new(big.Int).Exp(a, t, n)
+		if b.Cmp(one) == 0 || b.Cmp(nm1) == 0 {
+			continue // the chain has only trivial roots
+		}
+		reachedOne := false
+		for i := uint(0); i < s; i++ {
+			c := new(big.Int).Mul(b, b)
+			c.Mod(c, n)
+			if c.Cmp(one) == 0 {
+				// b² = 1 with b ≠ ±1
+				p = new(big.Int).GCD(nil, nil, b.Sub(b, one), n)
+				reachedOne = true
+				break
+			}
+			if c.Cmp(nm1) == 0 {
+				reachedOne = true // the next square is 1, b = −1 is a trivial root
+				break
+			}
+			b = c
+		}
+		if !reachedOne {
+			return nil, fmt.Errorf("a^(e·d − 1) ≠ 1 (mod n): e and d do not belong to n")
+		}
+	}
+
+	q := new(big.Int).Div(n, p)
+	if p.Cmp(q) < 0 {
+		p, q = q, p
+	}
+	if BailliePSW(p).Composite || BailliePSW(q).Composite {
+		return nil, fmt.Errorf("n = %s · %s is not a product of two primes", p, q)
+	}
+
+	key := &RSAKey{N: n, E: e, D: d, P: p, Q: q, Iterations: iterations}
+	pm1, qm1 := new(big.Int).Sub(p, one), new(big.Int).Sub(q, one)
+	key.Phi = new(big.Int).Mul(pm1, qm1)
+	key.Lambda = new(big.Int).Div(key.Phi, new(big.Int).GCD(nil, nil, pm1, qm1))
+	if new(big.Int).Mod(new(big.Int).Mul(e, d), key.Lambda).Cmp(one) != 0 {
+		return nil, fmt.Errorf("e·d ≢ 1 (mod λ(n))")
+	}
+	key.Dp = new(big.Int).Mod(d, pm1)
+	key.Dq = new(big.Int).Mod(d, qm1)
+	key.Qinv = new(big.Int).ModInverse(q, p)
+
+	// The CRT parameters must decrypt what e encrypts
+	m, err := helpers.CryptoRandBigIntBetween(big.NewInt(2), nm1)
+	if err != nil {
+		return nil, err
+	}
+	if key.DecryptCRT(new(big.Int).Exp(m, e, n)).Cmp(m) != 0 {
+		return nil, fmt.Errorf("CRT decryption check failed")
+	}
+	return key, nil
+}
+
+// DecryptCRT computes c^d mod n with Garner's formula: m = mQ + q·(qInv·(mP − mQ) mod p).
+func (k *RSAKey) DecryptCRT(c *big.Int) *big.Int {
+	mp := new(big.Int).Exp(c, k.Dp, k.P)
+	mq := new(big.Int).Exp(c, k.Dq, k.Q)
+	h := new(big.Int).Sub(mp, mq)
+	h.Mul(h, k.Qinv).Mod(h, k.P)
+	return h.Mul(h, k.Q).Add(h, mq)
+}
+
+// Text formats the key, one value per line.
+func (k *RSAKey) Text() string {
+	var sb strings.Builder
+	for _, v := range []struct {
+		name  string
+		value *big.Int
+	}{
+		{"n", k.N}, {"e", k.E}, {"d", k.D},
+		{"p", k.P}, {"q", k.Q},
+		{"phi(n) = (p-1)(q-1)", k.Phi},
+		{"lambda(n) = lcm(p-1, q-1)", k.Lambda},
+		{"dP = d mod (p-1)", k.Dp},
+		{"dQ = d mod (q-1)", k.Dq},
+		{"qInv = q^-1 mod p", k.Qinv},
+	} {
+		fmt.Fprintf(&sb, "%s = %s\n", v.name, v.value)
+	}
+	return sb.String()
+}
+
+// RSATest reads n, e, d from the input file, recovers p and q and writes the key to RSAKeyFile.
+func RSATest(EntryFile string) error {
+	log.Println("RSA key recovery start")
+
+	lines, err := helpers.ReadData(EntryFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %v", err)
+	}
+	n, e, d, err := helpers.ParseRSAKey(lines)
+	if err != nil {
+		return fmt.Errorf("failed to parse input: %v", err)
+	}
+	log.Printf("n = %s (%d bits), e = %s", n, n.BitLen(), e)
+
+	key, err := RecoverRSAKey(n, e, d)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] n split after %d base(s): p = %s, q = %s", key.Iterations, key.P, key.Q)
+
+	if err := os.WriteFile(RSAKeyFile, []byte(key.Text()), 0644); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	log.Printf("[INFO] RSA key saved to %s", RSAKeyFile)
+	return nil
+}
@@ -0,0 +1,87 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// matchesRSAKey compares the recovered key with a crypto/rsa key, including the CRT parameters.
+func matchesRSAKey(key *RSAKey, priv *rsa.PrivateKey) bool {
+	pre := priv.Precomputed
+	if key.P.Cmp(priv.Primes[0]) == 0 && key.Q.Cmp(priv.Primes[1]) == 0 {
+		return key.Dp.Cmp(pre.Dp) == 0 && key.Dq.Cmp(pre.Dq) == 0 && key.Qinv.Cmp(pre.Qinv) == 0
+	}
+	// our p is their Primes[1]
+	return key.P.Cmp(priv.Primes[1]) == 0 && key.Q.Cmp(priv.Primes[0]) == 0 &&
+		key.Dp.Cmp(pre.Dq) == 0 && key.Dq.Cmp(pre.Dp) == 0
+}
+
+func generateRSAKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatalf("failed to generate %d-bit RSA key: %v", bits, err)
+	}
+	return priv
+}
+
+func TestRecoverRSAKey(t *testing.T) {
+	priv := generateRSAKey(t, 1024)
+	e := big.NewInt(int64(priv.E))
+	pm1 := new(big.Int).Sub(priv.Primes[0], big.NewInt(1))
+	qm1 := new(big.Int).Sub(priv.Primes[1], big.NewInt(1))
+	lambda := new(big.Int).Mul(pm1, qm1)
+	lambda.Div(lambda, new(big.Int).GCD(nil, nil, pm1, qm1))
+
+	tests := []struct {
+		name    string
+		n, e, d *big.Int
+		priv    *rsa.PrivateKey // key to compare with; nil for the textbook key
+		wantErr bool
+	}{
+		// n = 61·53, φ = 3120, λ = 780
+		{name: "textbook 3233/17/2753", n: big.NewInt(3233), e: big.NewInt(17), d: big.NewInt(2753)},
+		// d + λ(n) is another valid private exponent
+		{name: "d + lambda", n: priv.N, e: e, d: new(big.Int).Add(priv.D, lambda), priv: priv},
+		{name: "wrong d", n: priv.N, e: e, d: new(big.Int).Add(priv.D, big.NewInt(2)), wantErr: true},
+	}
+	for _, bits := range []int{512, 1024, 2048} {
+		for i := 1; i <= 5; i++ {
+			priv := generateRSAKey(t, bits)
+			tests = append(tests, struct {
+				name    string
+				n, e, d *big.Int
+				priv    *rsa.PrivateKey
+				wantErr bool
+			}{name: fmt.Sprintf("%d-bit key %d", bits, i), n: priv.N, e: big.NewInt(int64(priv.E)), d: priv.D, priv: priv})
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := RecoverRSAKey(tt.n, tt.e, tt.d)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("accepted, expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecoverRSAKey: %v", err)
+			}
+			if tt.priv == nil {
+				if key.P.Int64() != 61 || key.Q.Int64() != 53 || key.Phi.Int64() != 3120 || key.Lambda.Int64() != 780 {
+					t.Errorf("p = %s, q = %s, φ = %s, λ = %s, expected 61, 53, 3120, 780", key.P, key.Q, key.Phi, key.Lambda)
+				}
+				return
+			}
+			if !matchesRSAKey(key, tt.priv) {
+				t.Errorf("p, q, dP, dQ, qInv differ from crypto/rsa (p = %s)", key.P)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+// Author: Paulina Kimak
+package helpers
+
+import "math/big"
+
+// SmallPrimes returns the primes below limit (sieve of Eratosthenes).
+func SmallPrimes(limit int) []int64 {
+	composite := make([]bool, limit)
+	var primes []int64
+	for i := 2; i < limit; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, int64(i))
+		for j := i * i; j < limit; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// TrialDivide removes the given primes from n. It returns the prime factors found
+// (with repetition) and the remaining cofactor.
+func TrialDivide(n *big.Int, primes []int64) ([]*big.Int, *big.Int) {
+	rest := new(big.Int).Set(n)
+	var factors []*big.Int
+	q, m := new(big.Int), new(big.Int)
+	for _, sp := range primes {
+		bp := big.NewInt(sp)
+		if new(big.Int).Mul(bp, bp).Cmp(rest) > 0 {
+			// no prime factor up to √rest: rest is 1 or a prime
+			if rest.Cmp(big.NewInt(1)) > 0 {
+				factors = append(factors, rest)
+				rest = big.NewInt(1)
+			}
+			break
+		}
+		for {
+			q.QuoRem(rest, bp, m)
+			if m.Sign() != 0 {
+				break
+			}
+			factors = append(factors, bp)
+			rest.Set(q)
+		}
+	}
+	return factors, rest
+}
+
+// modRing multiplies modulo n with preallocated temporaries, so the inner loops of the
+// factoring methods do not allocate.
+type modRing struct {
+	n, t *big.Int
+}
+
+func newModRing(n *big.Int) *modRing {
+	return &modRing{n: n, t: new(big.Int)}
+}
+
+// mul sets z = x·y mod n in [0, n); z may alias x or y.
+func (m *modRing) mul(z, x, y *big.Int) *big.Int {
+	return z.Mod(m.t.Mul(x, y), m.n)
+}
+
+// BrentRho looks for a nontrivial factor of the composite n with Brent's variant of
+// Pollard's rho, f(x) = x² + c, trying c = 1, 2, 3. It returns nil after maxSteps steps per c.
+func BrentRho(n *big.Int, maxSteps int) *big.Int {
+	one := big.NewInt(1)
+	if n.Bit(0) == 0 {
+		return big.NewInt(2)
+	}
+	m := newModRing(n)
+	for c := int64(1); c <= 3; c++ {
+		bc := big.NewInt(c)
+		f := func(x *big.Int) {
+			m.t.Mul(x, x)
+			x.Mod(m.t.Add(m.t, bc), n)
+		}
+		y, x, ys := big.NewInt(2), new(big.Int), new(big.Int)
+		q, g, diff := big.NewInt(1), big.NewInt(1), new(big.Int)
+		const batch = 128
+		steps := 0
+		for r := 1; g.Cmp(one) == 0 && steps < maxSteps; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				f(y)
+			}
+			for k := 0; k < r && g.Cmp(one) == 0; k += batch {
+				ys.Set(y)
+				for i := 0; i < batch && i < r-k; i++ {
+					f(y)
+					m.mul(q, q, diff.Sub(x, y))
+				}
+				g.GCD(nil, nil, q, n)
+				steps += batch
+			}
+		}
+		if g.Cmp(n) == 0 {
+			// the product of a whole batch hit 0 mod n: repeat the batch step by step
+			for {
+				f(ys)
+				g.GCD(nil, nil, diff.Sub(x, ys).Abs(diff), n)
+				if g.Cmp(one) != 0 {
+					break
+				}
+			}
+		}
+		if g.Cmp(one) != 0 && g.Cmp(n) != 0 {
+			return g
+		}
+	}
+	return nil
+}
+
+// primePowers calls f(q^e) for every prime q ≤ bound, with q^e the largest power ≤ bound.
+func primePowers(primes []int64, bound int64, f func(*big.Int)) {
+	for _, q := range primes {
+		if q > bound {
+			break
+		}
+		pe := q
+		for pe <= bound/q {
+			pe *= q
+		}
+		f(big.NewInt(pe))
+	}
+}
+
+// nontrivial returns g when 1 < g < n, otherwise nil.
+func nontrivial(g, n *big.Int) *big.Int {
+	if g.Cmp(big.NewInt(1)) > 0 && g.Cmp(n) < 0 {
+		return g
+	}
+	return nil
+}
+
+// PollardPM1 finds a factor p of n when p−1 is B1-smooth, except for one prime ≤ B2.
+// Stage 1: a = 2^M with M = Π q^e ≤ B1; stage 2 multiplies a^q − 1 for the primes B1 < q ≤ B2
+// (consecutive a^q are reached with a^(gap) of the small prime gaps). Returns nil on failure.
+func PollardPM1(n *big.Int, primes []int64, B1, B2 int64) *big.Int {
+	one := big.NewInt(1)
+	a := big.NewInt(2)
+	primePowers(primes, B1, func(pe *big.Int) { a.Exp(a, pe, n) })
+	g := new(big.Int).GCD(nil, nil, new(big.Int).Sub(a, one), n)
+	if d := nontrivial(g, n); d != nil || g.Cmp(n) == 0 {
+		return d
+	}
+
+	// Stage 2
+	gaps := map[int64]*big.Int{}
+	prod := big.NewInt(1)
+	var last int64
+	var aq *big.Int
+	t := new(big.Int)
+	for i, q := range primes {
+		if q <= B1 {
+			continue
+		}
+		if q > B2 {
+			break
+		}
+		if aq == nil {
+			aq = new(big.Int).Exp(a, big.NewInt(q), n)
+		} else {
+			gap := q - last
+			ag, ok := gaps[gap]
+			if !ok {
+				ag = new(big.Int).Exp(a, big.NewInt(gap), n)
+				gaps[gap] = ag
+			}
+			aq.Mul(aq, ag).Mod(aq, n)
+		}
+		last = q
+		prod.Mul(prod, t.Sub(aq, one)).Mod(prod, n)
+		if i%128 == 0 {
+			if d := nontrivial(g.GCD(nil, nil, prod, n), n); d != nil {
+				return d
+			}
+		}
+	}
+	return nontrivial(g.GCD(nil, nil, prod, n), n)
+}
+
+// WilliamsPP1 finds a factor p of n when p+1 is B1-smooth (for a seed A whose A² − 4 is a
+// non-residue mod p). It computes V_M(A) with the Lucas chain V_2k = V_k² − 2,
+// V_(2k+1) = V_k·V_(k+1) − A and checks gcd(V_M − 2, n). Returns nil on failure.
+func WilliamsPP1(n *big.Int, primes []int64, B1 int64) *big.Int {
+	two := big.NewInt(2)
+	for _, seed := range []int64{3, 5, 7, 11} {
+		v := big.NewInt(seed)
+		primePowers(primes, B1, func(pe *big.Int) { v = lucasV(v, pe, n) })
+		g := new(big.Int).GCD(nil, nil, new(big.Int).Sub(v, two), n)
+		if d := nontrivial(g, n); d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+// lucasV returns V_m(A) mod n for V_0 = 2, V_1 = A.
+func lucasV(A, m, n *big.Int) *big.Int {
+	x := new(big.Int).Set(A)    // V_k
+	y := new(big.Int).Mul(A, A) // V_(k+1)
+	y.Sub(y, big.NewInt(2)).Mod(y, n)
+	for i := m.BitLen() - 2; i >= 0; i-- {
+		if m.Bit(i) == 1 {
+			x.Mul(x, y).Sub(x, A).Mod(x, n)
+			y.Mul(y, y).Sub(y, big.NewInt(2)).Mod(y, n)
+		} else {
+			y.Mul(x, y).Sub(y, A).Mod(y, n)
+			x.Mul(x, x).Sub(x, big.NewInt(2)).Mod(x, n)
+		}
+	}
+	return x
+}
+
+// ECM is Lenstra's elliptic curve method on Montgomery curves By² = x³ + Ax² + x with
+// Suyama's parametrization (the group order is divisible by 12). Stage 1 multiplies the point
+// by every q^e ≤ B1 with the (X:Z) ladder; stage 2 looks for one more prime B1 < q ≤ B2 with
+// the baby-step giant-step continuation. gcd(Z, n) reveals p when the order of the curve
+// mod p is smooth enough. Returns nil after the given number of curves.
+func ECM(n *big.Int, primes []int64, B1, B2 int64, curves int) *big.Int {
+	isPrime := primeTable(B2 + ecmD)
+	for c := 0; c < curves; c++ {
+		sigma, err := CryptoRandBigIntBetween(big.NewInt(6), new(big.Int).Sub(n, big.NewInt(1)))
+		if err != nil {
+			return nil
+		}
+		// u = σ² − 5, v = 4σ, P = (u³ : v³), (A+2)/4 = (v−u)³(3u+v) / (16u³v)
+		u := new(big.Int).Mul(sigma, sigma)
+		u.Sub(u, big.NewInt(5)).Mod(u, n)
+		v := new(big.Int).Lsh(sigma, 2)
+		v.Mod(v, n)
+		X := new(big.Int).Exp(u, big.NewInt(3), n)
+		Z := new(big.Int).Exp(v, big.NewInt(3), n)
+		num := new(big.Int).Sub(v, u)
+		num.Exp(num, big.NewInt(3), n)
+		num.Mul(num, new(big.Int).Add(new(big.Int).Mul(big.NewInt(3), u), v)).Mod(num, n)
+		den := new(big.Int).Mul(X, v)
+		den.Lsh(den, 4).Mod(den, n)
+		if d := nontrivial(new(big.Int).GCD(nil, nil, den, n), n); d != nil {
+			return d // lucky: the denominator shares a factor with n
+		}
+		denInv := new(big.Int).ModInverse(den, n)
+		if denInv == nil {
+			continue
+		}
+		e := newCurve(n, num.Mul(num, denInv).Mod(num, n))
+
+		P := &xz{X, Z}
+		primePowers(primes, B1, func(pe *big.Int) { e.ladder(P, P, pe) })
+		g := new(big.Int).GCD(nil, nil, P.z, n)
+		if d := nontrivial(g, n); d != nil {
+			return d
+		}
+		if g.Cmp(n) == 0 {
+			continue // every prime of n found at once: try the next curve
+		}
+		if d := e.stage2(P, B1, B2, isPrime); d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+// ecmD is the giant step of ECM stage 2 (2·3·5·7·11, so only 240 baby steps are needed).
+const ecmD = 2310
+
+// xz is a point in projective (X:Z) coordinates.
+type xz struct {
+	x, z *big.Int
+}
+
+// curve is a Montgomery curve with (A+2)/4 = a24 and the temporaries of its formulas.
+type curve struct {
+	*modRing
+	a24                    *big.Int
+	t1, t2, t3, t4, t5, t6 *big.Int
+}
+
+func newCurve(n, a24 *big.Int) *curve {
+	return &curve{modRing: newModRing(n), a24: a24,
+		t1: new(big.Int), t2: new(big.Int), t3: new(big.Int),
+		t4: new(big.Int), t5: new(big.Int), t6: new(big.Int)}
+}
+
+// double sets r = 2p; r may be p.
+func (e *curve) double(r, p *xz) {
+	e.t1.Add(p.x, p.z)
+	e.mul(e.t1, e.t1, e.t1) // (x+z)²
+	e.t2.Sub(p.x, p.z)
+	e.mul(e.t2, e.t2, e.t2) // (x−z)²
+	e.t3.Sub(e.t1, e.t2)    // 4xz
+	e.mul(r.x, e.t1, e.t2)
+	e.mul(e.t4, e.a24, e.t3)
+	e.mul(r.z, e.t4.Add(e.t4, e.t2), e.t3)
+}
+
+// add sets r = p + q given diff = p − q; r may be p or q but not diff.
+func (e *curve) add(r, p, q, diff *xz) {
+	e.mul(e.t1, e.t5.Sub(p.x, p.z), e.t6.Add(q.x, q.z))
+	e.mul(e.t2, e.t5.Add(p.x, p.z), e.t6.Sub(q.x, q.z))
+	e.t3.Add(e.t1, e.t2)
+	e.t4.Sub(e.t1, e.t2)
+	e.mul(e.t3, e.t3, e.t3)
+	e.mul(e.t4, e.t4, e.t4)
+	e.mul(r.x, e.t3, diff.z)
+	e.mul(r.z, e.t4, diff.x)
+}
+
+// ladder sets r = k·p with the Montgomery ladder (k ≥ 1); r may be p.
+func (e *curve) ladder(r, p *xz, k *big.Int) {
+	d := &xz{new(big.Int).Set(p.x), new(big.Int).Set(p.z)}
+	r0 := &xz{new(big.Int).Set(p.x), new(big.Int).Set(p.z)}
+	r1 := &xz{new(big.Int), new(big.Int)}
+	e.double(r1, d)
+	for i := k.BitLen() - 2; i >= 0; i-- {
+		if k.Bit(i) == 1 {
+			e.add(r0, r1, r0, d)
+			e.double(r1, r1)
+		} else {
+			e.add(r1, r1, r0, d)
+			e.double(r0, r0)
+		}
+	}
+	r.x.Set(r0.x)
+	r.z.Set(r0.z)
+}
+
+// stage2 multiplies x(m·D·Q)·z(j·Q) − x(j·Q)·z(m·D·Q) over all m·D ± j prime in (B1, B2],
+// with j < D/2 coprime to D. The product is 0 mod p when (m·D ± j)·Q is the identity mod p.
+func (e *curve) stage2(Q *xz, B1, B2 int64, isPrime []bool) *big.Int {
+	// baby steps jQ for odd j < D/2: (j+2)Q = jQ + 2Q with difference (j−2)Q
+	var js []int64
+	var baby []*xz
+	Q2 := &xz{new(big.Int), new(big.Int)}
+	e.double(Q2, Q)
+	prev := &xz{new(big.Int).Set(Q.x), new(big.Int).Set(Q.z)} // (j−2)Q
+	cur := &xz{new(big.Int), new(big.Int)}                    // jQ
+	e.add(cur, Q2, Q, Q)
+	baby = append(baby, &xz{new(big.Int).Set(Q.x), new(big.Int).Set(Q.z)})
+	js = append(js, 1)
+	for j := int64(3); j < ecmD/2; j += 2 {
+		if j > 3 {
+			next := &xz{new(big.Int), new(big.Int)}
+			e.add(next, cur, Q2, prev)
+			prev, cur = cur, next
+		}
+		if new(big.Int).GCD(nil, nil, big.NewInt(j), big.NewInt(ecmD)).Int64() == 1 {
+			baby = append(baby, &xz{new(big.Int).Set(cur.x), new(big.Int).Set(cur.z)})
+			js = append(js, j)
+		}
+	}
+
+	// giant steps R_m = m·D·Q: R_(m+1) = R_m + D·Q with difference R_(m−1)
+	m := B1 / ecmD
+	if m < 1 {
+		m = 1
+	}
+	DQ := &xz{new(big.Int).Set(Q.x), new(big.Int).Set(Q.z)}
+	e.ladder(DQ, DQ, big.NewInt(ecmD))
+	R := &xz{new(big.Int).Set(Q.x), new(big.Int).Set(Q.z)}
+	e.ladder(R, R, big.NewInt(m*ecmD))
+	Rnext := &xz{new(big.Int).Set(Q.x), new(big.Int).Set(Q.z)}
+	e.ladder(Rnext, Rnext, big.NewInt((m+1)*ecmD))
+
+	prod := big.NewInt(1)
+	a, b := new(big.Int), new(big.Int)
+	g := new(big.Int)
+	for ; m*ecmD-ecmD/2 <= B2; m++ {
+		for i, j := range js {
+			lo, hi := m*ecmD-j, m*ecmD+j
+			if !(lo > B1 && lo <= B2 && isPrime[lo]) && !(hi > B1 && hi <= B2 && isPrime[hi]) {
+				continue
+			}
+			e.mul(a, R.x, baby[i].z)
+			e.mul(b, baby[i].x, R.z)
+			e.mul(prod, prod, a.Sub(a, b))
+		}
+		if m%64 == 0 {
+			if d := nontrivial(g.GCD(nil, nil, prod, e.n), e.n); d != nil {
+				return d
+			}
+		}
+		// R, Rnext = Rnext, Rnext + DQ (difference R)
+		next := &xz{new(big.Int), new(big.Int)}
+		e.add(next, Rnext, DQ, R)
+		R, Rnext = Rnext, next
+	}
+	return nontrivial(g.GCD(nil, nil, prod, e.n), e.n)
+}
+
+// primeTable returns isPrime[i] for i < limit.
+func primeTable(limit int64) []bool {
+	isPrime := make([]bool, limit)
+	for i := int64(2); i < limit; i++ {
+		isPrime[i] = true
+	}
+	for i := int64(2); i*i < limit; i++ {
+		if isPrime[i] {
+			for j := i * i; j < limit; j += i {
+				isPrime[j] = false
+			}
+		}
+	}
+	return isPrime
+}
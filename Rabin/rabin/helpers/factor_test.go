@@ -0,0 +1,71 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestFactorMethods splits numbers built for each method and checks d · n/d = n. p−1 of
+// 1563936363450587279 is 2·587·1901·2887·12497·19423, p+1 of 3464479215029531977 is
+// 2·479·2251·6121·14723·17827, and the other prime of both products has a prime factor
+// above 2^40 in q−1 and in q+1. 2^128 + 1 = 59649589127497217 · 5704689200685129054721.
+func TestFactorMethods(t *testing.T) {
+	primes := SmallPrimes(1 << 22)
+	tests := []struct {
+		name string
+		n    string
+		find func(n *big.Int) *big.Int
+	}{
+		{"BrentRho", "10403", func(n *big.Int) *big.Int { return BrentRho(n, 1<<10) }},
+		{"BrentRho", "9223372064772063217", func(n *big.Int) *big.Int { return BrentRho(n, 1<<18) }}, // (2^31 − 1)(2^32 + 15)
+		{"PollardPM1", "20852234015841605521989518121128741849", func(n *big.Int) *big.Int {
+			return PollardPM1(n, primes, 20000, 1<<20)
+		}},
+		{"WilliamsPP1", "63457389262901013152687568865301441321", func(n *big.Int) *big.Int {
+			return WilliamsPP1(n, primes, 20000)
+		}},
+		{"ECM", "340282366920938463463374607431768211457", func(n *big.Int) *big.Int {
+			return ECM(n, primes, 11000, 1100000, 200)
+		}},
+	}
+	for _, tt := range tests {
+		n, _ := new(big.Int).SetString(tt.n, 10)
+		d := tt.find(n)
+		if d == nil {
+			t.Errorf("%s found no factor of %s", tt.name, n)
+			continue
+		}
+		q, m := new(big.Int).QuoRem(n, d, new(big.Int))
+		if d.Cmp(big.NewInt(1)) <= 0 || d.Cmp(n) >= 0 || m.Sign() != 0 || new(big.Int).Mul(d, q).Cmp(n) != 0 {
+			t.Errorf("%s: %s is not a nontrivial factor of %s", tt.name, d, n)
+		}
+	}
+}
+
+// TestTrialDivide checks that the factors times the rest give n: the rest is 1 when a prime up to
+// its square root ends the search, and the unfactored part once the primes run out.
+func TestTrialDivide(t *testing.T) {
+	primes := SmallPrimes(1 << 16)
+	tests := []struct {
+		n       int64
+		factors int
+		rest    int64
+	}{
+		{2 * 2 * 2 * 3 * 101, 5, 1},
+		{65521 * 65521, 2, 1},
+		{1 << 16 * 9 * 65521 * 1000003, 19, 1000003},
+		{65537 * 65539, 0, 65537 * 65539},
+	}
+	for _, tt := range tests {
+		n := big.NewInt(tt.n)
+		factors, rest := TrialDivide(n, primes)
+		product := new(big.Int).Set(rest)
+		for _, p := range factors {
+			product.Mul(product, p)
+		}
+		if product.Cmp(n) != 0 || len(factors) != tt.factors || rest.Int64() != tt.rest {
+			t.Errorf("%d: factors %v and rest %s, expected %d factors and rest %d", tt.n, factors, rest, tt.factors, tt.rest)
+		}
+	}
+}
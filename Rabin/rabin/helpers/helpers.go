@@ -24,6 +24,17 @@ func SetLogger() {
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 }
 
+// CountSelectedFlags returns how many of the boolean flags are set.
+func CountSelectedFlags(flags []*bool) int {
+	count := 0
+	for _, f := range flags {
+		if *f {
+			count++
+		}
+	}
+	return count
+}
+
 // Function ReadData reads and returns non-empty trimmed lines from a text file.
 func ReadData(path string) ([]string, error) {
 	file, err := os.Open(path)
@@ -83,6 +94,22 @@ func ParseInput(lines []string) (*big.Int, *big.Int, error) {
 	return n, r, nil
 }
 
+// ParseRSAKey reads an RSA key n, e, d from three lines (the same layout as ParseInput,
+// which turns lines 2 and 3 into r = e·d − 1).
+func ParseRSAKey(lines []string) (n, e, d *big.Int, err error) {
+	if len(lines) != 3 {
+		return nil, nil, nil, fmt.Errorf("expected 3 lines (n, e, d), got %d", len(lines))
+	}
+	values := make([]*big.Int, 3)
+	for i, name := range []string{"n", "e", "d"} {
+		v, ok := new(big.Int).SetString(lines[i], 10)
+		if !ok || v.Cmp(big.NewInt(1)) <= 0 {
+			return nil, nil, nil, fmt.Errorf("invalid %s: %s", name, lines[i])
+		}
+		values[i] = v
+	}
+	return values[0], values[1], values[2], nil
+}
 
 // CryptoRandBigIntBetween returns a cryptographically secure random big.Int in [min, max].
 func CryptoRandBigIntBetween(min, max *big.Int) (*big.Int, error) {
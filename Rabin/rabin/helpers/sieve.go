@@ -0,0 +1,82 @@
+// Author: Paulina Kimak
+package helpers
+
+import "math/big"
+
+// Progression is the arithmetic progression Start + j·Step, j = 0, 1, 2, ...
+type Progression struct {
+	Start, Step *big.Int
+}
+
+// Term returns Start + j·Step.
+func (p Progression) Term(j int64) *big.Int {
+	t := new(big.Int).Mul(p.Step, big.NewInt(j))
+	return t.Add(t, p.Start)
+}
+
+// residues holds Start and Step of one progression modulo every sieving prime,
+// with the inverse of Step (0 when the prime divides Step).
+type residues struct {
+	start, step, inv []int64
+	skip             []bool // the prime is not below Start, so a term may be the prime itself
+}
+
+// Sieve removes the terms of one or more progressions that have a small prime factor,
+// so that only the survivors are passed to the expensive primality test.
+type Sieve struct {
+	primes []int64
+	progs  []residues
+}
+
+// NewSieve reduces the progressions modulo the given small primes.
+func NewSieve(primes []int64, progs ...Progression) *Sieve {
+	s := &Sieve{primes: primes}
+	for _, pr := range progs {
+		res := residues{
+			start: make([]int64, len(primes)),
+			step:  make([]int64, len(primes)),
+			inv:   make([]int64, len(primes)),
+			skip:  make([]bool, len(primes)),
+		}
+		m := new(big.Int)
+		for i, p := range primes {
+			bp := big.NewInt(p)
+			res.start[i] = m.Mod(pr.Start, bp).Int64()
+			res.step[i] = m.Mod(pr.Step, bp).Int64()
+			if res.step[i] != 0 {
+				res.inv[i] = m.ModInverse(big.NewInt(res.step[i]), bp).Int64()
+			}
+			res.skip[i] = pr.Start.Cmp(bp) <= 0
+		}
+		s.progs = append(s.progs, res)
+	}
+	return s
+}
+
+// Window returns ok[i] = true when term offset+i of every progression has no prime
+// factor among the sieving primes.
+func (s *Sieve) Window(offset int64, size int) []bool {
+	ok := make([]bool, size)
+	for i := range ok {
+		ok[i] = true
+	}
+	for _, res := range s.progs {
+		for i, p := range s.primes {
+			if res.skip[i] {
+				continue
+			}
+			// term j ≡ start + (offset + j)·step ≡ 0 (mod p)
+			cur := (res.start[i] + (offset%p)*res.step[i]) % p
+			if res.step[i] == 0 {
+				if cur == 0 {
+					return make([]bool, size) // p divides every term
+				}
+				continue
+			}
+			for j := (p - cur) % p * res.inv[i] % p; j < int64(size); j += p {
+				ok[j] = false
+			}
+		}
+	}
+	return ok
+}
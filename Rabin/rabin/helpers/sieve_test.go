@@ -0,0 +1,26 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestSieveWindow checks that the survivors of the sieve of 10^6 + 1 + 2j, j < 1000, are exactly
+// the terms without a factor below 2^16 found by trial division.
+func TestSieveWindow(t *testing.T) {
+	primes := SmallPrimes(1 << 16)
+	prog := Progression{Start: big.NewInt(1000001), Step: big.NewInt(2)}
+	window := NewSieve(primes, prog).Window(0, 1000)
+	if len(window) != 1000 {
+		t.Fatalf("window of %d terms, expected 1000", len(window))
+	}
+	for j, ok := range window {
+		term := prog.Term(int64(j))
+		small, _ := TrialDivide(term, primes)
+		hasSmall := len(small) > 0 && small[0].Cmp(term) != 0
+		if ok == hasSmall {
+			t.Errorf("%s: sieve says %v, trial division found small factors %v", term, ok, small)
+		}
+	}
+}
@@ -14,13 +14,72 @@ func main() {
 
 	//Set flags
 	fermatFlag := flag.Bool("f", false, "Fermat test")
+	factorFlag := flag.Bool("factor", false, "full factorization of n (trial division, rho, p-1, p+1, ECM)")
+	batchFlag := flag.Bool("batch", false, "Rabin-Miller test of every number (or range a..b) in the -in file")
+	rsaFlag := flag.Bool("rsa", false, "recover p, q, phi(n) and the CRT parameters from an RSA key n, e, d")
+	genFlag := flag.Bool("gen", false, "generate primes of exactly -bits bits (-type, -count)")
+	liarsFlag := flag.Bool("liars", false, "count Fermat and strong liars of n (or of every odd composite of -range)")
+	carmichaelFlag := flag.Bool("carmichael", false, "enumerate Carmichael numbers up to -limit (Korselt) and test them")
+	rabinKeysFlag := flag.Bool("rabin-k", false, "prepare Rabin keys n = p·q with p ≡ q ≡ 3 (mod 4), n of -bits bits")
+	rabinEncryptFlag := flag.Bool("rabin-e", false, "Rabin encryption of rabin_plain.txt")
+	rabinDecryptFlag := flag.Bool("rabin-d", false, "Rabin decryption of rabin_crypto.txt")
+	rabinSignFlag := flag.Bool("rabin-s", false, "Rabin signature of rabin_plain.txt")
+	rabinVerifyFlag := flag.Bool("rabin-v", false, "verify the Rabin signature")
+	rabinAttackFlag := flag.Bool("rabin-cca", false, "factor n with a chosen-ciphertext attack on the Rabin decryption")
+
+	// Options
+	inFlag := flag.String("in", flagfunc.BatchInput, "input file of -batch: one number or range per line")
+	methodFlag := flag.String("method", flagfunc.Method, "primality test: random, deterministic, bpsw or pocklington")
+	workersFlag := flag.Int("workers", flagfunc.Workers, "number of goroutines used by -batch and -gen")
+	bitsFlag := flag.Int("bits", flagfunc.GenBits, "bit length of the primes made by -gen (of n for -rabin-k)")
+	typeFlag := flag.String("type", flagfunc.GenKind, "type of prime for -gen: random, safe, strong or provable")
+	countFlag := flag.Int("count", flagfunc.GenCount, "number of primes made by -gen")
+	rangeFlag := flag.String("range", flagfunc.LiarRange, "number or range a..b for -liars (default: n from wejscie.txt)")
+	limitFlag := flag.Int("limit", flagfunc.CarmichaelLimit, "bound of -carmichael")
 
 	flag.Parse()
 
+	if helpers.CountSelectedFlags([]*bool{fermatFlag, batchFlag, factorFlag, rsaFlag, genFlag, liarsFlag, carmichaelFlag,
+		rabinKeysFlag, rabinEncryptFlag, rabinDecryptFlag, rabinSignFlag, rabinVerifyFlag, rabinAttackFlag}) > 1 {
+		log.Fatalf("Error: choose only one of -f, -batch, -factor, -rsa, -gen, -liars, -carmichael, -rabin-k, -rabin-e, -rabin-d, -rabin-s, -rabin-v and -rabin-cca.")
+	}
+	flagfunc.BatchInput = *inFlag
+	flagfunc.Workers = *workersFlag
+	flagfunc.Method = *methodFlag
+	flagfunc.GenBits = *bitsFlag
+	flagfunc.GenKind = *typeFlag
+	flagfunc.GenCount = *countFlag
+	flagfunc.LiarRange = *rangeFlag
+	flagfunc.CarmichaelLimit = *limitFlag
+
 	// Determine the operation
 	var operation string
 	if *fermatFlag {
 		operation = "f"
+	} else if *batchFlag {
+		operation = "b"
+	} else if *factorFlag {
+		operation = "fac"
+	} else if *rsaFlag {
+		operation = "rsa"
+	} else if *genFlag {
+		operation = "gen"
+	} else if *liarsFlag {
+		operation = "liars"
+	} else if *carmichaelFlag {
+		operation = "carm"
+	} else if *rabinKeysFlag {
+		operation = "rk"
+	} else if *rabinEncryptFlag {
+		operation = "re"
+	} else if *rabinDecryptFlag {
+		operation = "rd"
+	} else if *rabinSignFlag {
+		operation = "rs"
+	} else if *rabinVerifyFlag {
+		operation = "rv"
+	} else if *rabinAttackFlag {
+		operation = "cca"
 	} else {
 		operation = "r" // default Rabin-Miller
 	}
@@ -0,0 +1,114 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"stegano/helpers"
+)
+
+const CapacityFile = "files/capacity.txt"
+
+// maxMessageBytes returns the longest payload whose frame fits into the given number of bits,
+// or −1 when not even an empty frame fits.
+func maxMessageBytes(bits int) int {
+	n := bits/8 - 6 // magic, one length byte and CRC32
+	for n >= 0 && helpers.FrameBits(n) > bits {
+		n--
+	}
+	return max(n, -1)
+}
+
+// messageBytesText formats a byte count of the report ("-" when nothing fits).
+func messageBytesText(n int) string {
+	if n < 0 {
+		return "-"
+	}
+	return fmt.Sprint(n)
+}
+
+// AnalyzeCapacity reports how many bits each method can hide in the cover (after the cleanup done
+// by -e), the longest message for each, with and without a passphrase, and a suggested method.
+// -e embeds with one method at a time, so no combined capacity is reported. The report is printed
+// and written to CapacityFile.
+func AnalyzeCapacity(CoverFile, MessageFile string) error {
+	content, err := os.ReadFile(CoverFile)
+	if err != nil {
+		return err
+	}
+	cleaned, err := helpers.CleanHtml(string(content))
+	if err != nil {
+		return err
+	}
+	input := []byte(cleaned)
+
+	capacity := map[int]int{}
+	for m := 1; m <= 4; m++ {
+		capacity[m] = carrierCount(input, m)
+	}
+	tags := startTags(cleaned)
+	styled := 0
+	for _, t := range tags {
+		if t.name == "p" && t.style != "" {
+			styled++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# capacity of %s (after the cleanup done by -e)\n", CoverFile)
+	fmt.Fprintf(&sb, "lines: %d, space regions: %d, <p style>: %d (%d with margin-bottom and line-height), <div>: %d\n\n",
+		capacity[1], capacity[2], styled, capacity[3], capacity[4])
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "metoda\tbity\twiadomość (bajty)\tz -pass (bajty)\t")
+	for m := 1; m <= 4; m++ {
+		plain := maxMessageBytes(capacity[m])
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t\n", m, capacity[m], messageBytesText(plain), messageBytesText(plain-helpers.SealOverhead))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// the message of mess.txt as -e would frame it
+	var need int
+	if payload, err := helpers.ReadHexBytes(MessageFile); err == nil {
+		size := len(payload)
+		if Passphrase != "" {
+			size += helpers.SealOverhead
+		}
+		need = helpers.FrameBits(size)
+		fmt.Fprintf(&sb, "\n%s: %d byte(s), %d bits to embed", MessageFile, len(payload), need)
+		if Passphrase != "" {
+			sb.WriteString(" (encrypted)")
+		}
+		sb.WriteString("\n")
+	} else {
+		log.Printf("[WARN] %s not read, suggestion made for an empty message: %v", MessageFile, err)
+		need = helpers.FrameBits(0)
+	}
+
+	// the suggestion is the method with the most carriers, if it holds the message
+	best := 1
+	for m := 2; m <= 4; m++ {
+		if capacity[m] > capacity[best] {
+			best = m
+		}
+	}
+	if capacity[best] >= need {
+		fmt.Fprintf(&sb, "suggested: method %d (%d bits, the most carriers in this cover)\n", best, capacity[best])
+	} else {
+		fmt.Fprintf(&sb, "suggested: none fits (method %d holds at most %d bits, %d needed)\n", best, capacity[best], need)
+		log.Printf("[WARN] %s is too small for the message with every method", CoverFile)
+	}
+
+	fmt.Print(sb.String())
+	if err := os.WriteFile(CapacityFile, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write capacity report: %v", err)
+	}
+	log.Printf("[INFO] capacity report saved to %s", CapacityFile)
+	return nil
+}
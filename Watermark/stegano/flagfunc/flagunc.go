@@ -2,6 +2,7 @@
 package flagfunc
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
@@ -19,17 +20,36 @@ const (
 	DetectFile 			= "files/detect.txt"
 )
 
+// PassphraseEnv names the variable that supplies the passphrase when -pass is omitted.
+const PassphraseEnv = "STEGANO_PASSPHRASE"
+
+// CoverPath is the cover read by -e and -a (set from -cover)
+var CoverPath = CoverFile
+
+// Passphrase encrypts the message and permutes the carriers; empty means plain embedding
+// (set from -pass or STEGANO_PASSPHRASE)
+var Passphrase = ""
+
 func ExecuteProgram(operation string, method int) error {
 	switch operation {
 	case "e":
 		// IN MessageFile, OUT WatermarkFile 
-		err := EmbedMsg(MessageFile, CoverFile, method)
+		err := EmbedMsg(MessageFile, CoverPath, method)
 		if err != nil {
 			return fmt.Errorf("failed to embeded the message: %v", err)
 		}
 		log.Println("[INFO] Text successfully embeded into watermark.html.")
 		return nil
 
+	case "a":
+		// IN CoverPath, OUT CapacityFile
+		err := AnalyzeCapacity(CoverPath, MessageFile)
+		if err != nil {
+			return fmt.Errorf("failed to analyze the cover: %v", err)
+		}
+		log.Println("[INFO] Capacity analysis executed.")
+		return nil
+
 	case "d":
 		err := ExtractMsg(WatermarkFile, DetectFile, method)
 		if err != nil {
@@ -44,10 +64,19 @@ func ExecuteProgram(operation string, method int) error {
 }
 
 func EmbedMsg(MessageFile, CoverFile string, method int) error {
-	messageBits, err := helpers.ReadHexBits(MessageFile)
+	payload, err := helpers.ReadHexBytes(MessageFile)
 	if err != nil {
 		return err
 	}
+	if Passphrase != "" {
+		payload, err = helpers.SealPayload(payload, Passphrase)
+		if err != nil {
+			return err
+		}
+	}
+	// magic, length, payload and CRC32, so extraction finds the end of the message itself
+	messageBits := helpers.BuildFrame(payload)
+	log.Printf("[INFO] %d byte(s) of message framed into %d bits", len(payload), len(messageBits))
 	// //  BACKUP oryginału cover.html
 	// backup := "files/cover_original.html"
 	// inputOriginal, err := os.ReadFile(CoverFile)
@@ -56,17 +85,33 @@ func EmbedMsg(MessageFile, CoverFile string, method int) error {
 	// 	log.Println("[INFO] Backup of cover.html saved as files/cover_original.html")
 	// }
 
-	err = helpers.ClearHtml(CoverFile)
+	// the cover is cleaned in memory only: the -cover file is never written
+	content, err := os.ReadFile(CoverFile)
 	if err != nil {
-		fmt.Println("Error:", err)
-	} else {
-		log.Println("Cleaned HTML saved as files/cover.html")
+		return err
 	}
-
-	input, err := os.ReadFile(CoverFile)
+	cleaned, err := helpers.CleanHtml(string(content))
 	if err != nil {
 		return err
 	}
+	input := []byte(cleaned)
+	log.Printf("[INFO] cover %s cleaned in memory", CoverFile)
+
+	// reject a cover that cannot hold the whole frame before changing anything
+	carriers := carrierCount(input, method)
+	if len(messageBits) > carriers {
+		log.Printf("[WARN] method %d: %d bits needed, the cover has %d carriers", method, len(messageBits), carriers)
+		return fmt.Errorf("cover file too small for message (method %d): %d bits needed, %d available", method, len(messageBits), carriers)
+	}
+
+	if Passphrase != "" {
+		// every carrier gets a bit: the frame at key-chosen positions, random bits elsewhere
+		messageBits, err = helpers.ScatterBits(messageBits, helpers.CarrierPermutation(Passphrase, carriers))
+		if err != nil {
+			return err
+		}
+		log.Printf("[INFO] message encrypted with AES-GCM and spread over all %d carriers", carriers)
+	}
 
 	switch method {
 	case 1:
@@ -88,18 +133,58 @@ func ExtractMsg(WatermarkFile, DetectFile string, method int) error {
 		return err
 	}
 
+	var bits string
 	switch method {
 	case 1:
-		return extractMethod1(input, DetectFile)
+		bits = extractMethod1(input)
 	case 2:
-		return extractMethod2(input, DetectFile)
+		bits = extractMethod2(input)
 	case 3:
-		return extractMethod3(input, DetectFile)
+		bits = extractMethod3(input)
 	case 4:
-		return extractMethod4(input, DetectFile)
+		bits = extractMethod4(input)
 	default:
 		return errors.New("invalid method")
 	}
+
+	if Passphrase != "" {
+		bits = helpers.GatherBits(bits, helpers.CarrierPermutation(Passphrase, len(bits)))
+	}
+	payload, err := helpers.ParseFrame(bits)
+	if err != nil {
+		if Passphrase != "" {
+			err = fmt.Errorf("%v (wrong passphrase?)", err)
+		}
+		log.Printf("[WARN] %d bits read (method %d): %v", len(bits), method, err)
+		return err
+	}
+	log.Printf("[INFO] %d byte(s) of message found in %d bits, CRC32 correct", len(payload), len(bits))
+	if Passphrase != "" {
+		payload, err = helpers.OpenPayload(payload, Passphrase)
+		if err != nil {
+			log.Printf("[WARN] %v", err)
+			return err
+		}
+		log.Printf("[INFO] message decrypted, GCM tag correct")
+	}
+	return os.WriteFile(DetectFile, []byte(hex.EncodeToString(payload)), 0644)
+}
+
+// carrierCount returns the number of places where the method can hide a bit.
+func carrierCount(input []byte, method int) int {
+	text := string(input)
+	switch method {
+	case 1:
+		return len(strings.Split(text, "\n"))
+	case 2:
+		return len(regexp.MustCompile(`[^\S\n]+`).FindAllStringIndex(text, -1))
+	case 3:
+		return len(styleCarriers(startTags(text)))
+	case 4:
+		return len(divCarriers(startTags(text)))
+	default:
+		return 0
+	}
 }
 
 // Method 1: Embed by adding a space at the end of each line based on bit value
@@ -154,13 +239,12 @@ func embedMethod2(input []byte, messageBits string) error {
 }
 
 // Method 3: Embed bits by introducing typos in style attributes of paragraph tags
-// 0 → "margin-botom", 1 → "lineheight"
+// 0 → "margin-botom", 1 → "lineheight". Only <p> tags whose style has both properties are used.
 func embedMethod3(input []byte, messageBits string) error {
 	text := string(input)
-	styleRegex := regexp.MustCompile(`<p[^>]*style="[^"]*"[^>]*>`)
-	styleMatches := styleRegex.FindAllStringIndex(text, -1)
+	carriers := styleCarriers(startTags(text))
 
-	if len(messageBits) > len(styleMatches) {
+	if len(messageBits) > len(carriers) {
 		fmt.Println("cover file too small for message (method 3)")
 		return errors.New("cover file too small for message (method 3)")
 	}
@@ -168,41 +252,32 @@ func embedMethod3(input []byte, messageBits string) error {
 	var sb strings.Builder
 	last := 0
 
-	for i, match := range styleMatches {
-		sb.WriteString(text[last:match[0]])
-		original := text[match[0]:match[1]]
-		modified := original
-
-		// Embed bit only if available
-		if i < len(messageBits) {
-			if messageBits[i] == '1' {
-				modified = strings.Replace(modified, "line-height", "lineheight", 1)
-			} else if messageBits[i] == '0' {
-				modified = strings.Replace(modified, "margin-bottom", "margin-botom", 1)
-			} else {
-				return fmt.Errorf("invalid bit at position %d: %c", i, messageBits[i])
-			}
+	for i, tag := range carriers[:len(messageBits)] {
+		sb.WriteString(text[last:tag.start])
+		raw := text[tag.start:tag.end]
+		switch messageBits[i] {
+		case '1':
+			raw = replaceInStyle(raw, styleBit1, styleBit1Typo)
+		case '0':
+			raw = replaceInStyle(raw, styleBit0, styleBit0Typo)
+		default:
+			return fmt.Errorf("invalid bit at position %d: %c", i, messageBits[i])
 		}
-
-		sb.WriteString(modified)
-		last = match[1]
+		sb.WriteString(raw)
+		last = tag.end
 	}
 
 	sb.WriteString(text[last:])
 	return os.WriteFile(WatermarkFile, []byte(sb.String()), 0644)
 }
 
-
 // Method 4: Embed bits using extra <div> tag patterns
-// 1 → duplicate open-close-open pattern, 0 → duplicate closing tags
+// 1 → "</div><div>" right after the <div ...> start tag, 0 → nothing
 func embedMethod4(input []byte, messageBits string) error {
 	text := string(input)
+	carriers := divCarriers(startTags(text))
 
-	// Find <div ...>
-	divRegex := regexp.MustCompile(`(?i)<div[^>]*>`)
-	matches := divRegex.FindAllStringIndex(text, -1)
-
-	if len(messageBits) > len(matches) {
+	if len(messageBits) > len(carriers) {
 		fmt.Printf("cover file too small for message (method 4)")
 		return errors.New("cover file too small for message (method 4)")
 	}
@@ -210,49 +285,38 @@ func embedMethod4(input []byte, messageBits string) error {
 	var sb strings.Builder
 	last := 0
 
-	for i, match := range matches {
-		sb.WriteString(text[last:match[1]])
+	for i, tag := range carriers[:len(messageBits)] {
+		sb.WriteString(text[last:tag.end])
 
-		// Hide 1 
-		if i < len(messageBits) && messageBits[i] == '1' {
-			sb.WriteString("</div><div>")
+		// Hide 1
+		if messageBits[i] == '1' {
+			sb.WriteString(divBit1Pattern)
 		}
 
-		last = match[1]
+		last = tag.end
 	}
 
 	sb.WriteString(text[last:])
 	return os.WriteFile(WatermarkFile, []byte(sb.String()), 0644)
 }
 
-
-
 // Extraction for Method 1: Check if line ends with space
-func extractMethod1(input []byte, DetectFile string) error {
+func extractMethod1(input []byte) string {
 	text := string(input)
 	lines := strings.Split(text, "\n")
 	var bits strings.Builder
-	bitCount := 0
 	for _, line := range lines {
 		if strings.HasSuffix(line, " ") {
 			bits.WriteByte('1')
-			bitCount++
 		} else if strings.TrimSpace(line) != "" {
 			bits.WriteByte('0')
-			bitCount++
-		}
-		if bitCount%4 == 0 && helpers.IsHex(bits.String()) {
-			if helpers.BitsToHex(bits.String()) == helpers.ReadFileContent("files/mess.txt") {
-				break
-			}
 		}
 	}
-	hexMsg := helpers.BitsToHex(bits.String())
-	return os.WriteFile(DetectFile, []byte(hexMsg), 0644)
+	return bits.String()
 }
 
 // Extraction for Method 2: Check for single vs. double spaces
-func extractMethod2(input []byte, DetectFile string) error {
+func extractMethod2(input []byte) string {
 	text := string(input)
 	spaceRegex := regexp.MustCompile(`[ ]{1,2}`)
 	spaces := spaceRegex.FindAllString(text, -1)
@@ -263,69 +327,39 @@ func extractMethod2(input []byte, DetectFile string) error {
 		} else if sp == " " {
 			bits.WriteByte('0')
 		}
-		if bits.Len()%4 == 0 && helpers.IsHex(bits.String()) {
-			if helpers.BitsToHex(bits.String()) == helpers.ReadFileContent("files/mess.txt") {
-				break
-			}
-		}
 	}
-	hexMsg := helpers.BitsToHex(bits.String())
-	return os.WriteFile(DetectFile, []byte(hexMsg), 0644)
+	return bits.String()
 }
 
-// Extraction for Method 3: Detect style attribute typos
-func extractMethod3(input []byte, DetectFile string) error {
-	text := string(input)
+// Extraction for Method 3: Detect style attribute typos in <p> tags
+func extractMethod3(input []byte) string {
 	var bits strings.Builder
-	styleRegex := regexp.MustCompile(`<p[^>]*style="[^"]*"[^>]*>`)
-	matches := styleRegex.FindAllString(text, -1)
-	for _, m := range matches {
-		if strings.Contains(m, "margin-botom") {
+	for _, tag := range startTags(string(input)) {
+		if tag.name != "p" {
+			continue
+		}
+		if strings.Contains(tag.style, styleBit0Typo) {
 			bits.WriteByte('0')
-		} else if strings.Contains(m, "lineheight") {
+		} else if strings.Contains(tag.style, styleBit1Typo) {
 			bits.WriteByte('1')
 		}
-		if bits.Len()%4 == 0 && helpers.IsHex(bits.String()) {
-			if helpers.BitsToHex(bits.String()) == helpers.ReadFileContent("files/mess.txt") {
-				break
-			}
-		}
 	}
-	hexMsg := helpers.BitsToHex(bits.String())
-	return os.WriteFile(DetectFile, []byte(hexMsg), 0644)
+	return bits.String()
 }
 
-// Extraction for Method 4: Detect open-close-open <font> tag sequence
-func extractMethod4(input []byte, DetectFile string) error {
+// Extraction for Method 4: Detect the "</div><div>" sequence after a <div> start tag
+func extractMethod4(input []byte) string {
 	text := string(input)
-
-	// Znajdź wszystkie otwarcia <div ...>
-	open := regexp.MustCompile(`(?i)<div[^>]*>`)
-	openTags := open.FindAllStringIndex(text, -1)
+	divs := divCarriers(startTags(text))
 
 	var bits strings.Builder
-	i := 0
-
-	for i < len(openTags)-1 {
-		// Sprawdź, czy zaraz po tagu <div> występuje "</div><div"
-		if strings.HasPrefix(text[openTags[i][1]:], "</div><div") {
+	for i := 0; i < len(divs); i++ {
+		if strings.HasPrefix(text[divs[i].end:], divBit1Pattern) {
 			bits.WriteByte('1')
-			i++ // pominąć następny tag-div w sekwencji
+			i++ // the inserted <div> is not a carrier
 		} else {
 			bits.WriteByte('0')
 		}
-		i++
-
-		// Opcjonalne zakończenie, gdy odzyskano cały komunikat
-		if bits.Len()%4 == 0 && helpers.IsHex(bits.String()) {
-			if helpers.BitsToHex(bits.String()) == helpers.ReadFileContent("files/mess.txt") {
-				break
-			}
-		}
 	}
-
-	hexMsg := helpers.BitsToHex(bits.String())
-	return os.WriteFile(DetectFile, []byte(hexMsg), 0644)
+	return bits.String()
 }
-
-
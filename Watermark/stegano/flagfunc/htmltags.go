@@ -0,0 +1,89 @@
+// Author: Paulina Kimak
+package flagfunc
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlTag is a start tag found by the tokenizer with its byte range in the document.
+type htmlTag struct {
+	start, end int
+	name       string
+	style      string // value of the style attribute, lower case ("" when absent)
+}
+
+// startTags returns the start tags of the document in order. Comments, <script>, <style> and
+// <textarea> contents are not tags for the tokenizer, and attributes may use any quoting and
+// span several lines.
+func startTags(text string) []htmlTag {
+	var tags []htmlTag
+	z := html.NewTokenizer(strings.NewReader(text))
+	offset := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return tags
+		}
+		size := len(z.Raw())
+		if tt == html.StartTagToken {
+			name, more := z.TagName()
+			tag := htmlTag{start: offset, end: offset + size, name: string(name)}
+			for more {
+				var key, value []byte
+				key, value, more = z.TagAttr()
+				if string(key) == "style" {
+					tag.style = strings.ToLower(string(value))
+				}
+			}
+			tags = append(tags, tag)
+		}
+		offset += size
+	}
+}
+
+// Markers of method 3 (typos in the style of a <p>) and method 4 (an extra </div><div>)
+const (
+	styleBit0      = "margin-bottom"
+	styleBit0Typo  = "margin-botom"
+	styleBit1      = "line-height"
+	styleBit1Typo  = "lineheight"
+	divBit1Pattern = "</div><div>"
+)
+
+// styleCarriers returns the <p> tags that can hold a method 3 bit.
+func styleCarriers(tags []htmlTag) []htmlTag {
+	var carriers []htmlTag
+	for _, t := range tags {
+		if t.name == "p" && strings.Contains(t.style, styleBit0) && strings.Contains(t.style, styleBit1) {
+			carriers = append(carriers, t)
+		}
+	}
+	return carriers
+}
+
+// divCarriers returns the <div> start tags (method 4 carriers).
+func divCarriers(tags []htmlTag) []htmlTag {
+	var carriers []htmlTag
+	for _, t := range tags {
+		if t.name == "div" {
+			carriers = append(carriers, t)
+		}
+	}
+	return carriers
+}
+
+// replaceInStyle replaces the first old after the style attribute name of a raw tag.
+func replaceInStyle(raw, old, new string) string {
+	at := strings.Index(strings.ToLower(raw), "style")
+	if at < 0 {
+		return raw
+	}
+	i := strings.Index(strings.ToLower(raw[at:]), old)
+	if i < 0 {
+		return raw
+	}
+	i += at
+	return raw[:i] + new + raw[i+len(old):]
+}
@@ -1,3 +1,8 @@
 module stegano
 
 go 1.23.5
+
+require (
+	golang.org/x/crypto v0.36.0
+	golang.org/x/net v0.38.0
+)
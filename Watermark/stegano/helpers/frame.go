@@ -0,0 +1,75 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// FrameMagic is the first byte of every embedded frame (bits 10100101).
+const FrameMagic byte = 0xA5
+
+// BuildFrame returns the bits of magic ‖ uvarint(len(payload)) ‖ payload ‖ CRC32, where the
+// CRC32 (IEEE) covers everything before it.
+func BuildFrame(payload []byte) string {
+	frame := []byte{FrameMagic}
+	frame = binary.AppendUvarint(frame, uint64(len(payload)))
+	frame = append(frame, payload...)
+	frame = binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(frame))
+	return BytesToBits(frame)
+}
+
+// FrameBits returns the number of bits BuildFrame produces for a payload of n bytes.
+func FrameBits(n int) int {
+	var buf [binary.MaxVarintLen64]byte
+	return 8 * (1 + binary.PutUvarint(buf[:], uint64(n)) + n + 4)
+}
+
+// ParseFrame reads a frame from the start of the extracted bits; bits after the frame are ignored.
+// It reports a missing marker, a frame cut short and a CRC32 mismatch as errors.
+func ParseFrame(bits string) ([]byte, error) {
+	data := BitsToBytes(bits)
+	if len(data) == 0 || data[0] != FrameMagic {
+		return nil, fmt.Errorf("no message marker at the start of the hidden bits")
+	}
+	length, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return nil, fmt.Errorf("message length is cut off or invalid")
+	}
+	end := 1 + n + int(length)
+	if length > uint64(len(data)) || end+4 > len(data) {
+		return nil, fmt.Errorf("frame declares %d bytes but only %d bits were found (truncated)", length, len(bits))
+	}
+	stored := binary.BigEndian.Uint32(data[end : end+4])
+	if computed := crc32.ChecksumIEEE(data[:end]); stored != computed {
+		return nil, fmt.Errorf("CRC32 mismatch: stored %08x, computed %08x (message corrupted)", stored, computed)
+	}
+	return data[1+n : end], nil
+}
+
+// BytesToBits writes every byte as 8 characters '0'/'1', most significant bit first.
+func BytesToBits(data []byte) string {
+	var bits strings.Builder
+	for _, b := range data {
+		bits.WriteString(fmt.Sprintf("%08b", b))
+	}
+	return bits.String()
+}
+
+// BitsToBytes packs groups of 8 bits into bytes; a last incomplete group is dropped.
+func BitsToBytes(bits string) []byte {
+	var data []byte
+	for i := 0; i+8 <= len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			b <<= 1
+			if bits[i+j] == '1' {
+				b |= 1
+			}
+		}
+		data = append(data, b)
+	}
+	return data
+}
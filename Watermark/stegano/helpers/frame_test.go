@@ -0,0 +1,54 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestFrameRoundTrip parses frames followed by trailing bits, as extraction reads the whole cover.
+func TestFrameRoundTrip(t *testing.T) {
+	tests := []struct {
+		payload  []byte
+		trailing string
+	}{
+		{[]byte{}, ""},
+		{[]byte("bla"), "0110"},
+		{bytes.Repeat([]byte{0xFF}, 200), strings.Repeat("1", 37)}, // two-byte length
+	}
+	for _, tt := range tests {
+		frame := BuildFrame(tt.payload)
+		if len(frame) != FrameBits(len(tt.payload)) {
+			t.Errorf("%d-byte payload: %d frame bits, FrameBits says %d", len(tt.payload), len(frame), FrameBits(len(tt.payload)))
+		}
+		got, err := ParseFrame(frame + tt.trailing)
+		if err != nil || !bytes.Equal(got, tt.payload) {
+			t.Errorf("%d-byte payload with %d trailing bits: got %x (%v)", len(tt.payload), len(tt.trailing), got, err)
+		}
+	}
+}
+
+// TestFrameErrors checks the three errors of ParseFrame: no marker, a truncated frame and a
+// CRC32 mismatch.
+func TestFrameErrors(t *testing.T) {
+	frame := BuildFrame([]byte("bla"))
+	flipped := []byte(frame)
+	flipped[20] ^= 1 // first payload byte
+	tests := []struct {
+		name, bits, want string
+	}{
+		{"empty", "", "no message marker"},
+		{"no marker", "0" + frame[1:], "no message marker"},
+		{"no length", frame[:8], "cut off"},
+		{"truncated payload", frame[:24], "truncated"},
+		{"truncated CRC", frame[:len(frame)-8], "truncated"},
+		{"corrupted", string(flipped), "CRC32 mismatch"},
+	}
+	for _, tt := range tests {
+		_, err := ParseFrame(tt.bits)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: %v, expected an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -4,7 +4,6 @@ package helpers
 import (
 	"bufio"
 	"encoding/hex"
-	"fmt"
 	"log"
 	"os"
 	"regexp"
@@ -40,36 +39,25 @@ func TextToHex(input string) string {
 }
 
 
-func ReadHexBits(filename string) (string, error) {
+// ReadHexBytes reads a hex string from a file and decodes it.
+func ReadHexBytes(filename string) ([]byte, error) {
 	content, err := os.ReadFile(filename)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	hexStr := strings.TrimSpace(string(content))
-	bytes, err := hex.DecodeString(hexStr)
+	return hex.DecodeString(strings.TrimSpace(string(content)))
+}
+
+func ReadHexBits(filename string) (string, error) {
+	bytes, err := ReadHexBytes(filename)
 	if err != nil {
 		return "", err
 	}
-	var bits strings.Builder
-	for _, b := range bytes {
-		bits.WriteString(fmt.Sprintf("%08b", b))
-	}
-	return bits.String(), nil
+	return BytesToBits(bytes), nil
 }
 
 func BitsToHex(bits string) string {
-	var bytes []byte
-	for i := 0; i+8 <= len(bits); i += 8 {
-		var b byte
-		for j := 0; j < 8; j++ {
-			b <<= 1
-			if bits[i+j] == '1' {
-				b |= 1
-			}
-		}
-		bytes = append(bytes, b)
-	}
-	return hex.EncodeToString(bytes)
+	return hex.EncodeToString(BitsToBytes(bits))
 }
 
 // SaveHexToFile converts the input string to hex and writes it to the given file.
@@ -84,10 +72,18 @@ func ClearHtml(htmlFile string) error {
 	if err != nil {
 		return err
 	}
+	finalContent, err := CleanHtml(string(content))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(htmlFile, []byte(finalContent), 0644)
+}
 
+// CleanHtml returns the content as ClearHtml would write it, without touching any file.
+func CleanHtml(content string) (string, error) {
 	// 1. Remove HTML comments <!-- ... -->
 	commentRegex := regexp.MustCompile(`(?s)<!--.*?-->`)
-	cleaned := commentRegex.ReplaceAllString(string(content), "")
+	cleaned := commentRegex.ReplaceAllString(content, "")
 
 	// 2. Remove known steganographic artifacts from previous embeddings:
 	// - method 2: double spaces
@@ -114,11 +110,10 @@ func ClearHtml(htmlFile string) error {
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return err
+		return "", err
 	}
 
-	finalContent := strings.Join(cleanedLines, "\n")
-	return os.WriteFile(htmlFile, []byte(finalContent), 0644)
+	return strings.Join(cleanedLines, "\n"), nil
 }
 
 // IsHex checks if the bit string has a length that's a multiple of 4
@@ -0,0 +1,121 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// PBKDF2Iterations is the work factor of both keys derived from the passphrase
+	PBKDF2Iterations = 600000
+	sealSaltSize     = 16
+	sealNonceSize    = 12
+	// SealOverhead is the number of bytes SealPayload adds: salt, nonce and GCM tag
+	SealOverhead = sealSaltSize + sealNonceSize + 16
+
+	// permutationSalt is fixed: the permutation is needed to find the payload, so its seed
+	// cannot depend on the random salt stored inside it
+	permutationSalt = "stegano carrier permutation"
+)
+
+// ErrWrongPassphrase is returned when the GCM tag does not match.
+var ErrWrongPassphrase = errors.New("authentication failed: wrong passphrase or modified message")
+
+// SealPayload encrypts the message with AES-256-GCM under PBKDF2-HMAC-SHA256(passphrase, salt) and returns
+// salt ‖ nonce ‖ ciphertext ‖ tag.
+func SealPayload(plain []byte, passphrase string) ([]byte, error) {
+	header := make([]byte, sealSaltSize+sealNonceSize)
+	if _, err := rand.Read(header); err != nil {
+		return nil, fmt.Errorf("crypto/rand failed: %v", err)
+	}
+	gcm, err := passphraseGCM(passphrase, header[:sealSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(header, header[sealSaltSize:], plain, nil), nil
+}
+
+// OpenPayload decrypts and authenticates a payload made by SealPayload.
+func OpenPayload(sealed []byte, passphrase string) ([]byte, error) {
+	if len(sealed) < SealOverhead {
+		return nil, fmt.Errorf("encrypted message too short: %d bytes, at least %d expected", len(sealed), SealOverhead)
+	}
+	gcm, err := passphraseGCM(passphrase, sealed[:sealSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, sealed[sealSaltSize:sealSaltSize+sealNonceSize], sealed[sealSaltSize+sealNonceSize:], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func passphraseGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := pbkdf2.Key([]byte(passphrase), salt, PBKDF2Iterations, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// CarrierPermutation returns a permutation of 0..n-1 (Fisher–Yates) drawn from ChaCha8 seeded
+// with PBKDF2-HMAC-SHA256(passphrase, permutationSalt). Bit i of the frame goes to carrier perm[i].
+func CarrierPermutation(passphrase string, n int) []int {
+	var seed [32]byte
+	copy(seed[:], pbkdf2.Key([]byte(passphrase), []byte(permutationSalt), PBKDF2Iterations, 32, sha256.New))
+	src := mathrand.NewChaCha8(seed)
+
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		// uniform j in [0, i] by rejection, so the result does not depend on math/rand internals
+		bound := uint64(i + 1)
+		threshold := -bound % bound
+		x := src.Uint64()
+		for x < threshold {
+			x = src.Uint64()
+		}
+		j := int(x % bound)
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return perm
+}
+
+// ScatterBits puts bit i at carrier perm[i]; the carriers left over get random bits, so the
+// cover read in order looks like noise.
+func ScatterBits(bits string, perm []int) (string, error) {
+	if len(bits) > len(perm) {
+		return "", fmt.Errorf("%d bits do not fit into %d carriers", len(bits), len(perm))
+	}
+	noise := make([]byte, (len(perm)+7)/8)
+	if _, err := rand.Read(noise); err != nil {
+		return "", fmt.Errorf("crypto/rand failed: %v", err)
+	}
+	carriers := []byte(BytesToBits(noise)[:len(perm)])
+	for i := 0; i < len(bits); i++ {
+		carriers[perm[i]] = bits[i]
+	}
+	return string(carriers), nil
+}
+
+// GatherBits reads the carriers in the order of perm, undoing ScatterBits.
+func GatherBits(carriers string, perm []int) string {
+	var bits strings.Builder
+	for _, p := range perm {
+		bits.WriteByte(carriers[p])
+	}
+	return bits.String()
+}
@@ -0,0 +1,80 @@
+// Author: Paulina Kimak
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// TestSealRoundTrip seals messages of several lengths and opens them with the same passphrase;
+// the wrong passphrase and a flipped byte must give ErrWrongPassphrase.
+func TestSealRoundTrip(t *testing.T) {
+	for _, plain := range [][]byte{{}, []byte("bla"), bytes.Repeat([]byte{0xA5}, 100)} {
+		sealed, err := SealPayload(plain, "sekret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sealed) != len(plain)+SealOverhead {
+			t.Errorf("%d bytes sealed into %d, expected %d", len(plain), len(sealed), len(plain)+SealOverhead)
+		}
+		opened, err := OpenPayload(sealed, "sekret")
+		if err != nil || !bytes.Equal(opened, plain) {
+			t.Errorf("opened %x (%v), expected %x", opened, err, plain)
+		}
+	}
+
+	sealed, err := SealPayload([]byte("bla"), "sekret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenPayload(sealed, "Sekret"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("wrong passphrase: %v, expected ErrWrongPassphrase", err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := OpenPayload(sealed, "sekret"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("modified tag: %v, expected ErrWrongPassphrase", err)
+	}
+	if _, err := OpenPayload(sealed[:SealOverhead-1], "sekret"); err == nil {
+		t.Errorf("payload shorter than SealOverhead opened")
+	}
+}
+
+// TestCarrierPermutation checks that the permutation depends only on the passphrase and
+// contains every carrier exactly once.
+func TestCarrierPermutation(t *testing.T) {
+	const n = 1000
+	perm := CarrierPermutation("sekret", n)
+	if !slices.Equal(perm, CarrierPermutation("sekret", n)) {
+		t.Errorf("two permutations of the same passphrase differ")
+	}
+	if slices.Equal(perm, CarrierPermutation("Sekret", n)) {
+		t.Errorf("two passphrases give the same permutation")
+	}
+	sorted := slices.Sorted(slices.Values(perm))
+	for i, p := range sorted {
+		if p != i {
+			t.Fatalf("not a permutation of 0..%d: %d at position %d after sorting", n-1, p, i)
+		}
+	}
+}
+
+// TestScatterGather checks that GatherBits returns the scattered bits first, followed by noise.
+func TestScatterGather(t *testing.T) {
+	perm := CarrierPermutation("sekret", 64)
+	bits := "1011001110001111"
+	carriers, err := ScatterBits(bits, perm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(carriers) != len(perm) {
+		t.Errorf("%d carriers, expected %d", len(carriers), len(perm))
+	}
+	if got := GatherBits(carriers, perm); got[:len(bits)] != bits || len(got) != len(perm) {
+		t.Errorf("gathered %s, expected %s followed by %d noise bits", got, bits, len(perm)-len(bits))
+	}
+	if _, err := ScatterBits(bits, perm[:len(bits)-1]); err == nil {
+		t.Errorf("%d bits scattered into %d carriers", len(bits), len(bits)-1)
+	}
+}
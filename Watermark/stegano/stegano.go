@@ -5,6 +5,7 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 
 	"stegano/flagfunc"
 	"stegano/helpers"
@@ -16,32 +17,38 @@ func main() {
 	// Flags
 	embedFlag := flag.Bool("e", false, "Embed message into cover.html and create watermark.html")
 	extractFlag := flag.Bool("d", false, "Extract message from watermark.html")
+	analyzeFlag := flag.Bool("a", false, "Report how many bits methods 1-4 can hide in the cover and suggest a method")
 	oneFlag := flag.Bool("1", false, "Method 1: bit as additional space at line end")
 	twoFlag := flag.Bool("2", false, "Method 2: bit as single or double space")
 	threeFlag := flag.Bool("3", false, "Method 3: bit as HTML attribute typo")
 	fourFlag := flag.Bool("4", false, "Method 4: bit as redundant markup (e.g., FONT tags)")
+	msgFlag := flag.String("msg", "bla", "message written (as hex) to mess.txt and embedded by -e")
+	coverFlag := flag.String("cover", flagfunc.CoverFile, "cover HTML file read by -e and -a")
+	passFlag := flag.String("pass", "", "passphrase: AES-GCM encryption and key-chosen carriers (default $"+flagfunc.PassphraseEnv+")")
 
 	flag.Parse()
 
 	// Validate operation selection
-	operationFlags := []*bool{embedFlag, extractFlag}
+	operationFlags := []*bool{embedFlag, extractFlag, analyzeFlag}
 	if helpers.CountSelectedFlags(operationFlags) != 1 {
-		log.Fatalf("Error: You must choose exactly one operation: -e or -d")
+		log.Fatalf("Error: You must choose exactly one operation: -e, -d or -a")
 	}
 
 	// Determine operation
 	var operation string
 	if *embedFlag {
 		operation = "e"
-	} else {
+	} else if *extractFlag {
 		operation = "d"
+	} else {
+		operation = "a"
 	}
 
-	// Validate method selection (required in both cases now)
+	// Validate method selection (required for -e and -d, -a reports all methods)
 	methodFlags := []*bool{oneFlag, twoFlag, threeFlag, fourFlag}
 	selectedMethods := helpers.CountSelectedFlags(methodFlags)
 
-	if selectedMethods != 1 {
+	if operation != "a" && selectedMethods != 1 {
 		log.Fatalf("Error: You must specify exactly one method (-1, -2, -3, or -4)")
 	}
 
@@ -57,8 +64,14 @@ func main() {
 	case *fourFlag:
 		method = 4
 	}
+	flagfunc.CoverPath = *coverFlag
+	flagfunc.Passphrase = *passFlag
+	if flagfunc.Passphrase == "" {
+		flagfunc.Passphrase = os.Getenv(flagfunc.PassphraseEnv)
+	}
+
 	// Create mess.txt with hex input
-	var msg string = "bla"
+	var msg string = *msgFlag
 	err := helpers.SaveHexToFile(msg, "files/mess.txt")
 	if err != nil {
 		log.Println("Error:", err)